@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// placeholderPattern matches alias placeholders like {1}.
+var placeholderPattern = regexp.MustCompile(`\{(\d+)\}`)
+
+// aliasesPath returns the file where user aliases are stored.
+func (c *CLI) aliasesPath() (string, error) {
+	dir, err := c.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aliases.json"), nil
+}
+
+// LoadAliases returns the user's aliases, mapping each alias name to its
+// expansion. See CLI.UserAliases.
+func (c *CLI) LoadAliases() (map[string]string, error) {
+	aliases := map[string]string{}
+
+	path, err := c.aliasesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, c.Strings.errorf("failed to read aliases from %s: %s", path, err)
+	}
+	return aliases, nil
+}
+
+// saveAliases writes the user's aliases to disk.
+func (c *CLI) saveAliases(aliases map[string]string) error {
+	path, err := c.aliasesPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// SetAlias saves an alias for the user. expansion is a command line that may
+// include placeholders like {1}, which are replaced by the arguments passed to
+// the alias.
+func (c *CLI) SetAlias(name, expansion string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \n\t") {
+		return c.Strings.errorf("invalid alias name %q", name)
+	}
+	if _, ok := c.lookup(name); ok || c.builtin(name) {
+		return c.Strings.errorf("alias %q would hide the %s command of the same name", name, name)
+	}
+
+	if _, err := c.parseAlias(name, expansion); err != nil {
+		return err
+	}
+
+	aliases, err := c.LoadAliases()
+	if err != nil {
+		return err
+	}
+	aliases[name] = expansion
+	return c.saveAliases(aliases)
+}
+
+// RemoveAlias deletes one of the user's aliases.
+func (c *CLI) RemoveAlias(name string) error {
+	aliases, err := c.LoadAliases()
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[name]; !ok {
+		return c.Strings.errorf("alias %q does not exist", name)
+	}
+	delete(aliases, name)
+	return c.saveAliases(aliases)
+}
+
+// parseAlias splits an alias expansion into words, checking that it is not
+// empty and that its placeholders are valid.
+func (c *CLI) parseAlias(name, expansion string) ([]string, error) {
+	words, err := splitWords(expansion, c.Strings)
+	if err != nil {
+		return nil, c.Strings.errorf("invalid alias %q: %s", name, err)
+	}
+	if len(words) == 0 {
+		return nil, c.Strings.errorf("alias %q must not be empty", name)
+	}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(expansion, -1) {
+		if n, _ := strconv.Atoi(match[1]); n < 1 {
+			return nil, c.Strings.errorf("invalid placeholder %s in alias %q, placeholders start at {1}", match[0], name)
+		}
+	}
+	return words, nil
+}
+
+// expandAlias replaces placeholders in an alias expansion with args, and
+// appends any arguments that weren't used by a placeholder. The expansion is
+// checked again, because aliases.json may have been edited by hand.
+func (c *CLI) expandAlias(name, expansion string, args []string) ([]string, error) {
+	words, err := c.parseAlias(name, expansion)
+	if err != nil {
+		return nil, err
+	}
+
+	required := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatch(expansion, -1) {
+		if n, _ := strconv.Atoi(match[1]); n > required {
+			required = n
+		}
+	}
+	if len(args) < required {
+		return nil, c.Strings.errorf("alias '%s' requires %d argument(s), found %d: %s", name, required, len(args), expansion)
+	}
+
+	for i, word := range words {
+		words[i] = placeholderPattern.ReplaceAllStringFunc(word, func(placeholder string) string {
+			n, _ := strconv.Atoi(placeholder[1 : len(placeholder)-1])
+			return args[n-1]
+		})
+	}
+	return append(words, args[required:]...), nil
+}
+
+// aliasCommand implements the built-in alias command.
+func (c *CLI) aliasCommand(args []string) error {
+	usage := c.Strings.errorf("usage: %s alias [list | set <name> <expansion> | remove <name>]", c.Name)
+
+	if len(args) == 0 || args[0] == "list" {
+		if len(args) > 1 {
+			return usage
+		}
+		aliases, err := c.LoadAliases()
+		if err != nil {
+			return err
+		}
+		names := []string{}
+		width := 0
+		for name := range aliases {
+			names = append(names, name)
+			if len(name) > width {
+				width = len(name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			c.Printer.Outf("%s   %s\n", PadRight(name, width), aliases[name])
+		}
+		return nil
+	}
+
+	switch {
+	case args[0] == "set" && len(args) == 3:
+		return c.SetAlias(args[1], args[2])
+	case args[0] == "remove" && len(args) == 2:
+		return c.RemoveAlias(args[1])
+	}
+	return usage
+}
+
+// splitWords splits a command line into words like a POSIX shell, honoring
+// single quotes, double quotes, and backslash escapes. Errors are translated
+// with messages.
+func splitWords(line string, messages Strings) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\' && quote != '\'':
+			if i+1 == len(runes) {
+				return nil, messages.errorf("trailing backslash")
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, messages.errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,116 @@
+package cli_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestUserAliases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-aliases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("SHIP_CONFIG_DIR", dir)
+	defer os.Unsetenv("SHIP_CONFIG_DIR")
+
+	var received []string
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:        "ship",
+		UserAliases: true,
+		Printer:     &cli.Printer{Stdout: stdout},
+		Commands: map[string]*cli.Command{
+			"releases": {
+				Run: func(args []string) error {
+					received = args
+					return nil
+				},
+			},
+		},
+	}
+
+	run := func(args ...string) error {
+		return app.RunArgs(args)
+	}
+
+	if err := run("alias", "set", "rl", "releases list --env={1} --name '{2} two'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("rl", "staging", "one", "--verbose"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"list", "--env=staging", "--name", "one two", "--verbose"}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("Expected %q, found %q", expected, received)
+	}
+
+	err = run("rl", "staging")
+	expectedError := "alias 'rl' requires 2 argument(s), found 1: releases list --env={1} --name '{2} two'"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Expected %q, found %v", expectedError, err)
+	}
+
+	if err := run("alias", "list"); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "rl   releases list --env={1} --name '{2} two'\n" {
+		t.Errorf("Unexpected alias list %q", stdout.String())
+	}
+
+	type TestCase struct {
+		Name      string
+		Expansion string
+		Error     string
+	}
+
+	cases := []TestCase{
+		{Name: "releases", Expansion: "releases list", Error: `alias "releases" would hide the releases command of the same name`},
+		{Name: "help", Expansion: "releases list", Error: `alias "help" would hide the help command of the same name`},
+		{Name: "bad", Expansion: "releases 'list", Error: `invalid alias "bad": unterminated ' quote`},
+		{Name: "zero", Expansion: "releases {0}", Error: `invalid placeholder {0} in alias "zero", placeholders start at {1}`},
+		{Name: "two words", Expansion: "releases", Error: `invalid alias name "two words"`},
+	}
+	for _, testCase := range cases {
+		err := app.SetAlias(testCase.Name, testCase.Expansion)
+		if err == nil || err.Error() != testCase.Error {
+			t.Errorf("Expected %q, found %v", testCase.Error, err)
+		}
+	}
+
+	// Aliases can't refer to other aliases
+	if err := app.SetAlias("r", "rl"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("r", "a", "b"); err == nil || err.Error() != "alias 'r' refers to another alias, 'rl'" {
+		t.Errorf("Expected alias loop error, found %v", err)
+	}
+
+	if err := run("alias", "remove", "rl"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("rl", "staging", "one"); err == nil {
+		t.Error("Expected removed alias to fail")
+	}
+
+	// Aliases edited by hand are checked when they are used
+	edited := `{"empty": "  ", "zero": "releases {0}"}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "aliases.json"), []byte(edited), 0600); err != nil {
+		t.Fatal(err)
+	}
+	expectedErrors := map[string]string{
+		"empty": `alias "empty" must not be empty`,
+		"zero":  `invalid placeholder {0} in alias "zero", placeholders start at {1}`,
+	}
+	for name, expected := range expectedErrors {
+		if err := run(name, "staging"); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q, found %v", expected, err)
+		}
+	}
+}
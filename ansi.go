@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// ansiStripper is an io.Writer that removes ANSI escape sequences (colors,
+// cursor movement, and so on) from text before passing it to the underlying
+// writer. It tracks state between calls to Write, so sequences split across
+// multiple writes are still removed. It is safe for concurrent use.
+type ansiStripper struct {
+	w     io.Writer
+	mu    sync.Mutex
+	state int
+}
+
+// ansiStripper states
+const (
+	ansiText   = iota // regular text
+	ansiEscape        // saw ESC
+	ansiCSI           // inside a control sequence, ESC [
+	ansiOSC           // inside an operating system command, ESC ]
+	ansiOSCEsc        // saw ESC inside an operating system command
+)
+
+func (s *ansiStripper) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	output := make([]byte, 0, len(p))
+
+	for _, b := range p {
+		switch s.state {
+		case ansiText:
+			if b == 0x1b {
+				s.state = ansiEscape
+			} else {
+				output = append(output, b)
+			}
+		case ansiEscape:
+			switch b {
+			case '[':
+				s.state = ansiCSI
+			case ']':
+				s.state = ansiOSC
+			default:
+				// Two-byte sequence, such as ESC c
+				s.state = ansiText
+			}
+		case ansiCSI:
+			// Control sequences end with a byte in the range @ to ~
+			if b >= 0x40 && b <= 0x7e {
+				s.state = ansiText
+			}
+		case ansiOSC:
+			// Operating system commands end with BEL or ESC \
+			if b == 0x07 {
+				s.state = ansiText
+			} else if b == 0x1b {
+				s.state = ansiOSCEsc
+			}
+		case ansiOSCEsc:
+			if b == '\\' {
+				s.state = ansiText
+			} else {
+				s.state = ansiOSC
+			}
+		}
+	}
+
+	if _, err := s.w.Write(output); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// StripANSI removes ANSI escape sequences from text.
+func StripANSI(text string) string {
+	var output strings.Builder
+	_, _ = (&ansiStripper{w: &output}).Write([]byte(text))
+	return output.String()
+}
+
+// StripANSIWriter returns a writer that removes ANSI escape sequences from
+// everything written to w. Commands that write directly to a file or another
+// writer can use it to produce plain output. Output written with a Printer is
+// already stripped when necessary.
+func StripANSIWriter(w io.Writer) io.Writer {
+	return &ansiStripper{w: w}
+}
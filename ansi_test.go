@@ -0,0 +1,47 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestStripANSI(t *testing.T) {
+	type TestCase struct {
+		Input    string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{Input: "plain text", Expected: "plain text"},
+		{Input: "\x1b[31mred\x1b[0m and \x1b[1;32mbold green\x1b[0m", Expected: "red and bold green"},
+		{Input: "\x1b[2K\rprogress 50%", Expected: "\rprogress 50%"},
+		{Input: "\x1b]8;;https://example.com\x07link\x1b]8;;\x1b\\", Expected: "link"},
+		{Input: "\x1bcreset", Expected: "reset"},
+		{Input: "crème brûlée ✓", Expected: "crème brûlée ✓"},
+	}
+
+	for _, testCase := range cases {
+		actual := cli.StripANSI(testCase.Input)
+		if actual != testCase.Expected {
+			t.Errorf("Expected %q, found %q with input %q", testCase.Expected, actual, testCase.Input)
+		}
+	}
+}
+
+func TestStripANSIWriter(t *testing.T) {
+	output := &bytes.Buffer{}
+	w := cli.StripANSIWriter(output)
+
+	// Escape sequences split across writes should still be removed
+	for _, chunk := range []string{"\x1b[1;3", "1mfailed\x1b", "[0m\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if output.String() != "failed\n" {
+		t.Errorf("Expected %q, found %q", "failed\n", output.String())
+	}
+}
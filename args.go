@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// StdioName is the conventional filename argument used to refer to stdin when
+// reading, or stdout when writing.
+const StdioName = "-"
+
+// ExpandResponseFiles replaces each argument of the form @path with the
+// contents of the file at path, one argument per line. This allows users to
+// pass argument lists that exceed the operating system's command-line length
+// limit. Response files have the following format:
+//
+//   - Each line is a single argument. Spaces are preserved except at the
+//     beginning and end of the line, and no quoting is necessary
+//   - Blank lines are skipped
+//   - Lines starting with # are comments and are skipped
+//
+// Arguments inside a response file are not expanded again. To pass a literal
+// argument that begins with @, escape it as @@.
+func ExpandResponseFiles(args []string) ([]string, error) {
+	return expandResponseFiles(args, nil)
+}
+
+// expandResponseFiles implements ExpandResponseFiles, translating its errors
+// with messages.
+func expandResponseFiles(args []string, messages Strings) ([]string, error) {
+	expanded := []string{}
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "@@") {
+			expanded = append(expanded, arg[1:])
+			continue
+		}
+		if len(arg) < 2 || arg[0] != '@' {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		data, err := ioutil.ReadFile(arg[1:])
+		if err != nil {
+			return nil, messages.errorf("failed to read response file: %s", err)
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			expanded = append(expanded, line)
+		}
+	}
+
+	return expanded, nil
+}
+
+// ExpandGlobs replaces each argument that contains a wildcard pattern, such as
+// *.txt or data/202?-*.csv, with the names of the files it matches, in sorted
+// order. Patterns use the syntax of filepath.Match. Arguments without
+// wildcards, and patterns that match nothing, are left unchanged, as in POSIX
+// shells.
+//
+// The framework calls ExpandGlobs on Windows for arguments whose ArgSpec sets
+// Glob. Note that Windows does not tell the program whether an argument was
+// quoted, so a quoted pattern is expanded too.
+func ExpandGlobs(args []string) []string {
+	expanded := []string{}
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			expanded = append(expanded, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil || len(matches) == 0 {
+			expanded = append(expanded, arg)
+			continue
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded
+}
+
+// expandArgGlobs expands wildcard patterns in the arguments whose ArgSpec sets
+// Glob, on platforms where the shell doesn't expand them.
+func expandArgGlobs(command *Command, args []string) []string {
+	if runtime.GOOS != "windows" || len(command.Args) == 0 {
+		return args
+	}
+	expanded := []string{}
+	for idx, arg := range args {
+		spec := command.Args[len(command.Args)-1]
+		if idx < len(command.Args) {
+			spec = command.Args[idx]
+		}
+		if spec.Glob {
+			expanded = append(expanded, ExpandGlobs([]string{arg})...)
+		} else {
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded
+}
+
+// OpenInput opens the named file for reading. If name is "-", stdin is returned
+// instead, following the common unix convention. Closing the returned stdin
+// reader has no effect, so it is always safe to defer Close.
+func OpenInput(name string) (io.ReadCloser, error) {
+	if name == StdioName {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// CreateOutput creates or truncates the named file for writing. If name is "-",
+// stdout is returned instead, which is useful for output flags such as
+// --output. Closing the returned stdout writer has no effect, so it is always
+// safe to defer Close.
+func CreateOutput(name string) (io.WriteCloser, error) {
+	if name == StdioName {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(name)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,148 @@
+package cli_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-test-response")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	responseFile := filepath.Join(dir, "args.txt")
+	contents := "# files to process\nfile1.txt\r\n\n  file with spaces.txt  \n@not-expanded\n"
+	if err := ioutil.WriteFile(responseFile, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := []string{"cat", "-n", "@" + responseFile, "@@literal", "@", "last"}
+	expected := []string{"cat", "-n", "file1.txt", "file with spaces.txt", "@not-expanded", "@literal", "@", "last"}
+
+	actual, err := cli.ExpandResponseFiles(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, found %#v", expected, actual)
+	}
+
+	if _, err := cli.ExpandResponseFiles([]string{"@" + filepath.Join(dir, "missing.txt")}); err == nil {
+		t.Error("Expected error for missing response file")
+	}
+}
+
+func TestOpenInput(t *testing.T) {
+	ogStdin := os.Stdin
+	defer func() { os.Stdin = ogStdin }()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdin = reader
+	if _, err := writer.WriteString("from stdin"); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	input, err := cli.OpenInput("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input.Close()
+
+	if string(data) != "from stdin" {
+		t.Errorf("Expected %q, found %q", "from stdin", string(data))
+	}
+
+	// Closing the stdin wrapper must not close stdin itself
+	if _, err := reader.Stat(); err != nil {
+		t.Errorf("Expected stdin to remain open: %s", err)
+	}
+	reader.Close()
+
+	if _, err := cli.OpenInput("does-not-exist.txt"); err == nil {
+		t.Error("Expected error opening missing file")
+	}
+}
+
+func TestCreateOutput(t *testing.T) {
+	cleanup, stdout := redirectIO()
+	defer cleanup()
+
+	output, err := cli.CreateOutput("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := output.Write([]byte("to stdout")); err != nil {
+		t.Fatal(err)
+	}
+	output.Close()
+
+	cleanup() // Cleanup to flush stdout/err to disk
+	data, err := ioutil.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "to stdout" {
+		t.Errorf("Expected %q, found %q", "to stdout", string(data))
+	}
+
+	dir, err := ioutil.TempDir("", "cli-test-output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "out.txt")
+	output, err = cli.CreateOutput(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := output.Write([]byte("to file")); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "to file" {
+		t.Errorf("Expected %q, found %q", "to file", string(data))
+	}
+}
+
+func TestExpandGlobs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-test-glob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"b.txt", "a.txt", "notes.md"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args := []string{"--force", filepath.Join(dir, "*.txt"), filepath.Join(dir, "*.csv"), "notes"}
+	expected := []string{"--force", filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), filepath.Join(dir, "*.csv"), "notes"}
+	if actual := cli.ExpandGlobs(args); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, found %q", expected, actual)
+	}
+}
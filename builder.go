@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Builder constructs a CLI using chained method calls, as an alternative to
+// writing a CLI struct literal:
+//
+//	app, err := cli.New("shipit").
+//		Version("1.0.0").
+//		Command("deploy").
+//			Summary("Deploy the application").
+//			Flag("env", "staging", "Environment to deploy to").
+//			Arg("app", true).
+//			Run(deploy).
+//		Build()
+//
+// Each call is validated as it is made. The first error encountered is
+// remembered and later calls are ignored, so the error is reported by Build.
+type Builder struct {
+	cli *CLI
+	err error
+}
+
+// New starts building a CLI with the specified program name.
+func New(name string) *Builder {
+	b := &Builder{cli: &CLI{Name: name, Commands: map[string]*Command{}}}
+	if strings.ContainsAny(name, " \n\t") {
+		b.err = fmt.Errorf("program name (%q) must not contain spaces", name)
+	}
+	return b
+}
+
+// Version sets the program version.
+func (b *Builder) Version(version string) *Builder {
+	b.cli.Version = version
+	return b
+}
+
+// Header sets the text shown above command help.
+func (b *Builder) Header(header string) *Builder {
+	b.cli.Header = header
+	return b
+}
+
+// Footer sets the text shown below command help.
+func (b *Builder) Footer(footer string) *Builder {
+	b.cli.Footer = footer
+	return b
+}
+
+// Command adds a new command and returns a CommandBuilder to configure it.
+func (b *Builder) Command(name string) *CommandBuilder {
+	cb := &CommandBuilder{parent: b, name: name, command: &Command{}}
+	switch {
+	case b.err != nil:
+	case name == "":
+		b.err = fmt.Errorf("command name must not be empty")
+	case strings.ContainsAny(name, " \n\t"):
+		b.err = fmt.Errorf("command names (%q) must not contain spaces", name)
+	case b.cli.Commands[name] != nil:
+		b.err = fmt.Errorf("command %q is already defined", name)
+	default:
+		b.cli.Commands[name] = cb.command
+	}
+	return cb
+}
+
+// Build returns the constructed CLI, or the first error encountered while
+// building it.
+func (b *Builder) Build() (*CLI, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.cli, nil
+}
+
+// CommandBuilder configures a single command. Call Run or End to return to the
+// parent Builder.
+type CommandBuilder struct {
+	parent  *Builder
+	name    string
+	command *Command
+}
+
+// Summary sets the command summary shown in command help.
+func (cb *CommandBuilder) Summary(summary string) *CommandBuilder {
+	cb.command.Summary = summary
+	return cb
+}
+
+// Description sets the detailed description shown in the command's help.
+func (cb *CommandBuilder) Description(description string) *CommandBuilder {
+	cb.command.Description = description
+	return cb
+}
+
+// Help sets the verbose help text for the command.
+func (cb *CommandBuilder) Help(help string) *CommandBuilder {
+	cb.command.Help = help
+	return cb
+}
+
+// Hidden hides the command from command help.
+func (cb *CommandBuilder) Hidden() *CommandBuilder {
+	cb.command.Hidden = true
+	return cb
+}
+
+// Experimental marks the command as experimental.
+func (cb *CommandBuilder) Experimental() *CommandBuilder {
+	cb.command.Experimental = true
+	return cb
+}
+
+// Flag defines a flag on the command. The type of the flag is determined by
+// the default value, which must be a string, bool, int, int64, uint, uint64,
+// float64, or time.Duration.
+func (cb *CommandBuilder) Flag(name string, value interface{}, usage string) *CommandBuilder {
+	if cb.parent.err != nil {
+		return cb
+	}
+	if cb.command.Flags == nil {
+		cb.command.Flags = flag.NewFlagSet(cb.name, flag.ContinueOnError)
+	}
+
+	set := cb.command.Flags
+	if set.Lookup(name) != nil {
+		cb.parent.err = fmt.Errorf("command %q: flag --%s is already defined", cb.name, name)
+		return cb
+	}
+
+	switch def := value.(type) {
+	case string:
+		set.String(name, def, usage)
+	case bool:
+		set.Bool(name, def, usage)
+	case int:
+		set.Int(name, def, usage)
+	case int64:
+		set.Int64(name, def, usage)
+	case uint:
+		set.Uint(name, def, usage)
+	case uint64:
+		set.Uint64(name, def, usage)
+	case float64:
+		set.Float64(name, def, usage)
+	case time.Duration:
+		set.Duration(name, def, usage)
+	default:
+		cb.parent.err = fmt.Errorf("command %q: flag --%s has unsupported type %T", cb.name, name, value)
+	}
+	return cb
+}
+
+// RequiredFlag marks a previously defined flag as required.
+func (cb *CommandBuilder) RequiredFlag(name string) *CommandBuilder {
+	if cb.parent.err != nil {
+		return cb
+	}
+	if cb.command.Flags == nil || cb.command.Flags.Lookup(name) == nil {
+		cb.parent.err = fmt.Errorf("command %q: required flag --%s is not defined", cb.name, name)
+		return cb
+	}
+	cb.command.RequiredFlags = append(cb.command.RequiredFlags, name)
+	return cb
+}
+
+// Arg appends a positional argument to the command.
+func (cb *CommandBuilder) Arg(name string, required bool) *CommandBuilder {
+	if cb.parent.err != nil {
+		return cb
+	}
+	for _, arg := range cb.command.Args {
+		if arg.Name == name {
+			cb.parent.err = fmt.Errorf("command %q: argument <%s> is already defined", cb.name, name)
+			return cb
+		}
+	}
+	cb.command.Args = append(cb.command.Args, ArgSpec{Name: name, Required: required})
+	return cb
+}
+
+// Run sets the function that implements the command and returns the parent
+// Builder.
+func (cb *CommandBuilder) Run(fn func(ctx *Context) error) *Builder {
+	if fn == nil && cb.parent.err == nil {
+		cb.parent.err = fmt.Errorf("command %q: run function must not be nil", cb.name)
+	}
+	cb.command.RunContext = fn
+	return cb.parent
+}
+
+// End returns the parent Builder without setting a run function, for commands
+// such as help topics that have no implementation.
+func (cb *CommandBuilder) End() *Builder {
+	return cb.parent
+}
@@ -0,0 +1,93 @@
+package cli_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestBuilder(t *testing.T) {
+	var env string
+	var timeout time.Duration
+
+	app, err := cli.New("shipit").
+		Version("1.0.0").
+		Command("deploy").
+		Summary("Deploy the application").
+		Flag("env", "staging", "Environment to deploy to").
+		Flag("timeout", time.Minute, "Time to wait").
+		Arg("app", true).
+		Run(func(ctx *cli.Context) error {
+			env = ctx.Flags.Lookup("env").Value.String()
+			timeout = ctx.Flags.Lookup("timeout").Value.(flag.Getter).Get().(time.Duration)
+			return nil
+		}).
+		Command("topic").
+		Help("A help topic").
+		End().
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Name != "shipit" || app.Version != "1.0.0" {
+		t.Errorf("Unexpected CLI: %+v", app)
+	}
+	if app.Commands["deploy"].Summary != "Deploy the application" {
+		t.Errorf("Expected %q, found %q", "Deploy the application", app.Commands["deploy"].Summary)
+	}
+	if usage := cli.CommandUsage(app, "deploy"); usage != "shipit deploy [flags] <app>" {
+		t.Errorf("Expected %q, found %q", "shipit deploy [flags] <app>", usage)
+	}
+
+	if err := app.RunArgs([]string{"deploy", "--env", "prod", "web"}); err != nil {
+		t.Fatal(err)
+	}
+	if env != "prod" || timeout != time.Minute {
+		t.Errorf("Unexpected flag values %q %s", env, timeout)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	type TestCase struct {
+		Builder *cli.Builder
+		Error   string
+	}
+
+	cases := []TestCase{
+		{
+			Builder: cli.New("my prog"),
+			Error:   `program name ("my prog") must not contain spaces`,
+		},
+		{
+			Builder: cli.New("prog").Command("a").End().Command("a").End(),
+			Error:   `command "a" is already defined`,
+		},
+		{
+			Builder: cli.New("prog").Command("a").Flag("x", "", "").Flag("x", 1, "").End(),
+			Error:   `command "a": flag --x is already defined`,
+		},
+		{
+			Builder: cli.New("prog").Command("a").Flag("x", []int{}, "").End(),
+			Error:   `command "a": flag --x has unsupported type []int`,
+		},
+		{
+			Builder: cli.New("prog").Command("a").RequiredFlag("x").End(),
+			Error:   `command "a": required flag --x is not defined`,
+		},
+		{
+			// Only the first error is reported
+			Builder: cli.New("prog").Command("").End().Command("a b").End(),
+			Error:   "command name must not be empty",
+		},
+	}
+
+	for _, testCase := range cases {
+		_, err := testCase.Builder.Build()
+		if err == nil || err.Error() != testCase.Error {
+			t.Errorf("Expected %q, found %v", testCase.Error, err)
+		}
+	}
+}
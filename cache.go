@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// resultCacheDir returns the directory used to cache the output of commands
+// with a CacheTTL.
+func (c *CLI) resultCacheDir() (string, error) {
+	dir, err := c.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "results"), nil
+}
+
+// resultCacheKey returns the name of the cache file for the command at path
+// run with args.
+func resultCacheKey(path string, args []string) string {
+	// Use a hash so arbitrary arguments make a safe filename
+	key := sha256.Sum256([]byte(strings.Join(append([]string{path}, args...), "\x00")))
+	return hex.EncodeToString(key[:])
+}
+
+// cachedResult prints the cached output for key and returns true if it was
+// cached less than ttl ago. Caching is best-effort; if the cache can't be
+// read, it returns false and the command is run.
+func (c *CLI) cachedResult(key string, ttl time.Duration) bool {
+	dir, err := c.resultCacheDir()
+	if err != nil {
+		return false
+	}
+	path := filepath.Join(dir, key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) >= ttl {
+		return false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	c.Printer.Out(string(data))
+	return true
+}
+
+// saveResult caches output under key. Errors are ignored because the cache is
+// only an optimization.
+func (c *CLI) saveResult(key string, output []byte) {
+	dir, err := c.resultCacheDir()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0700); err == nil {
+		ioutil.WriteFile(filepath.Join(dir, key), output, 0600)
+	}
+}
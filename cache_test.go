@@ -0,0 +1,82 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestCacheTTL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_CACHE_DIR", dir)
+	defer os.Unsetenv("SHIP_CACHE_DIR")
+
+	runs := 0
+	fail := false
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout},
+		Commands: map[string]*cli.Command{
+			"status": {
+				CacheTTL: time.Hour,
+				RunContext: func(ctx *cli.Context) error {
+					runs++
+					if fail {
+						return errors.New("unavailable")
+					}
+					fmt.Fprintf(ctx.Stdout, "run %d: %v\n", runs, ctx.Args)
+					return nil
+				},
+			},
+		},
+	}
+
+	type TestCase struct {
+		Args     []string
+		Expected string
+		Runs     int
+	}
+
+	cases := []TestCase{
+		{[]string{"status", "web"}, "run 1: [web]\n", 1},
+		{[]string{"status", "web"}, "run 1: [web]\n", 1},
+		{[]string{"status", "db"}, "run 2: [db]\n", 2},
+		{[]string{"status", "web", "--no-cache"}, "run 3: [web]\n", 3},
+		{[]string{"status", "web"}, "run 1: [web]\n", 3},
+		{[]string{"status", "web", "--refresh"}, "run 4: [web]\n", 4},
+		{[]string{"status", "web"}, "run 4: [web]\n", 4},
+	}
+
+	for _, c := range cases {
+		stdout.Reset()
+		if err := app.RunArgs(c.Args); err != nil {
+			t.Fatal(err)
+		}
+		if stdout.String() != c.Expected || runs != c.Runs {
+			t.Errorf("Expected %q after %d runs, found %q after %d runs with %q", c.Expected, c.Runs, stdout.String(), runs, c.Args)
+		}
+	}
+
+	// Failures are not cached
+	fail = true
+	if err := app.RunArgs([]string{"status", "api"}); err == nil {
+		t.Error("Expected status to fail")
+	}
+	fail = false
+	stdout.Reset()
+	if err := app.RunArgs([]string{"status", "api"}); err != nil || stdout.String() != "run 6: [api]\n" {
+		t.Errorf("Expected status to run again after failing, found %q, %v", stdout.String(), err)
+	}
+}
@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	markdownLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownBold = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownCode = regexp.MustCompile("`([^`]+)`")
+)
+
+// changelogSection is a single release in a changelog.
+type changelogSection struct {
+	// version is empty for sections that don't name a version, such as
+	// "Unreleased".
+	version string
+	text    string
+}
+
+// RenderChangelog formats c.Changelog for display in the terminal. The
+// changelog is Markdown, with a level two heading for each release that begins
+// with its version, as in "## 1.2.0" or "## [1.2.0] - 2024-05-01".
+//
+// If since is set, only releases newer than since are included, along with any
+// sections such as "Unreleased" that don't name a version.
+func RenderChangelog(c *CLI, since string) (string, error) {
+	text := c.Changelog
+	if since != "" {
+		if _, ok := parseVersion(since); !ok {
+			return "", c.Strings.errorf("invalid version '%s'", since)
+		}
+		text = ""
+		for _, section := range parseChangelog(c.Changelog)[1:] {
+			if section.version == "" || compareVersions(section.version, since) > 0 {
+				text += section.text
+			}
+		}
+		if strings.TrimSpace(text) == "" {
+			return fmt.Sprintf("No changes since %s\n", since), nil
+		}
+	}
+
+	width := c.helpWidth()
+	if width < minWrapWidth {
+		width = 0
+	}
+	bullet := c.Printer.Symbols().Info
+	color := c.Printer.styled(c.Printer.stdout())
+	return renderMarkdown(text, width, bullet, color), nil
+}
+
+// changelogCommand implements the built-in changelog command.
+func (c *CLI) changelogCommand(args []string) error {
+	set := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	since := set.String("since", "", "")
+
+	parser := &flagParser{set: set, messages: c.Strings}
+	positionals, err := parser.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positionals) > 0 {
+		return c.Strings.errorf("usage: %s changelog [--since <version>]", c.Name)
+	}
+
+	output, err := RenderChangelog(c, *since)
+	if err != nil {
+		return err
+	}
+	c.Printer.Out(output)
+	return nil
+}
+
+// changelogVersions returns the versions named in c.Changelog, newest first.
+func (c *CLI) changelogVersions() []string {
+	var versions []string
+	for _, section := range parseChangelog(c.Changelog)[1:] {
+		if section.version != "" {
+			versions = append(versions, section.version)
+		}
+	}
+	return versions
+}
+
+// parseChangelog splits text into sections at each level two heading. The
+// first section holds any text before the first heading, such as the title.
+func parseChangelog(text string) []changelogSection {
+	sections := []changelogSection{{}}
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			sections = append(sections, changelogSection{version: headingVersion(line[3:])})
+		}
+		sections[len(sections)-1].text += line
+	}
+	return sections
+}
+
+// headingVersion returns the version at the start of a release heading, or an
+// empty string if the heading does not begin with a version.
+func headingVersion(heading string) string {
+	fields := strings.Fields(heading)
+	if len(fields) == 0 {
+		return ""
+	}
+	version := strings.Trim(fields[0], "[]():")
+	if _, ok := parseVersion(version); !ok {
+		return ""
+	}
+	return version
+}
+
+// parseVersion splits a version such as "v1.2.3-beta.1" into its numeric
+// components and pre-release suffix.
+func parseVersion(version string) (parts []int, ok bool) {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// compareVersions returns -1, 0, or 1 if a is older than, the same as, or
+// newer than b. Missing components are treated as zero, and a pre-release such
+// as 1.2.0-rc.1 is older than the release itself. Pre-releases are compared by
+// each dot-separated identifier, numerically if both are numbers, so
+// beta.10 is newer than beta.2.
+func compareVersions(a, b string) int {
+	aParts, _ := parseVersion(a)
+	bParts, _ := parseVersion(b)
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	aPre, bPre := preRelease(a), preRelease(b)
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aIDs, bIDs := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if aIDs[i] == bIDs[i] {
+			continue
+		}
+		x, xErr := strconv.Atoi(aIDs[i])
+		y, yErr := strconv.Atoi(bIDs[i])
+		switch {
+		case xErr == nil && yErr == nil && x < y:
+			return -1
+		case xErr == nil && yErr == nil:
+			return 1
+		case xErr == nil:
+			// Numeric identifiers are older than alphanumeric ones
+			return -1
+		case yErr == nil:
+			return 1
+		case aIDs[i] < bIDs[i]:
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case len(aIDs) < len(bIDs):
+		return -1
+	case len(aIDs) > len(bIDs):
+		return 1
+	}
+	return 0
+}
+
+// preRelease returns the pre-release suffix of version, without build
+// metadata.
+func preRelease(version string) string {
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.Index(version, "-"); i >= 0 {
+		return version[i+1:]
+	}
+	return ""
+}
+
+// renderMarkdown formats a subset of Markdown for the terminal: headings,
+// bulleted lists, fenced code blocks, emphasis, inline code, and links.
+// Paragraphs and list items are wrapped to width. Styles are only applied if
+// color is set.
+func renderMarkdown(text string, width int, bullet string, color bool) string {
+	style := func(s, text string) string {
+		if color {
+			return colorize(s, text)
+		}
+		return text
+	}
+	inline := func(text string) string {
+		text = markdownLink.ReplaceAllString(text, "$1 ($2)")
+		text = markdownBold.ReplaceAllStringFunc(text, func(match string) string {
+			return style(ansiBold, match[2:len(match)-2])
+		})
+		return markdownCode.ReplaceAllStringFunc(text, func(match string) string {
+			return style(ansiCyan, match[1:len(match)-1])
+		})
+	}
+
+	var output []string
+	var paragraph []string
+	prefix, hanging := "", ""
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		wrapWidth := 0
+		if width > 0 {
+			wrapWidth = width - textWidth(hanging)
+		}
+		for i, line := range wrapWords(inline(strings.Join(paragraph, " ")), wrapWidth) {
+			if i == 0 {
+				output = append(output, prefix+line)
+			} else {
+				output = append(output, hanging+line)
+			}
+		}
+		paragraph = nil
+	}
+
+	fenced := false
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			fenced = !fenced
+		case fenced:
+			output = append(output, "    "+line)
+		case trimmed == "":
+			flush()
+			output = append(output, "")
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			output = append(output, style(ansiBold, inline(strings.TrimSpace(strings.TrimLeft(trimmed, "#")))))
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ "):
+			flush()
+			depth := (len(line) - len(strings.TrimLeft(line, " \t"))) / 2
+			prefix = strings.Repeat("  ", depth+1) + bullet + " "
+			hanging = strings.Repeat(" ", textWidth(prefix))
+			paragraph = []string{trimmed[2:]}
+		default:
+			if len(paragraph) == 0 {
+				prefix, hanging = "", ""
+			}
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	// Collapse runs of blank lines left by the markup we removed
+	var collapsed []string
+	for i, line := range output {
+		if line == "" && i > 0 && output[i-1] == "" {
+			continue
+		}
+		collapsed = append(collapsed, line)
+	}
+	return strings.Join(collapsed, "\n") + "\n"
+}
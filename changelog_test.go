@@ -0,0 +1,158 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+const testChangelog = `# Changelog
+
+All notable changes to this project are documented here.
+
+## Unreleased
+
+- Faster startup
+
+## [1.10.0] - 2024-06-01
+
+### Added
+
+- The **sync** command copies files between hosts. It retries transfers that
+  fail and resumes where it left off.
+- See [the docs](https://example.com/sync).
+
+## [1.9.0] - 2024-03-12
+
+- Use ` + "`--verbose`" + ` for more output
+
+## 1.2.0-rc.1
+
+- Release candidate
+`
+
+func TestRenderChangelog(t *testing.T) {
+	app := &cli.CLI{
+		Name:      "ferry",
+		Printer:   &cli.Printer{ASCII: true},
+		Layout:    cli.HelpLayout{MaxWidth: 50},
+		Changelog: testChangelog,
+	}
+
+	t.Run("everything", func(tt *testing.T) {
+		expectedOutput := `Changelog
+
+All notable changes to this project are documented
+here.
+
+Unreleased
+
+  * Faster startup
+
+[1.10.0] - 2024-06-01
+
+Added
+
+  * The sync command copies files between hosts.
+    It retries transfers that fail and resumes
+    where it left off.
+  * See the docs (https://example.com/sync).
+
+[1.9.0] - 2024-03-12
+
+  * Use --verbose for more output
+
+1.2.0-rc.1
+
+  * Release candidate
+`
+		output, err := cli.RenderChangelog(app, "")
+		if err != nil {
+			tt.Fatal(err)
+		}
+		if output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+
+	t.Run("since", func(tt *testing.T) {
+		type TestCase struct {
+			Since    string
+			Expected []string
+		}
+
+		cases := []TestCase{
+			{"1.9.0", []string{"Unreleased", "[1.10.0]", "Added"}},
+			{"v1.2", []string{"Unreleased", "[1.10.0]", "Added", "[1.9.0]"}},
+			{"1.2.0-beta", []string{"Unreleased", "[1.10.0]", "Added", "[1.9.0]", "1.2.0-rc.1"}},
+			{"1.10.0", []string{"Unreleased"}},
+		}
+
+		for _, c := range cases {
+			output, err := cli.RenderChangelog(app, c.Since)
+			if err != nil {
+				tt.Fatal(err)
+			}
+			var headings []string
+			for _, line := range strings.Split(output, "\n") {
+				if line != "" && !strings.HasPrefix(line, " ") {
+					headings = append(headings, strings.Fields(line)[0])
+				}
+			}
+			if strings.Join(headings, ",") != strings.Join(c.Expected, ",") {
+				tt.Errorf("Since %s: expected %q, found %q", c.Since, c.Expected, headings)
+			}
+		}
+	})
+
+	t.Run("nothing new", func(tt *testing.T) {
+		app := &cli.CLI{Name: "ferry", Changelog: "## 1.0.0\n\n- First release\n"}
+		output, err := cli.RenderChangelog(app, "1.0.0")
+		if err != nil {
+			tt.Fatal(err)
+		}
+		if expected := "No changes since 1.0.0\n"; output != expected {
+			tt.Errorf("Expected %q, found %q", expected, output)
+		}
+	})
+
+	t.Run("invalid version", func(tt *testing.T) {
+		_, err := cli.RenderChangelog(app, "latest")
+		expected := "invalid version 'latest'"
+		if err == nil || err.Error() != expected {
+			tt.Errorf("Expected %q, found %v", expected, err)
+		}
+	})
+}
+
+func TestChangelogCommand(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:      "ferry",
+		Printer:   &cli.Printer{Stdout: stdout, ASCII: true},
+		Changelog: testChangelog,
+	}
+
+	if err := app.RunArgs([]string{"changelog", "--since", "1.9"}); err != nil {
+		t.Fatal(err)
+	}
+	expectedOutput := "Unreleased\n\n  * Faster startup\n"
+	if !strings.HasPrefix(stdout.String(), expectedOutput) || strings.Contains(stdout.String(), "1.9.0") {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, stdout.String())
+	}
+
+	if err := app.RunArgs([]string{"changelog", "1.9"}); err == nil {
+		t.Error("Expected an error for a positional argument")
+	}
+
+	result := cli.Complete(app, []string{"changelog", "--since", "1.1"})
+	if strings.Join(result.Candidates, ",") != "1.10.0" {
+		t.Errorf("Expected [1.10.0], found %q", result.Candidates)
+	}
+	result = cli.Complete(app, []string{"changelog", "--since=1.9"})
+	if strings.Join(result.Candidates, ",") != "--since=1.9.0" {
+		t.Errorf("Expected [--since=1.9.0], found %q", result.Candidates)
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Channels are the release channels a program can follow, from most to least
+// stable. See CLI.Channel.
+var Channels = []string{"stable", "beta", "nightly"}
+
+// ActiveChannel returns the release channel the user follows: the one passed
+// to --channel, or PROG_CHANNEL in the environment (where PROG is the
+// upper-cased program name), or the "channel" setting in the configuration
+// file, or c.Channel. It returns an empty string if the
+// program does not use channels.
+func (c *CLI) ActiveChannel() string {
+	if c.Channel == "" {
+		return ""
+	}
+	if c.channel != "" {
+		return c.channel
+	}
+	if channel := os.Getenv(c.envVar("CHANNEL")); channelRank(channel) >= 0 {
+		return channel
+	}
+	if channel := c.configChannel(); channelRank(channel) >= 0 {
+		return channel
+	}
+	return c.Channel
+}
+
+// configChannel returns the "channel" setting in the configuration file, or
+// an empty string if it can't be read. The file is read directly rather than
+// with LoadConfig, so showing the version never migrates or rewrites it.
+func (c *CLI) configChannel() string {
+	path, err := c.ConfigPath()
+	if err != nil {
+		return ""
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		Channel string `json:"channel"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ""
+	}
+	return config.Channel
+}
+
+// setChannel sets the channel for --channel.
+func (c *CLI) setChannel(value string) error {
+	if channelRank(value) < 0 {
+		return c.Strings.errorf("invalid value %q for flag --channel: expected %s", value, strings.Join(Channels, ", "))
+	}
+	c.channel = value
+	return nil
+}
+
+// VersionChannel returns the channel a version was released on, based on its
+// pre-release suffix: versions without one, such as 1.4.0, are stable;
+// nightly, dev, and snapshot builds, such as 1.5.0-nightly.20240501, are
+// nightly; and any other pre-release, such as 1.5.0-beta.2 or 1.5.0-rc.1, is
+// beta.
+func VersionChannel(version string) string {
+	pre := strings.ToLower(preRelease(version))
+	switch {
+	case pre == "":
+		return "stable"
+	case strings.HasPrefix(pre, "nightly"), strings.HasPrefix(pre, "dev"), strings.HasPrefix(pre, "snapshot"):
+		return "nightly"
+	}
+	return "beta"
+}
+
+// NewerRelease reports whether candidate is an update for the running
+// program: it is newer than c.Version and was released on the active channel
+// or a more stable one, so beta users receive both beta and stable releases.
+// If the program does not use channels, only stable releases are considered.
+// Update commands use this to choose which release to install.
+func (c *CLI) NewerRelease(candidate string) bool {
+	if _, ok := parseVersion(candidate); !ok {
+		return false
+	}
+	active := c.ActiveChannel()
+	if active == "" {
+		active = "stable"
+	}
+	if channelRank(VersionChannel(candidate)) > channelRank(active) {
+		return false
+	}
+	return c.Version == "" || compareVersions(candidate, c.Version) > 0
+}
+
+// channelRank returns the position of channel in Channels, or -1 if it is not
+// a channel.
+func channelRank(channel string) int {
+	for i, name := range Channels {
+		if name == channel {
+			return i
+		}
+	}
+	return -1
+}
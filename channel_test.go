@@ -0,0 +1,147 @@
+package cli_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestNewerRelease(t *testing.T) {
+	app := &cli.CLI{Name: "ship", Version: "1.4.0-beta.2", Channel: "stable"}
+
+	type TestCase struct {
+		Channel   string
+		Candidate string
+		Expected  bool
+	}
+
+	cases := []TestCase{
+		{"stable", "1.4.0", true},
+		{"stable", "1.4.0-beta.10", false},
+		{"beta", "1.4.0-beta.10", true},
+		{"beta", "1.4.0-beta.1", false},
+		{"beta", "1.4.0-nightly.20240501", false},
+		{"beta", "1.5.0", true},
+		{"nightly", "1.4.0-nightly.20240501", true},
+		{"nightly", "1.3.9", false},
+		{"nightly", "not a version", false},
+	}
+
+	for _, c := range cases {
+		os.Setenv("SHIP_CHANNEL", c.Channel)
+		if found := app.NewerRelease(c.Candidate); found != c.Expected {
+			t.Errorf("Expected NewerRelease(%q) on %s to be %v", c.Candidate, c.Channel, c.Expected)
+		}
+	}
+	os.Unsetenv("SHIP_CHANNEL")
+
+	for version, expected := range map[string]string{"2.0.0": "stable", "2.0.0-rc.1": "beta", "2.0.0-dev": "nightly"} {
+		if channel := cli.VersionChannel(version); channel != expected {
+			t.Errorf("Expected %s to be on %s, found %s", version, expected, channel)
+		}
+	}
+}
+
+func TestVersionFull(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Version: "1.4.0",
+		Channel: "stable",
+		Printer: &cli.Printer{Stdout: stdout},
+	}
+
+	if err := app.RunArgs([]string{"--channel", "beta", "--version", "--full"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "ship version 1.4.0\nChannel:  beta\nPlatform: " + runtime.GOOS + "/" + runtime.GOARCH + "\nGo:       " + runtime.Version() + "\n"
+	if stdout.String() != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.RunArgs([]string{"--version", "--json"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), `"channel":"stable"`) {
+		t.Errorf("Expected the channel in %q", stdout.String())
+	}
+
+	expected = "invalid value \"canary\" for flag --channel: expected stable, beta, nightly"
+	if err := app.RunArgs([]string{"--channel=canary", "--version"}); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+}
+
+func TestChannelConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "channel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("SHIP_CONFIG_DIR", dir)
+	defer os.Unsetenv("SHIP_CONFIG_DIR")
+
+	app := &cli.CLI{Name: "ship", Channel: "stable"}
+	if channel := app.ActiveChannel(); channel != "stable" {
+		t.Errorf("Expected %q without a config file, found %q", "stable", channel)
+	}
+
+	type TestCase struct {
+		Config   string
+		Env      string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{`{"channel": "beta"}`, "", "beta"},
+		{`{"channel": "canary"}`, "", "stable"},
+		{`{"channel": "beta"}`, "nightly", "nightly"},
+		{`not json`, "", "stable"},
+	}
+
+	path := filepath.Join(dir, "config.json")
+	for _, c := range cases {
+		if err := ioutil.WriteFile(path, []byte(c.Config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		os.Setenv("SHIP_CHANNEL", c.Env)
+		if channel := app.ActiveChannel(); channel != c.Expected {
+			t.Errorf("%s with SHIP_CHANNEL=%q: Expected %q, found %q", c.Config, c.Env, c.Expected, channel)
+		}
+	}
+	os.Unsetenv("SHIP_CHANNEL")
+
+	// Reading the channel never migrates the config file
+	old := `{"version": 1, "channel": "beta"}`
+	if err := ioutil.WriteFile(path, []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stderr := &bytes.Buffer{}
+	upgrading := &cli.CLI{Name: "ship", Channel: "stable", ConfigVersion: 2, Printer: &cli.Printer{Stderr: stderr}}
+	if channel := upgrading.ActiveChannel(); channel != "beta" {
+		t.Errorf("Expected %q, found %q", "beta", channel)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil || string(data) != old || stderr.Len() > 0 {
+		t.Errorf("Expected %s to be unchanged, found %q and output %q", path, data, stderr.String())
+	}
+
+	// --channel takes precedence over the config file
+	if err := ioutil.WriteFile(path, []byte(`{"channel": "beta"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stdout := &bytes.Buffer{}
+	app.Printer = &cli.Printer{Stdout: stdout}
+	if err := app.RunArgs([]string{"--channel", "nightly", "--version", "--full"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "Channel:  nightly\n") {
+		t.Errorf("Expected the nightly channel, found %q", stdout.String())
+	}
+}
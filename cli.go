@@ -29,14 +29,25 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// Errors returned by Run. They are translated with CLI.Strings, so check for
+// them with errors.Is rather than comparing them directly.
 var (
 	ErrNotImplemented   = errors.New("not implemented")
 	ErrTooManyArguments = errors.New("too many arguments")
@@ -49,7 +60,9 @@ var (
 type CLI struct {
 	// Name of the program, for help text and command-line examples. This must
 	// be a valid filename on every target system and MUST NOT CONTAIN SPACES.
-	// If you do not set this it will be set automatically using this snippet:
+	// Names reserved by Windows, such as CON or NUL, and names that end with a
+	// dot are also rejected. If you do not set this it will be set
+	// automatically using this snippet, without any .exe extension:
 	//
 	//	filepath.Base(os.Args[0])
 	Name string
@@ -72,6 +85,260 @@ type CLI struct {
 
 	// Commands are invoked by their map key.
 	Commands map[string]*Command
+
+	// HelpDir is a directory containing additional help topics that are loaded
+	// when the program starts, one topic per file. The location may also be
+	// set by the user via the PROG_HELP_DIR environment variable, which takes
+	// precedence. See LoadHelpDir for details.
+	HelpDir string
+
+	// Glossary maps terms used by the program to their definitions. When it is
+	// set, "program help glossary" lists every term and "program help glossary
+	// <term>" shows a single entry. See GlossaryHelp.
+	Glossary map[string]string
+
+	// Changelog is the program's changelog in Markdown, typically embedded
+	// with go:embed. When it is set, the built-in changelog command renders it
+	// in the terminal, and "program changelog --since <version>" shows only
+	// what changed after the specified version. See RenderChangelog.
+	Changelog string
+
+	// Licenses lists the third-party modules compiled into the program and
+	// their license notices. When it is set, the built-in licenses command
+	// prints the notices and the about command summarizes them, to help meet
+	// the attribution requirements of open source licenses. See License.
+	Licenses []License
+
+	// WhatsNew holds short release notes for the current Version. When it is
+	// set, the first time the user runs a new version of the program a
+	// "What's new" notice with these notes is printed to stderr before the
+	// normal output. The last version that was run is recorded in the
+	// StateDir.
+	WhatsNew string
+
+	// CommandNotFound is called when the user runs a command that does not
+	// exist, after user aliases and plugins have been checked. It receives
+	// the command name and the remaining arguments, and its result is
+	// returned by Run. Use it to implement fallbacks such as dynamic dispatch
+	// or a custom error message. If CommandNotFound is nil, Run returns an
+	// error explaining that the command does not exist.
+	CommandNotFound func(name string, args []string) error
+
+	// Authorize is called before a command is run with the command's name or
+	// path, such as "release create", so a program can enforce permissions or
+	// license tiers in one place. If it returns an error the command is not
+	// run and the error is returned by Run. Parent commands are checked
+	// before their subcommands.
+	Authorize func(command string) error
+
+	// HideUnauthorized leaves commands that Authorize denies out of the
+	// command list, help topics, and completion.
+	HideUnauthorized bool
+
+	// ErrorHandler is called by Main when Run returns an error. It reports the
+	// error to the user and returns the exit status for the program, so an
+	// application can format errors and choose exit codes in one place. If
+	// ErrorHandler is nil, the error is written to stderr as formatted by
+	// RenderError and the exit status is 1.
+	ErrorHandler func(err error) int
+
+	// Tutorial defines the steps of a guided walkthrough of the program. When
+	// it is set, the built-in tutorial command takes the user through each
+	// step interactively, saving their progress in the StateDir. See
+	// RunTutorial.
+	Tutorial []TutorialStep
+
+	// Links maps names the binary may be installed as, through a symlink or
+	// a copy, to the command path each one runs. For example, with
+	//
+	//	Links: map[string]string{"deploy": "deploy", "rc": "release create"}
+	//
+	// running the binary as "rc v1.2" is the same as "program release create
+	// v1.2", and the usage line in the command's help starts with "rc". Name
+	// should be set when using Links, since it can't be derived from
+	// os.Args[0].
+	Links map[string]string
+
+	// DisableHelp turns off the built-in help command and --help flag, so the
+	// program can handle them itself. For example, a wrapper may forward
+	// --help to the tool it wraps. Running the program without a command
+	// still lists its commands.
+	DisableHelp bool
+
+	// DisableVersion turns off the built-in --version flag, including the
+	// --version flag of commands that set Version.
+	DisableVersion bool
+
+	// Reserved renames or gives up the names of built-in commands and flags,
+	// such as help and --version. See ReservedNames.
+	Reserved *ReservedNames
+
+	// Strings translates the messages the framework shows to users, such as
+	// "'%s' is not a %s command", into the language of the deployment. See
+	// Strings.
+	Strings Strings
+
+	// Annotations hold arbitrary machine-readable metadata about the program,
+	// such as the owning team. They do not affect the behavior of the program
+	// but are included in Introspect output for use by external tooling.
+	Annotations map[string]string
+
+	// Completion enables the built-in completion command, which generates tab
+	// completion scripts for bash, zsh, and fish. See CompletionScript.
+	Completion bool
+
+	// Hooks enables user hook scripts that run before and after commands. See
+	// Hooks for details.
+	Hooks *Hooks
+
+	// UserAliases enables the built-in alias command, which lets users define
+	// their own shortcuts for commands they use often:
+	//
+	//	program alias set rl 'releases list --env {1}'
+	//	program rl staging
+	//
+	// Placeholders like {1} are replaced by the alias's arguments, and any
+	// remaining arguments are appended. Aliases are stored in the ConfigDir
+	// and can't hide built-in or defined commands.
+	UserAliases bool
+
+	// ShellInit enables the built-in shell-init command, which prints a
+	// snippet for the user's shell startup file that sets up completion and
+	// the aliases and prompt hook defined in ShellInit. See ShellInitScript.
+	ShellInit *ShellInit
+
+	// Layout controls the formatting of help output. The zero value uses the
+	// default layout.
+	Layout HelpLayout
+
+	// Printer is used for all output produced by the CLI, such as help text,
+	// and is available for your commands to use as well. If Printer is nil,
+	// output is written to os.Stdout and os.Stderr.
+	Printer *Printer
+
+	// FlagsFirst switches command flag parsing to POSIX behavior, where flags
+	// must appear before any positional arguments and parsing stops at the
+	// first positional argument. By default flags and positional arguments may
+	// be mixed, GNU-style, so "program cp file --verbose" works as expected.
+	FlagsFirst bool
+
+	// Prompt enables interactive prompting for required flags and arguments.
+	// When a command is invoked without a required value and both stdin and
+	// stderr are attached to a terminal, the user is asked to enter it instead
+	// of the command failing. Sensitive values are not echoed.
+	Prompt bool
+
+	// Plugins enables git-style external commands. When the user invokes a
+	// command that isn't defined, Run looks for an executable named after the
+	// program and the command, like "program-command", among the plugins
+	// installed by InstallPlugin and then in PATH, and runs it with the
+	// remaining arguments.
+	//
+	// Plugins get tab completion when Completion is enabled. To complete a
+	// plugin's arguments, the completion command runs
+	//
+	//	program-command __complete <args...>
+	//
+	// where the last argument is the (possibly empty) word being completed.
+	// The plugin should print candidates one per line, followed by a line with
+	// a directive: ":values" to use the candidates, or ":files", ":dirs",
+	// ":hosts", ":default", or ":none". ":files" may be followed by a
+	// space-separated list of extensions. Plugins built with this package
+	// support the protocol by setting Completion.
+	Plugins bool
+
+	// ResponseFiles enables expansion of @file arguments. Any argument of the
+	// form @path is replaced by the contents of that file, one argument per
+	// line. See ExpandResponseFiles for details.
+	ResponseFiles bool
+
+	// RawArgs disables the cleanup NormalizeArgs does on arguments before
+	// they are parsed, for programs that need arguments exactly as they
+	// were passed, such as to handle arbitrary bytes.
+	RawArgs bool
+
+	// SortOrder controls how command names, help topics, and categories are
+	// ordered in listings, such as SortCollated or SortNatural. Defaults to
+	// SortBytes.
+	SortOrder SortOrder
+
+	// HistorySection adds a section at the top of the command help listing
+	// the commands the user ran most recently (HistoryRecent) or most often
+	// (HistoryFrequent), to help users of programs with many commands find
+	// the ones they use. Command history is recorded in the StateDir.
+	HistorySection HistorySection
+
+	// OfflineProbe is an address, such as "api.example.com:443", that Offline
+	// connects to in order to tell whether the network is reachable. If it is
+	// empty, the network is considered reachable when any network interface
+	// other than loopback is up.
+	OfflineProbe string
+
+	// Channel is the release channel the program follows unless the user
+	// chooses another, one of "stable", "beta", or "nightly". Setting it
+	// enables release channels: the user can follow another channel by
+	// passing --channel before the command name, by setting PROG_CHANNEL
+	// in the environment, or with a "channel" setting in the configuration
+	// file. The active channel is shown by --version --full, and
+	// NewerRelease only offers updates from the active channel or a more
+	// stable one.
+	Channel string
+
+	// ConfigVersion is the current version of the configuration file format
+	// read by LoadConfig. When it is set, SaveConfig records it in the file's
+	// "version" field, and LoadConfig upgrades files written in an older
+	// format by running ConfigMigrations. When it is zero the configuration
+	// is not versioned and "version" is an ordinary setting.
+	ConfigVersion int
+
+	// ConfigMigrations upgrade the configuration file from older formats,
+	// keyed by the version each one upgrades from: ConfigMigrations[1]
+	// converts a version 1 file to version 2. Files without a version are
+	// version 0. Versions without a migration need no changes. See Migration.
+	ConfigMigrations map[int]Migration
+
+	// experimental is set by Run when the user passes --enable-experimental.
+	experimental bool
+
+	// mu guards Commands against concurrent use of AddCommand and
+	// RemoveCommand.
+	mu sync.RWMutex
+
+	// compiled is set by Compile.
+	compiled *compiledTree
+
+	// listing caches the sorted command list. See commandListing.
+	listing *commandListing
+
+	// result collects the outcome of a call to Execute.
+	result *RunResult
+
+	// ctx is the parent of each command's Context. See RunArgsContext.
+	ctx context.Context
+
+	// linkName and linkPath record the link in Links that Run was invoked
+	// through, if any.
+	linkName string
+	linkPath string
+
+	// logFile is the file opened for --log-file.
+	logFile *os.File
+
+	// detach is set by Run when the user passes --detach.
+	detach bool
+
+	// watchPaths are the paths passed to --watch.
+	watchPaths []string
+
+	// offline is set by Run when the user passes --offline, and
+	// offlineDetected caches whether the network was found to be
+	// unreachable. See Offline.
+	offline         bool
+	offlineDetected *bool
+	offlineMu       sync.Mutex
+
+	// channel is set by Run when the user passes --channel.
+	channel string
 }
 
 // Run starts by parsing os.Args[1:] and uses the first "argument" to the
@@ -88,28 +355,101 @@ type CLI struct {
 // invoked when calling commands or subcommands, so you may use this as an
 // argument or other input to your program.
 //
-// All Commands should be specified before Run is called. Modifying CLI or
-// Commands after calling Run will produce undefined behavior.
+// Commands may be added or removed at any time, including while Run is in
+// progress, using AddCommand and RemoveCommand. Modifying CLI or the Commands
+// map directly after calling Run will produce undefined behavior.
+//
+// To run the program with other arguments use RunArgs, and to find out which
+// command ran use Execute.
 func (c *CLI) Run() error {
-	commandName, args := ParseArgs(os.Args[1:])
+	args := os.Args[1:]
+	c.linkName, c.linkPath = "", ""
+	if path, ok := c.Links[programName(os.Args[0])]; ok {
+		c.linkName, c.linkPath = programName(os.Args[0]), path
+		args = append(strings.Fields(path), args...)
+	}
+	return c.run(args)
+}
+
+// RunArgs is like Run, but parses args instead of os.Args[1:]. args should not
+// include the program name. This allows tests and programs that embed a CLI to
+// run commands without modifying os.Args:
+//
+//	err := app.RunArgs([]string{"deploy", "--env", "staging", "web"})
+//
+// If Name is not set it is still derived from os.Args[0].
+func (c *CLI) RunArgs(args []string) error {
+	return c.run(args)
+}
+
+// RunArgsContext is like RunArgs, but the Context passed to the command is
+// canceled when ctx is canceled, in addition to when the program receives an
+// interrupt.
+func (c *CLI) RunArgsContext(ctx context.Context, args []string) error {
+	c.ctx = ctx
+	defer func() { c.ctx = nil }()
+	return c.run(args)
+}
+
+// run implements Run, RunArgs, and Execute.
+func (c *CLI) run(input []string) error {
+	var err error
+	if c.ResponseFiles {
+		if input, err = expandResponseFiles(input, c.Strings); err != nil {
+			return err
+		}
+	}
+
+	if !c.RawArgs {
+		input = NormalizeArgs(input)
+	}
+
+	// Global flags that change the Printer only apply to this run
+	if c.Printer == nil {
+		c.Printer = &Printer{}
+	}
+	defer func(width int, noColor bool) {
+		c.Printer.Width, c.Printer.NoColor = width, noColor
+	}(c.Printer.Width, c.Printer.NoColor)
+
+	if input, err = c.parseGlobalFlags(input); err != nil {
+		return err
+	}
+
+	commandName, args := ParseArgs(input)
+
+	// Compile has already loaded help topics and validated names
+	if c.tree() != nil {
+		return c.finishJob(c.watch(commandName, args))
+	}
+
+	helpDir := c.HelpDir
+	if dir := os.Getenv(c.envVar("HELP_DIR")); dir != "" {
+		helpDir = dir
+	}
+	if helpDir != "" {
+		if err := LoadHelpDir(c, helpDir); err != nil {
+			return err
+		}
+	}
 
 	// Set a default name for the program in case the user forgot to set one.
 	// This also automatically detects the program name if the binary is renamed
 	// so it's a decent default behavior.
 	if c.Name == "" {
-		c.Name = filepath.Base(os.Args[0])
+		c.Name = programName(os.Args[0])
 	}
 
 	// Enforce no spaces in command and program names because this will break
 	// all kinds of stuff. There are technically other ways to break the program
 	// (non-printing characters, for example) but to be as permissive as
-	// possible for UTF-8 names we won't validate anything else.
-	if strings.ContainsAny(c.Name, " \n\t") {
-		// This could happen because of user behavior so we'll error instead of
-		// panicking and give the user a chance to fix it.
-		return fmt.Errorf("program name (%q) must not contain spaces, try renaming the binary", c.Name)
+	// possible for UTF-8 names we only reject names that aren't valid
+	// filenames on Windows. This could happen because of user behavior so
+	// we'll error instead of panicking and give the user a chance to fix it.
+	if err := c.validateName(); err != nil {
+		return err
 	}
-	for name := range c.Commands {
+	for name := range c.commands() {
 		if strings.ContainsAny(name, " \n\t") {
 			// This is a programmer error and there's no way for the user to fix
 			// it so we'll just panic.
@@ -117,32 +457,429 @@ func (c *CLI) Run() error {
 		}
 	}
 
-	switch commandName {
-	case "":
-		fmt.Print(CommandHelp(c))
+	return c.finishJob(c.watch(commandName, args))
+}
+
+// dispatch invokes a built-in or user-defined command.
+func (c *CLI) dispatch(commandName string, args []string) error {
+	c.showWhatsNew(commandName)
+	c.warnStaleCompletion(commandName)
+
+	if commandName == "" {
+		c.Printer.Out(CommandHelp(c))
 		return nil
+	}
+
+	switch c.builtinKey(commandName) {
 	case "--help":
-		fmt.Print(CommandHelp(c))
+		c.Printer.Out(CommandHelp(c))
 		return nil
 	case "--version":
-		fmt.Println(Version(c))
+		if len(args) > 0 && args[0] == "--json" {
+			output, err := VersionJSON(c)
+			if err != nil {
+				return err
+			}
+			c.Printer.Out(output, "\n")
+			return nil
+		}
+		if len(args) > 0 && args[0] == "--full" {
+			c.Printer.Out(VersionFull(c))
+			return nil
+		}
+		c.Printer.Out(Version(c), "\n")
 		return nil
 	case "help":
 		output, err := Help(c, args)
 		if err != nil {
 			return err
 		}
-		fmt.Print(output)
+		c.Printer.Out(output)
+		return nil
+	case "completion", "__complete":
+		if !c.Completion {
+			break
+		}
+		if commandName == "__complete" {
+			c.checkCompletionSchema()
+			c.Printer.Out(Complete(c, args).String())
+			return nil
+		}
+		if len(args) > 0 && args[0] == "cache" {
+			if len(args) != 2 || args[1] != "clear" {
+				return c.Strings.errorf("usage: %s completion cache clear", c.Name)
+			}
+			if err := c.ClearCompletionCache(); err != nil {
+				return err
+			}
+			c.Printer.Out("Completion cache cleared\n")
+			return nil
+		}
+		if len(args) > 1 {
+			return c.Strings.errorf("usage: %s completion [%s]", c.Name, strings.Join(completionShells, "|"))
+		}
+		shell := ""
+		if len(args) == 1 {
+			shell = args[0]
+		} else if shell = DetectShell(); shell == "" {
+			return c.Strings.errorf("unable to detect your shell. usage: %s completion <%s>", c.Name, strings.Join(completionShells, "|"))
+		}
+		script, err := CompletionScript(c, shell)
+		if err != nil {
+			return err
+		}
+		c.Printer.Out(script)
+		return nil
+	case "alias":
+		if !c.UserAliases {
+			break
+		}
+		return c.aliasCommand(args)
+	case "changelog":
+		if c.Changelog == "" {
+			break
+		}
+		return c.changelogCommand(args)
+	case "licenses":
+		if len(c.Licenses) == 0 {
+			break
+		}
+		return c.licensesCommand(args)
+	case "examples":
+		if !c.hasExamples() {
+			break
+		}
+		return c.examplesCommand(args)
+	case "tutorial":
+		if len(c.Tutorial) == 0 {
+			break
+		}
+		return c.tutorialCommand(args)
+	case "jobs":
+		if !c.hasDetachable() {
+			break
+		}
+		return c.jobsCommand(args)
+	case "about":
+		if len(c.Licenses) == 0 {
+			break
+		}
+		if len(args) > 0 {
+			return c.Strings.errorf("usage: %s about", c.Name)
+		}
+		c.Printer.Out(About(c))
+		return nil
+	case "shell-init":
+		if c.ShellInit == nil {
+			break
+		}
+		if len(args) > 1 {
+			return c.Strings.errorf("usage: %s shell-init [%s]", c.Name, strings.Join(shellInitShells, "|"))
+		}
+		shell := ""
+		if len(args) == 1 {
+			shell = args[0]
+		} else if shell = DetectShell(); shell == "" {
+			return c.Strings.errorf("unable to detect your shell. usage: %s shell-init <%s>", c.Name, strings.Join(shellInitShells, "|"))
+		}
+		script, err := ShellInitScript(c, shell)
+		if err != nil {
+			return err
+		}
+		c.Printer.Out(script)
 		return nil
 	}
 
-	command, ok := c.Commands[commandName]
+	command, ok := c.lookup(commandName)
+	if !ok && c.UserAliases {
+		aliases, err := c.LoadAliases()
+		if err != nil {
+			return err
+		}
+		if expansion, isAlias := aliases[commandName]; isAlias {
+			expanded, err := c.expandAlias(commandName, expansion, args)
+			if err != nil {
+				return err
+			}
+			// Aliases may not refer to other aliases, to prevent loops
+			if _, isCommand := c.lookup(expanded[0]); !isCommand {
+				if _, isAlias := aliases[expanded[0]]; isAlias {
+					return c.Strings.errorf("alias '%s' refers to another alias, '%s'", commandName, expanded[0])
+				}
+			}
+			return c.dispatch(expanded[0], expanded[1:])
+		}
+	}
+	if !ok && c.Plugins {
+		if path, ok := c.pluginPath(commandName); ok {
+			return c.runPlugin(path, args)
+		}
+	}
+	if !ok && c.CommandNotFound != nil {
+		return c.CommandNotFound(commandName, args)
+	}
 	if !ok {
-		return fmt.Errorf("'%s' is not a %s command. See '%s --help'.", commandName, c.Name, c.Name)
+		return c.unknownCommand(commandName)
 	}
 
-	if command.Run == nil {
-		return ErrNotImplemented
+	return c.execute(commandName, command, args)
+}
+
+// unknownCommand returns the error for a command name that is not defined,
+// suggesting similar commands like the error for an unknown subcommand.
+func (c *CLI) unknownCommand(name string) error {
+	var names []string
+	commands := c.commands()
+	for _, commandName := range c.commandListing().names {
+		if command := commands[commandName]; command != nil && c.listed(command) && c.permitted(commandName) {
+			names = append(names, commandName)
+		}
+	}
+	if suggestions := Suggest(name, names); len(suggestions) > 0 {
+		return c.Strings.errorf("'%s' is not a %s command, did you mean %s?", name, c.Name, strings.Join(suggestions, " or "))
+	}
+	if c.DisableHelp {
+		return c.Strings.errorf("'%s' is not a %s command.", name, c.Name)
+	}
+	return c.Strings.errorf("'%s' is not a %s command. See '%s --%s'.", name, c.Name, c.Name, c.reserved().HelpFlag)
+}
+
+// execute runs command, which may be a subcommand, in which case commandName
+// is its full path such as "release create".
+func (c *CLI) execute(commandName string, command *Command, args []string) error {
+	var err error
+	if command.Experimental && !c.ExperimentalEnabled() {
+		return c.Strings.errorf("'%s' is an experimental command. Set %s=1 or pass --enable-experimental to use it.", commandName, c.envVar("EXPERIMENTAL"))
+	}
+	if !command.supported() {
+		return c.unsupportedPlatform(commandName, command)
+	}
+	if c.Authorize != nil {
+		if err := c.Authorize(commandName); err != nil {
+			return err
+		}
+	}
+
+	// Only commands that are allowed to run are recorded
+	c.recordCommand(commandName)
+	c.recordHistory(commandName)
+
+	if len(command.Commands) > 0 {
+		return c.dispatchSubcommand(commandName, command, args)
+	}
+
+	// Show the command's help page when --help is passed, unless the command
+	// has defined its own --help flag
+	names := c.reserved()
+	if !c.DisableHelp && flagRequested(args, names.HelpFlag) && (command.Flags == nil || command.Flags.Lookup(names.HelpFlag) == nil) {
+		c.Printer.Out(helpPage(c, commandName, command))
+		return nil
+	}
+
+	if command.Version != "" && !c.DisableVersion && flagRequested(args, names.VersionFlag) && (command.Flags == nil || command.Flags.Lookup(names.VersionFlag) == nil) {
+		c.Printer.Outf("%s %s version %s\n", c.Name, commandName, command.Version)
+		return nil
+	}
+
+	if command.Run == nil && command.RunContext == nil && command.Runner == nil && command.Eval == nil {
+		return c.Strings.translate(ErrNotImplemented)
+	}
+
+	input := args
+	args, force := command.takeFlag(args, "force", command.Cooldown > 0)
+	args, noCache := command.takeFlag(args, "no-cache", command.CacheTTL > 0)
+	args, refresh := command.takeFlag(args, "refresh", command.CacheTTL > 0)
+	cacheKey := resultCacheKey(commandName, args)
+	if command.Flags != nil {
+		parser := &flagParser{
+			set:        command.Flags,
+			permissive: command.PermissiveFlags,
+			flagsFirst: c.FlagsFirst,
+			messages:   c.Strings,
+		}
+		if args, err = parser.parse(args); err != nil {
+			return &UsageError{Command: commandName, Err: err}
+		}
+		if err = c.applyFlagEnv(command); err != nil {
+			return &UsageError{Command: commandName, Err: err}
+		}
+	}
+
+	if err = c.checkRequiredEnv(command); err != nil {
+		return err
+	}
+	if err = c.checkPrerequisites(command); err != nil {
+		return err
+	}
+	if err = c.checkCooldown(commandName, command, force || command.boolFlag("force")); err != nil {
+		return err
+	}
+
+	// Flags and the environment are checked before detaching so mistakes are
+	// reported right away rather than in the job's log
+	if c.detach {
+		if !command.Detachable {
+			return c.Strings.errorf("'%s' can't be run in the background", commandName)
+		}
+		return c.startJob(commandName, input)
+	}
+
+	if args, err = c.resolveRequired(command, args); err != nil {
+		return &UsageError{Command: commandName, Err: err}
+	}
+	args = expandArgGlobs(command, args)
+	c.recordArgs(command, args)
+
+	noCache = noCache || command.boolFlag("no-cache")
+	refresh = refresh || command.boolFlag("refresh")
+	if command.CacheTTL > 0 && !noCache && !refresh && c.cachedResult(cacheKey, command.CacheTTL) {
+		return nil
+	}
+	var capture *bytes.Buffer
+	if command.CacheTTL > 0 && !noCache {
+		capture = &bytes.Buffer{}
+		c.Printer.capture = capture
+		defer func() { c.Printer.capture = nil }()
+	}
+
+	if c.Hooks == nil {
+		err = c.invoke(commandName, command, args)
+	} else {
+		if err := c.runHook("pre", commandName, args, nil); err != nil {
+			return err
+		}
+		err = c.invoke(commandName, command, args)
+		if hookErr := c.runHook("post", commandName, args, err); hookErr != nil && err == nil {
+			err = hookErr
+		}
+	}
+	if err == nil {
+		c.recordCooldown(commandName, command)
+		if capture != nil {
+			c.saveResult(cacheKey, capture.Bytes())
+		}
+	}
+	return err
+}
+
+// dispatchSubcommand runs the subcommand of parent named by the first
+// argument. If no subcommand is specified the parent's help page is shown,
+// including its list of subcommands.
+func (c *CLI) dispatchSubcommand(parentName string, parent *Command, args []string) error {
+	helpFlag := c.reserved().HelpFlag
+	if len(args) == 0 || (!c.DisableHelp && (args[0] == "--"+helpFlag || args[0] == "-"+helpFlag || (helpFlag == "help" && args[0] == "-h"))) {
+		c.Printer.Out(helpPage(c, parentName, parent))
+		return nil
+	}
+
+	name := args[0]
+	command, ok := parent.Commands[name]
+	if !ok || command == nil || command.HelpOnly || (command.Experimental && !c.ExperimentalEnabled()) {
+		c.Printer.Err(subcommandHelp(c, parentName, parent), "\n")
+
+		var names []string
+		for subName, subcommand := range parent.Commands {
+			if c.listed(subcommand) && c.permitted(parentName+" "+subName) {
+				names = append(names, subName)
+			}
+		}
+		if suggestions := Suggest(name, names); len(suggestions) > 0 {
+			return c.Strings.errorf("'%s' is not a %s %s command, did you mean %s?", name, c.Name, parentName, strings.Join(suggestions, " or "))
+		}
+		if c.DisableHelp {
+			return c.Strings.errorf("'%s' is not a %s %s command.", name, c.Name, parentName)
+		}
+		return c.Strings.errorf("'%s' is not a %s %s command. See '%s %s --%s'.", name, c.Name, parentName, c.Name, parentName, helpFlag)
+	}
+
+	return c.execute(parentName+" "+name, command, args[1:])
+}
+
+// subcommandHelp returns the usage line and list of subcommands for a command
+// that has subcommands.
+func subcommandHelp(c *CLI, name string, command *Command) string {
+	return "usage: " + commandUsage(c, name, command) + "\n\nCommands\n\n" + subcommandList(c, name, command)
+}
+
+// subcommandList lists the subcommands of command with their summaries,
+// grouped by category.
+func subcommandList(c *CLI, name string, command *Command) (output string) {
+	names := []string{}
+	width := 0
+	for _, subName := range c.sortedCommandNames(command.Commands) {
+		if c.listed(command.Commands[subName]) && c.permitted(name+" "+subName) {
+			names = append(names, subName)
+			if len(subName) > width {
+				width = len(subName)
+			}
+		}
+	}
+
+	layout := c.layout()
+	indent := strings.Repeat(" ", layout.Indent)
+	gap := strings.Repeat(" ", layout.ColumnGap)
+	prefix := c.Name + " " + name + " "
+
+	prefixWidth := len(indent) + len(prefix) + width + len(gap)
+	wrapWidth := c.helpWidth() - prefixWidth
+	if wrapWidth < minWrapWidth {
+		wrapWidth = 0
+	}
+
+	for i, section := range c.categorize(names, command.Commands) {
+		if len(section.names) == 0 {
+			continue
+		}
+		if i > 0 {
+			output += "\n" + section.title + "\n\n"
+		}
+		for _, subName := range section.names {
+			summary := strings.Join(wrapWords(command.Commands[subName].Summary, wrapWidth), "\n"+strings.Repeat(" ", prefixWidth))
+			output += strings.TrimRight(fmt.Sprintf("%s%s%s%s%s", indent, prefix, PadRight(subName, width), gap, summary), " ") + "\n"
+		}
+	}
+	return
+}
+
+// lookupPath finds a command by its full path, such as "release create" for
+// the create subcommand of the release command.
+func (c *CLI) lookupPath(path string) (*Command, bool) {
+	names := strings.Fields(path)
+	if len(names) == 0 {
+		return nil, false
+	}
+	command, ok := c.lookup(names[0])
+	for _, name := range names[1:] {
+		if !ok {
+			break
+		}
+		command, ok = command.Commands[name]
+		ok = ok && command != nil
+	}
+	return command, ok
+}
+
+// invoke calls the function that implements a command.
+func (c *CLI) invoke(commandName string, command *Command, args []string) error {
+	if command.Eval != nil {
+		ctx, cancel := c.newContext(commandName, command, args)
+		defer cancel()
+		script := NewShellScript("")
+		script.messages = c.Strings
+		if err := command.Eval(ctx, script); err != nil {
+			return err
+		}
+		c.Printer.Out(script.String())
+		return nil
+	}
+
+	if command.RunContext != nil || command.Runner != nil {
+		ctx, cancel := c.newContext(commandName, command, args)
+		defer cancel()
+		if command.RunContext != nil {
+			return command.RunContext(ctx)
+		}
+		return command.Runner.Run(ctx, args)
 	}
 
 	if err := command.Run(args); err != nil {
@@ -152,6 +889,198 @@ func (c *CLI) Run() error {
 	return nil
 }
 
+// HelpLayout controls the formatting of the command list and help pages so you
+// can match the house style of your organization or other tools.
+type HelpLayout struct {
+	// MaxWidth limits the width of help output. Text is wrapped to fit the
+	// terminal or MaxWidth, whichever is narrower. When output is not going to
+	// a terminal it is wrapped to MaxWidth. If MaxWidth is zero, help output
+	// is only wrapped to fit the terminal.
+	MaxWidth int
+
+	// Indent is the number of spaces in front of each entry in the command
+	// list and help topic list. Defaults to 2.
+	Indent int
+
+	// ColumnGap is the number of spaces between command names and their
+	// summaries in the command list. Defaults to 3.
+	ColumnGap int
+}
+
+// layout returns c.Layout with defaults filled in.
+func (c *CLI) layout() HelpLayout {
+	layout := c.Layout
+	if layout.Indent == 0 {
+		layout.Indent = 2
+	}
+	if layout.ColumnGap == 0 {
+		layout.ColumnGap = 3
+	}
+	return layout
+}
+
+// helpWidth returns the width that help text should be wrapped to, or zero if
+// it should not be wrapped.
+func (c *CLI) helpWidth() int {
+	width := c.Printer.OutputWidth()
+	if c.Layout.MaxWidth > 0 && (width == 0 || width > c.Layout.MaxWidth) {
+		width = c.Layout.MaxWidth
+	}
+	return width
+}
+
+// parseGlobalFlags applies any built-in flags that appear in front of the
+// command name, and returns the remaining input. Global flags are only
+// recognized in this position so they will not collide with flags or
+// arguments passed to the command itself.
+func (c *CLI) parseGlobalFlags(input []string) ([]string, error) {
+	if c.Printer == nil {
+		c.Printer = &Printer{}
+	}
+	if envEnabled(c.envVar("ACCESSIBLE")) {
+		c.Printer.Accessible = true
+	}
+	c.experimental = false
+	c.detach = false
+	c.watchPaths = nil
+	c.offline = false
+	c.channel = ""
+	c.offlineMu.Lock()
+	c.offlineDetected = nil
+	c.offlineMu.Unlock()
+
+	for len(input) > 0 {
+		switch input[0] {
+		case "--enable-experimental":
+			c.experimental = true
+		case "--detach":
+			c.detach = true
+		case "--offline":
+			c.offline = true
+		case "--no-emoji":
+			c.Printer.ASCII = true
+		case "--accessible":
+			c.Printer.Accessible = true
+		case "--no-color":
+			c.Printer.NoColor = true
+		case "--width":
+			if len(input) < 2 {
+				return nil, c.Strings.errorf("flag --%s requires a value", "width")
+			}
+			if err := c.setWidth(input[1]); err != nil {
+				return nil, err
+			}
+			input = input[1:]
+		case "--log-file":
+			if len(input) < 2 {
+				return nil, c.Strings.errorf("flag --%s requires a value", "log-file")
+			}
+			if err := c.openLogFile(input[1]); err != nil {
+				return nil, err
+			}
+			input = input[1:]
+		case "--watch":
+			if len(input) < 2 {
+				return nil, c.Strings.errorf("flag --%s requires a value", "watch")
+			}
+			if err := c.setWatch(input[1]); err != nil {
+				return nil, err
+			}
+			input = input[1:]
+		case "--progress":
+			if len(input) < 2 {
+				return nil, c.Strings.errorf("flag --%s requires a value", "progress")
+			}
+			if err := c.setProgress(input[1]); err != nil {
+				return nil, err
+			}
+			input = input[1:]
+		case "--channel":
+			if len(input) < 2 {
+				return nil, c.Strings.errorf("flag --%s requires a value", "channel")
+			}
+			if err := c.setChannel(input[1]); err != nil {
+				return nil, err
+			}
+			input = input[1:]
+		case "--record":
+			if len(input) < 2 {
+				return nil, c.Strings.errorf("flag --%s requires a value", "record")
+			}
+			if err := c.openRecording(input[1], input[2:]); err != nil {
+				return nil, err
+			}
+			input = input[1:]
+		default:
+			if strings.HasPrefix(input[0], "--width=") {
+				if err := c.setWidth(strings.TrimPrefix(input[0], "--width=")); err != nil {
+					return nil, err
+				}
+				break
+			}
+			if strings.HasPrefix(input[0], "--log-file=") {
+				if err := c.openLogFile(strings.TrimPrefix(input[0], "--log-file=")); err != nil {
+					return nil, err
+				}
+				break
+			}
+			if strings.HasPrefix(input[0], "--watch=") {
+				if err := c.setWatch(strings.TrimPrefix(input[0], "--watch=")); err != nil {
+					return nil, err
+				}
+				break
+			}
+			if strings.HasPrefix(input[0], "--progress=") {
+				if err := c.setProgress(strings.TrimPrefix(input[0], "--progress=")); err != nil {
+					return nil, err
+				}
+				break
+			}
+			if strings.HasPrefix(input[0], "--channel=") {
+				if err := c.setChannel(strings.TrimPrefix(input[0], "--channel=")); err != nil {
+					return nil, err
+				}
+				break
+			}
+			if strings.HasPrefix(input[0], "--record=") {
+				if err := c.openRecording(strings.TrimPrefix(input[0], "--record="), input[1:]); err != nil {
+					return nil, err
+				}
+				break
+			}
+			return input, nil
+		}
+		input = input[1:]
+	}
+
+	return input, nil
+}
+
+// setWidth overrides the output width for the current run. Programs we run
+// receive it as COLUMNS; see childEnv.
+func (c *CLI) setWidth(value string) error {
+	width, err := strconv.Atoi(value)
+	if err != nil || width < 1 {
+		return c.Strings.errorf("invalid value %q for flag --width: expected a positive number", value)
+	}
+	c.Printer.Width = width
+	return nil
+}
+
+// setProgress sets how progress is reported: "json" for ProgressEvents, or
+// "auto" to draw a progress bar when stderr is a terminal.
+func (c *CLI) setProgress(value string) error {
+	switch value {
+	case "json":
+		c.Printer.ProgressJSON = true
+	case "auto":
+		c.Printer.ProgressJSON = false
+	default:
+		return c.Strings.errorf("invalid value %q for flag --progress: expected auto or json", value)
+	}
+	return nil
+}
+
 // Command defines a CLI command that may be invoked by the key name in
 // CLI.Commands. Command names MUST NOT CONTAIN SPACES. A space in a command
 // name will result in a panic.
@@ -163,6 +1092,27 @@ type Command struct {
 	// shown to the user
 	Run func(args []string) error
 
+	// RunContext is an alternative to Run that receives a Context carrying the
+	// parsed arguments and flags, standard streams, and a cancellation signal.
+	// If RunContext is set, it is called instead of Run.
+	RunContext func(ctx *Context) error
+
+	// Runner is an alternative to Run and RunContext for commands implemented
+	// as types. It is used if RunContext is not set. See Runner.
+	Runner Runner
+
+	// Eval is used instead of Run for commands that change the user's shell,
+	// such as by exporting environment variables or changing directories.
+	// The command adds statements to the ShellScript, which is printed in the
+	// syntax of the user's shell when Eval returns, and the user evaluates
+	// the output:
+	//
+	//	eval "$(program use staging)"
+	//
+	// Messages for the user should be written to stderr so they aren't
+	// evaluated.
+	Eval func(ctx *Context, script *ShellScript) error
+
 	// Summary is a terse description of the command shown in the command list.
 	// For long-form help text see the Help command.
 	//
@@ -175,13 +1125,56 @@ type Command struct {
 	// a normal command.
 	Summary string
 
+	// Category groups the command under a heading in the command list and the
+	// list of help topics, such as "Deployment" or "Configuration". Commands
+	// without a Category are listed first under the default heading.
+	Category string
+
+	// Synopsis is a one-line description of the command's syntax shown at the
+	// top of its help page, such as:
+	//
+	//	program run [--env <name>] [-- <cmd> [args...]]
+	//
+	// If Synopsis is empty one is generated from Flags and Args. Specify
+	// Synopsis when the generated form can't express the real grammar of the
+	// command.
+	Synopsis string
+
+	// Description is long-form prose describing what the command does and how
+	// to use it. It is displayed on the command's help page below the
+	// synopsis, via either "program help <command>" or
+	// "program <command> --help".
+	Description string
+
 	// Help bears a long-form help page. It may be associated with a command or
-	// displayed stand-alone, and will be displayed using the help command.
+	// displayed stand-alone, and will be displayed using the help command. For
+	// commands, prefer Description and reserve Help for stand-alone topics. If
+	// both are set, Help is displayed after Description.
 	//
 	// When the help command is invoked with no arguments, it will produce a
-	// list including each Command item with a non-empty Help string.
+	// list including each Command item with a non-empty Help or Description.
 	Help string
 
+	// Version of the command, for commands that wrap an independently
+	// versioned component. If Version is set, "program <command> --version"
+	// displays it instead of running the command, and it is included in the
+	// output of "program --version --json".
+	Version string
+
+	// Annotations hold arbitrary machine-readable metadata about the command,
+	// such as the owning team or stability tier. They do not affect the
+	// behavior of the command but are included in Introspect output for use
+	// by external tooling.
+	Annotations map[string]string
+
+	// Header defines arbitrary text that is displayed above the command's help
+	// page, such as a stability warning.
+	Header string
+
+	// Footer defines arbitrary text that is displayed below the command's help
+	// page, such as links to further documentation.
+	Footer string
+
 	// Hidden commands may still be invoked as normal, but will be excluded from
 	// the command list. This is useful for deprecating commands or creating
 	// additional or special commands that are not part of the UI.
@@ -196,7 +1189,113 @@ type Command struct {
 	// instructions.
 	HelpOnly bool
 
-	//TODO
+	// Experimental commands are hidden from the command list and help topics
+	// and refuse to run unless the user opts in by setting PROG_EXPERIMENTAL=1
+	// in the environment (where PROG is the upper-cased program name) or by
+	// passing --enable-experimental before the command name. This allows you to
+	// ship preview features without committing to them.
+	Experimental bool
+
+	// Detachable commands may be run in the background by passing --detach
+	// before the command name. They are tracked as jobs in the StateDir and
+	// managed with the built-in jobs command. Use this for commands that kick
+	// off long builds or syncs.
+	Detachable bool
+
+	// Platforms limits the command to the listed operating systems, or
+	// operating systems and architectures, in the form of GOOS or
+	// GOOS/GOARCH, such as "windows" or "linux/arm64". On other platforms the
+	// command is left out of help and completion, and running it is an
+	// error. If Platforms is empty the command is available everywhere.
+	Platforms []string
+
+	// Flags defines the flags accepted by the command. Flags are parsed before
+	// Run is called and any remaining positional arguments are passed to Run.
+	// Both -name and --name forms are accepted, values may be specified as
+	// --name=value or --name value, and flags may appear before or after
+	// positional arguments unless CLI.FlagsFirst is set. Everything after "--"
+	// is treated as a positional argument.
+	//
+	// If Flags is nil, all arguments are passed to Run exactly as they were
+	// received.
+	Flags *flag.FlagSet
+
+	// PermissiveFlags controls how flags that are not defined in Flags are
+	// handled. By default an unknown flag is an error. When PermissiveFlags is
+	// true, unknown flags are passed through to Run as positional arguments,
+	// which is useful for wrapper commands that forward arbitrary options to
+	// another program.
+	PermissiveFlags bool
+
+	// RequiredFlags lists the names of flags in Flags that must be set by the
+	// user. See CLI.Prompt.
+	RequiredFlags []string
+
+	// SensitiveFlags lists the names of flags in Flags whose values should be
+	// masked, such as passwords or API tokens.
+	SensitiveFlags []string
+
+	// CompletionTTL enables caching of the completions returned by a Runner
+	// that implements Completer, for completers that are slow, such as those
+	// that make network requests. Results are cached for each set of preceding
+	// arguments in the CacheDir until CompletionTTL has passed. Users can run
+	// "program completion cache clear" to discard cached results.
+	CompletionTTL time.Duration
+
+	// FlagCompletions describes how to complete the values of flags, keyed by
+	// flag name, for shell completion. Flags without an entry are not
+	// completed.
+	FlagCompletions map[string]Completion
+
+	// FlagEnv names an environment variable for each flag, keyed by flag name,
+	// that provides the flag's value when it isn't passed on the command line,
+	// such as {"token": "SHIP_TOKEN"}. Variables are listed in the Flags
+	// section of the command's help.
+	FlagEnv map[string]string
+
+	// RequiresEnv lists environment variables that must be set to run the
+	// command, such as credentials for an API. If any are unset or empty the
+	// command is not run, and the error names every missing variable. They
+	// are listed in the Environment section of the command's help.
+	RequiresEnv []string
+
+	// Requires lists external programs the command runs, such as docker or
+	// git. Each must be in the PATH, and new enough if it has a MinVersion,
+	// or the command is not run and the error explains what is missing.
+	Requires []Prerequisite
+
+	// Cooldown is the minimum time between runs of the command, for commands
+	// that are expensive or call rate-limited services. Running the command
+	// again too soon is an error that says how long to wait, unless the user
+	// passes --force. The last successful run is recorded in the StateDir.
+	Cooldown time.Duration
+
+	// CacheTTL enables caching of the output of a read-only command, such as
+	// one that queries a slow API. Output is cached in the CacheDir for each
+	// set of arguments, and is printed without running the command again
+	// until CacheTTL has passed. Only output from successful runs is cached,
+	// and only output written through the Printer or the Context is captured.
+	// The user can pass --refresh to run the command and update the cache, or
+	// --no-cache to run it without using the cache at all.
+	CacheTTL time.Duration
+
+	// Examples demonstrate how to use the command. They are shown on the
+	// command's help page, and "program examples <command>" lists them and
+	// can run one at the user's request.
+	Examples []Example
+
+	// ExitCodes documents the exit statuses the command may exit with other
+	// than 0 and 1, keyed by status, such as {3: "the deployment was rolled
+	// back"}. They are listed in the Exit Status section of the command's
+	// help. Return an error from WithExitCode to exit with one of them, and
+	// use CheckExitCode in tests to verify that the status is documented.
+	ExitCodes map[int]string
+
+	// Args describes the positional arguments accepted by the command, in
+	// order. Args is optional, but required arguments will be checked (or
+	// prompted for) before Run is called.
+	Args []ArgSpec
+
 	// Commands is used to implement subcommands invoked by calling the program
 	// name followed by the command, and subsequently the subcommand. These may
 	// be nested to any arbitrary depth.
@@ -205,12 +1304,147 @@ type Command struct {
 	//
 	// Any command that has subcommands cannot be invoked directly. Instead,
 	// command help will be displayed that lists the available subcommands. This
-	// prevents collisions between arguments and subcommand names.
+	// prevents collisions between arguments and subcommand names. If the user
+	// specifies a subcommand that does not exist, the list is printed to
+	// stderr along with suggestions for what they may have meant.
 	//
 	// While subcommands are analyzed recursively, the tree is analyzed only
 	// once when the CLI arguments are initially parsed and as a result the
 	// program cannot dynamically add subcommands on-the-fly.
-	//Commands map[string]*Command
+	Commands map[string]*Command
+
+	// lazy is set for placeholder commands registered by AddLazyCommand.
+	lazy *lazyCommand
+}
+
+// ExperimentalEnabled reports whether the user has opted into experimental
+// commands, either via --enable-experimental or the PROG_EXPERIMENTAL
+// environment variable.
+func (c *CLI) ExperimentalEnabled() bool {
+	if c.experimental {
+		return true
+	}
+	return envEnabled(c.envVar("EXPERIMENTAL"))
+}
+
+// envEnabled reports whether the named environment variable is set to a value
+// that turns a feature on, such as 1 or true.
+func envEnabled(name string) bool {
+	value := os.Getenv(name)
+	return value != "" && value != "0" && strings.ToLower(value) != "false"
+}
+
+// windowsReservedNames are device names that can't be used as filenames on
+// Windows, with or without an extension.
+var windowsReservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// programName derives the program name from arg0, the path the program was
+// invoked with, removing the .exe extension used on Windows.
+func programName(arg0 string) string {
+	name := filepath.Base(arg0)
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// validateName checks that c.Name is a valid filename on every platform.
+func (c *CLI) validateName() error {
+	if strings.ContainsAny(c.Name, " \n\t") {
+		return c.Strings.errorf("program name (%q) must not contain spaces, try renaming the binary", c.Name)
+	}
+	if strings.HasSuffix(c.Name, ".") {
+		return c.Strings.errorf("program name (%q) must not end with a dot, try renaming the binary", c.Name)
+	}
+	base := c.Name
+	if i := strings.Index(base, "."); i >= 0 {
+		base = base[:i]
+	}
+	for _, reserved := range windowsReservedNames {
+		if strings.EqualFold(base, reserved) {
+			return c.Strings.errorf("program name (%q) is reserved on Windows, try renaming the binary", c.Name)
+		}
+	}
+	return nil
+}
+
+// childEnv returns the environment for programs the CLI runs, such as hooks
+// and plugins, with extra variables added. The output width set by --width is
+// passed on as COLUMNS, and --no-color as NO_COLOR so they don't use color
+// either.
+func (c *CLI) childEnv(extra ...string) []string {
+	env := os.Environ()
+	if c.Printer != nil && c.Printer.Width > 0 {
+		env = append(env, "COLUMNS="+strconv.Itoa(c.Printer.Width))
+	}
+	if c.Printer != nil && c.Printer.NoColor {
+		env = append(env, "NO_COLOR=1")
+	}
+	return append(env, extra...)
+}
+
+// envVar returns the name of an environment variable scoped to this program,
+// such as MYPROG_EXPERIMENTAL. Characters that are not valid in a variable
+// name are replaced with underscores.
+func (c *CLI) envVar(suffix string) string {
+	name := c.Name
+	if name == "" {
+		name = programName(os.Args[0])
+	}
+	prefix := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+	return prefix + "_" + suffix
+}
+
+// permitted reports whether the command at path should be shown in listings,
+// which is false if HideUnauthorized is set and Authorize denies it.
+func (c *CLI) permitted(path string) bool {
+	return !c.HideUnauthorized || c.Authorize == nil || c.Authorize(path) == nil
+}
+
+// listed reports whether a command should be shown in the command list.
+func (c *CLI) listed(command *Command) bool {
+	if command == nil || command.Hidden || command.HelpOnly || !command.supported() {
+		return false
+	}
+	return !command.Experimental || c.ExperimentalEnabled()
+}
+
+// builtin reports whether name invokes a built-in command or flag that is
+// enabled, taking c.Reserved into account.
+func (c *CLI) builtin(name string) bool {
+	switch c.builtinKey(name) {
+	case "help", "--help", "--version":
+		return true
+	case "completion", "__complete":
+		return c.Completion
+	case "alias":
+		return c.UserAliases
+	case "shell-init":
+		return c.ShellInit != nil
+	case "changelog":
+		return c.Changelog != ""
+	case "licenses", "about":
+		return len(c.Licenses) > 0
+	case "examples":
+		return c.hasExamples()
+	case "tutorial":
+		return len(c.Tutorial) > 0
+	case "jobs":
+		return c.hasDetachable()
+	}
+	return false
 }
 
 // SortedCommandNames returns a list of command names in lexical order.
@@ -245,14 +1479,33 @@ func EnsureNewlines(text string) string {
 
 // CommandHelp
 func CommandHelp(c *CLI) (output string) {
-	names := SortedCommandNames(c.Commands)
+	commands := c.commands()
+	names := c.commandListing().names
+	width := c.nameWidth()
 
-	width := 0
-	for _, name := range names {
-		// Skip hidden and help-only commands
-		if !c.Commands[name].Hidden && !c.Commands[name].HelpOnly && len(name) > width {
-			width = len(name)
-		}
+	if c.builtin("completion") && len("completion") > width {
+		width = len("completion")
+	}
+	if c.builtin("alias") && len("alias") > width {
+		width = len("alias")
+	}
+	if c.builtin("shell-init") && len("shell-init") > width {
+		width = len("shell-init")
+	}
+	if c.builtin("changelog") && len("changelog") > width {
+		width = len("changelog")
+	}
+	if c.builtin("licenses") && len("licenses") > width {
+		width = len("licenses")
+	}
+	if c.builtin("examples") && len("examples") > width {
+		width = len("examples")
+	}
+	if c.builtin("tutorial") && len("tutorial") > width {
+		width = len("tutorial")
+	}
+	if c.builtin("jobs") && len("jobs") > width {
+		width = len("jobs")
 	}
 
 	header := c.Header
@@ -261,17 +1514,92 @@ func CommandHelp(c *CLI) (output string) {
 		output += EnsureNewlines(header) + "\n"
 	}
 
-	output += fmt.Sprintf("usage: %s [--version] [--help] <command> [<args>]", c.Name)
-	output += fmt.Sprint("\n\n", "Commands", "\n\n")
+	reserved := c.reserved()
+	if len(reserved.Help) > width {
+		width = len(reserved.Help)
+	}
+
+	output += "usage: " + c.Name
+	if !c.DisableVersion {
+		output += " [--" + reserved.VersionFlag + "]"
+	}
+	if !c.DisableHelp {
+		output += " [--" + reserved.HelpFlag + "]"
+	}
+	output += " <command> [<args>]\n\n"
+
+	layout := c.layout()
+	indent := strings.Repeat(" ", layout.Indent)
+	gap := strings.Repeat(" ", layout.ColumnGap)
+
+	// Summaries that don't fit in the terminal are wrapped and indented to line
+	// up with the first line.
+	prefixWidth := len(fmt.Sprintf("%s%s %s%s", indent, c.Name, PadRight("", width), gap))
+	wrapWidth := c.helpWidth() - prefixWidth
+	if wrapWidth < minWrapWidth {
+		wrapWidth = 0
+	}
+
+	line := func(name, summary string) string {
+		summary = strings.Join(wrapWords(summary, wrapWidth), "\n"+strings.Repeat(" ", prefixWidth))
+		return fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight(name, width), gap, summary)
+	}
+
+	if title, recent := c.historySection(); len(recent) > 0 {
+		output += title + "\n\n"
+		for _, name := range recent {
+			output += line(name, commands[name].Summary)
+		}
+		output += "\n"
+	}
+	output += "Commands\n\n"
 
+	// Skip hidden, help-only, and experimental commands
+	listed := []string{}
 	for _, name := range names {
-		// Skip hidden and help-only commands
-		if !c.Commands[name].Hidden && !c.Commands[name].HelpOnly {
-			output += fmt.Sprintf("  %s %s   %s\n", c.Name, PadRight(name, width), c.Commands[name].Summary)
+		if c.listed(commands[name]) && c.permitted(name) {
+			listed = append(listed, name)
 		}
 	}
-	if len(c.Commands) > -1 {
-		output += fmt.Sprintf("  %s %s   %s\n", c.Name, PadRight("help", width), "List help topics")
+	sections := c.categorize(listed, commands)
+	for _, name := range sections[0].names {
+		output += line(name, commands[name].Summary)
+	}
+	if c.builtin("completion") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("completion", width), gap, "Generate shell completion scripts")
+	}
+	if c.builtin("alias") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("alias", width), gap, "Manage your command aliases")
+	}
+	if c.builtin("shell-init") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("shell-init", width), gap, "Print shell integration for your shell startup file")
+	}
+	if c.builtin("changelog") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("changelog", width), gap, "Show what changed in each version")
+	}
+	if c.builtin("examples") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("examples", width), gap, "Show and run examples for a command")
+	}
+	if c.builtin("tutorial") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("tutorial", width), gap, "Learn to use "+c.Name+" step by step")
+	}
+	if c.builtin("jobs") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("jobs", width), gap, "List and manage background jobs")
+	}
+	if c.builtin("about") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("about", width), gap, "Show version and third-party software information")
+	}
+	if c.builtin("licenses") {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight("licenses", width), gap, "Show third-party license notices")
+	}
+	if c.builtin(reserved.Help) {
+		output += fmt.Sprintf("%s%s %s%s%s\n", indent, c.Name, PadRight(reserved.Help, width), gap, "List help topics")
+	}
+	for _, section := range sections[1:] {
+		output += "\n" + section.title + "\n\n"
+		for _, name := range section.names {
+			output += line(name, commands[name].Summary)
+		}
 	}
 
 	if c.Footer != "" {
@@ -281,6 +1609,105 @@ func CommandHelp(c *CLI) (output string) {
 	return
 }
 
+// CommandUsage returns the synopsis for the named command. If the command does
+// not specify Synopsis, one is generated from its Flags and Args, where
+// required arguments are shown as <name> and optional arguments as [<name>].
+func CommandUsage(c *CLI, name string) string {
+	if tree := c.tree(); tree != nil && name != c.linkPath {
+		if usage, ok := tree.usages[name]; ok {
+			return usage
+		}
+	}
+
+	command, ok := c.lookupPath(name)
+	if !ok {
+		return ""
+	}
+	return commandUsage(c, name, command)
+}
+
+// commandUsage returns the synopsis for command, which has the specified name
+// or path.
+func commandUsage(c *CLI, name string, command *Command) string {
+	if command.Synopsis != "" {
+		return command.Synopsis
+	}
+
+	usage := c.Name + " " + name
+	if c.linkPath != "" && name == c.linkPath {
+		usage = c.linkName
+	}
+	if len(command.Commands) > 0 {
+		return usage + " <command> [<args>]"
+	}
+
+	hasFlags := false
+	if command.Flags != nil {
+		command.Flags.VisitAll(func(*flag.Flag) {
+			hasFlags = true
+		})
+	}
+	if hasFlags {
+		usage += " [flags]"
+	}
+
+	for _, spec := range command.Args {
+		if spec.Required {
+			usage += " <" + spec.Name + ">"
+		} else {
+			usage += " [<" + spec.Name + ">]"
+		}
+	}
+
+	return usage
+}
+
+// VersionInfo describes the version of the program and any commands that
+// specify their own Version. It is displayed by "program --version --json".
+type VersionInfo struct {
+	Name     string            `json:"name"`
+	Version  string            `json:"version"`
+	Channel  string            `json:"channel,omitempty"`
+	Commands map[string]string `json:"commands,omitempty"`
+}
+
+// VersionJSON returns the program's VersionInfo encoded as JSON.
+func VersionJSON(c *CLI) (string, error) {
+	info := VersionInfo{
+		Name:    c.Name,
+		Version: c.Version,
+		Channel: c.ActiveChannel(),
+	}
+	for name, command := range c.resolvedCommands() {
+		if command.Version == "" || command.Hidden || command.HelpOnly {
+			continue
+		}
+		if info.Commands == nil {
+			info.Commands = map[string]string{}
+		}
+		info.Commands[name] = command.Version
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// VersionFull returns the output of "program --version --full": the version
+// followed by details of the build, including the active release channel if
+// the program uses channels.
+func VersionFull(c *CLI) string {
+	output := Version(c) + "\n"
+	if channel := c.ActiveChannel(); channel != "" {
+		output += "Channel:  " + channel + "\n"
+	}
+	output += "Platform: " + runtime.GOOS + "/" + runtime.GOARCH + "\n"
+	output += "Go:       " + runtime.Version() + "\n"
+	return output
+}
+
 func Version(c *CLI) string {
 	if c.Version == "" {
 		return fmt.Sprintf("%s version undefined", c.Name)
@@ -292,42 +1719,280 @@ func Help(c *CLI, args []string) (output string, err error) {
 	switch len(args) {
 	case 0:
 		// Show help topics if nothing is specified
-		output += fmt.Sprintf("usage: %s help <topic>\n\nHelp Topics\n\n", c.Name)
-		names := SortedCommandNames(c.Commands)
-		for _, topic := range names {
-			if !c.Commands[topic].Hidden && c.Commands[topic].Help != "" {
-				if c.Commands[topic].HelpOnly {
-					output += fmt.Sprintf("  %s\n", topic)
-				} else {
-					output += fmt.Sprintf("  %s (command)\n", topic)
+		output += fmt.Sprintf("usage: %s %s <topic>\n", c.Name, c.reserved().Help)
+		commands := c.resolvedCommands()
+		if c.hasGlossary() {
+			// List the glossary alongside the other topics
+			entries := make(map[string]*Command, len(commands)+1)
+			for name, command := range commands {
+				entries[name] = command
+			}
+			entries["glossary"] = &Command{Summary: "Definitions of terms used by " + c.Name, Help: "glossary", HelpOnly: true}
+			commands = entries
+		}
+
+		topics := []string{}
+		labels := map[string]string{}
+		width := 0
+		for _, topic := range c.sortedCommandNames(commands) {
+			command := commands[topic]
+			if (command.Experimental && !c.ExperimentalEnabled()) || !command.supported() {
+				continue
+			}
+			if !command.HelpOnly && !c.permitted(topic) {
+				continue
+			}
+			if !command.Hidden && command.hasHelp() {
+				topics = append(topics, topic)
+				labels[topic] = topic
+				if !command.HelpOnly {
+					labels[topic] += " (command)"
+				}
+				if len(labels[topic]) > width {
+					width = len(labels[topic])
+				}
+			}
+		}
+
+		layout := c.layout()
+		indent := strings.Repeat(" ", layout.Indent)
+		gap := strings.Repeat(" ", layout.ColumnGap)
+		prefixWidth := layout.Indent + width + layout.ColumnGap
+		wrapWidth := c.helpWidth() - prefixWidth
+		if wrapWidth < minWrapWidth {
+			wrapWidth = 0
+		}
+
+		for _, section := range c.categorize(topics, commands) {
+			if len(section.names) == 0 {
+				continue
+			}
+			title := section.title
+			if title == "" {
+				title = "Help Topics"
+			}
+			output += "\n" + title + "\n\n"
+			for _, topic := range section.names {
+				summary := strings.Join(wrapWords(commands[topic].topicSummary(), wrapWidth), "\n"+strings.Repeat(" ", prefixWidth))
+				if summary == "" {
+					output += indent + labels[topic] + "\n"
+					continue
 				}
+				output += fmt.Sprintf("%s%s%s%s\n", indent, PadRight(labels[topic], width), gap, summary)
 			}
 		}
 	case 1:
 		// Show help for a single topic
 		topic := args[0]
-		command, ok := c.Commands[topic]
+		if topic == "glossary" && c.hasGlossary() {
+			return GlossaryHelp(c, nil)
+		}
+		command, ok := c.lookup(topic)
 		if !ok {
-			err = fmt.Errorf("unknown help topic '%s'", topic)
+			err = c.Strings.errorf("unknown help topic '%s'", topic)
 			return
 		}
 
-		// Show the help topic
-		output += topic
-		// Show "Command Help" if the help topic is attached to a normal command
-		if !command.HelpOnly {
-			output += " Command"
-		}
-		output += " Help\n\n"
-		output += EnsureNewlines(command.Help)
+		output += helpPage(c, topic, command)
 	default:
-		// TODO tweak this for subcommand help
-		err = ErrTooManyArguments
+		if args[0] == "glossary" && c.hasGlossary() {
+			return GlossaryHelp(c, args[1:])
+		}
+		// Show help for a subcommand, such as "help release create"
+		path := strings.Join(args, " ")
+		command, ok := c.lookupPath(path)
+		if !ok {
+			err = c.Strings.errorf("unknown help topic '%s'", path)
+			return
+		}
+
+		output += helpPage(c, path, command)
 	}
 
 	return
 }
 
+// helpPage renders the help page for a single command or help topic.
+func helpPage(c *CLI, name string, command *Command) (output string) {
+	if command.Header != "" {
+		output += EnsureNewlines(command.Header) + "\n"
+	}
+
+	// Show the help topic
+	output += name
+	// Show "Command Help" if the help topic is attached to a normal command
+	if !command.HelpOnly {
+		output += " Command"
+	}
+	output += " Help\n\n"
+
+	if !command.HelpOnly && (command.Synopsis != "" || command.Flags != nil || len(command.Args) > 0 || len(command.Commands) > 0) {
+		output += "usage: " + commandUsage(c, name, command) + "\n\n"
+	}
+
+	width := c.helpWidth()
+	if command.Description != "" {
+		output += EnsureNewlines(wrap(command.Description, width))
+		if command.helpText() != "" {
+			output += "\n"
+		}
+	}
+	if help := command.helpText(); help != "" || (command.Description == "" && len(command.Commands) == 0) {
+		output += EnsureNewlines(wrap(help, width))
+	}
+
+	if len(command.Commands) > 0 && !command.HelpOnly {
+		if command.hasHelp() {
+			output += "\n"
+		}
+		output += "Commands\n\n" + subcommandList(c, name, command)
+	}
+
+	if flags := flagList(c, command); flags != "" && !command.HelpOnly {
+		output += "\nFlags\n\n" + flags
+	}
+
+	if len(command.RequiresEnv) > 0 && !command.HelpOnly {
+		output += "\nEnvironment\n\n" + envList(c, command)
+	}
+
+	if len(command.Examples) > 0 {
+		output += "\nExamples\n\n" + renderExamples(c, command.Examples, false)
+	}
+
+	if len(command.ExitCodes) > 0 && !command.HelpOnly {
+		output += "\nExit Status\n\n" + exitStatusList(c, command.ExitCodes)
+	}
+
+	if command.Footer != "" {
+		output += "\n" + EnsureNewlines(command.Footer)
+	}
+
+	return
+}
+
+// hasHelp reports whether there is any help text for the command.
+func (command *Command) hasHelp() bool {
+	return command.helpText() != "" || command.Description != ""
+}
+
+// topicSummary returns the one-line summary shown next to the command in the
+// list of help topics. This is Summary if it is set, otherwise the first
+// sentence of the Description or Help text.
+func (command *Command) topicSummary() string {
+	if command.Summary != "" {
+		return command.Summary
+	}
+	text := command.Description
+	if text == "" {
+		text = command.helpText()
+	}
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[:i]
+	}
+	if i := strings.Index(text, ". "); i >= 0 {
+		text = text[:i+1]
+	}
+	return strings.TrimSpace(text)
+}
+
+// helpSection is a group of commands listed under a heading.
+type helpSection struct {
+	title string
+	names []string
+}
+
+// categorize groups names by the Category of each command. The first section
+// is always present and holds uncategorized commands; it is followed by one
+// section for each category in lexical order.
+func (c *CLI) categorize(names []string, commands map[string]*Command) []helpSection {
+	sections := []helpSection{{}}
+	index := map[string]int{}
+	categories := []string{}
+	for _, name := range names {
+		category := commands[name].Category
+		if category == "" {
+			sections[0].names = append(sections[0].names, name)
+			continue
+		}
+		if _, ok := index[category]; !ok {
+			index[category] = 0
+			categories = append(categories, category)
+		}
+	}
+	c.sortNames(categories)
+	for i, category := range categories {
+		index[category] = i + 1
+		sections = append(sections, helpSection{title: category})
+	}
+	for _, name := range names {
+		if category := commands[name].Category; category != "" {
+			sections[index[category]].names = append(sections[index[category]].names, name)
+		}
+	}
+	return sections
+}
+
+// helpText returns the command's Help, or the help supplied by its Runner if
+// Help is empty.
+func (command *Command) helpText() string {
+	if command.Help != "" || command.Description != "" {
+		return command.Help
+	}
+	if provider, ok := command.Runner.(HelpProvider); ok {
+		return provider.Help()
+	}
+	return ""
+}
+
+// takeFlag handles --name for a feature of the framework that is enabled for
+// command, such as --force for a Cooldown. If the command defines its own flag
+// called name, args are returned unchanged and the flag is parsed normally;
+// see boolFlag. Otherwise --name is removed from args, not counting any
+// arguments that follow "--", and found reports whether it was present.
+func (command *Command) takeFlag(args []string, name string, enabled bool) (rest []string, found bool) {
+	if !enabled || (command.Flags != nil && command.Flags.Lookup(name) != nil) {
+		return args, false
+	}
+	rest = make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(rest, args[i:]...), found
+		}
+		if arg == "--"+name {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// boolFlag reports whether the command defines a flag called name and it was
+// set to true, after flags have been parsed.
+func (command *Command) boolFlag(name string) bool {
+	if command.Flags == nil {
+		return false
+	}
+	f := command.Flags.Lookup(name)
+	return f != nil && f.Value.String() == "true"
+}
+
+// flagRequested reports whether args includes --name, not counting any
+// arguments that follow "--".
+func flagRequested(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "--"+name {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseArgs separates the command string from any subsequent arguments and
 // returns both. It handles cases where command or arguments are not specified.
 func ParseArgs(input []string) (command string, args []string) {
@@ -342,6 +2007,40 @@ func ParseArgs(input []string) (command string, args []string) {
 	return
 }
 
+// Main runs the program and exits with the appropriate status. It is a
+// convenient replacement for calling Run and handling the error yourself:
+//
+//	func main() {
+//		app := &cli.CLI{...}
+//		app.Main()
+//	}
+//
+// Errors returned by Run are passed to HandleError.
+func (c *CLI) Main() {
+	err := c.Run()
+	if err == nil {
+		os.Exit(0)
+	}
+	os.Exit(c.HandleError(err))
+}
+
+// HandleError reports err to the user and returns the exit status the program
+// should exit with. It calls ErrorHandler if one is set. Otherwise the error
+// is formatted by RenderError and written to stderr, and the status is the
+// one returned by ExitCode.
+func (c *CLI) HandleError(err error) int {
+	if c.ErrorHandler != nil {
+		return c.ErrorHandler(err)
+	}
+	c.Printer.Err(RenderError(c, err))
+	return ExitCode(err)
+}
+
+// writeError writes err to w in the default format.
+func writeError(w io.Writer, err error) {
+	_, _ = io.WriteString(w, "error: "+err.Error()+"\n")
+}
+
 // ExitWithError writes the error to stderr and halts with exit code 1. It is
 // used in main() to handle errors returned from Run() or WrappedMain(), such as
 //
@@ -353,9 +2052,7 @@ func ParseArgs(input []string) (command string, args []string) {
 //		}
 //	}
 func ExitWithError(err error) {
-	_, _ = os.Stderr.WriteString("error: ")
-	_, _ = os.Stderr.WriteString(err.Error())
-	_, _ = os.Stderr.WriteString("\n")
+	writeError(os.Stderr, err)
 	os.Exit(1)
 }
 
@@ -5,6 +5,9 @@
 package cli_test
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -99,6 +102,74 @@ Did you like it? Make another and share it with your friends!
 
 }
 
+func TestHelpCategories(t *testing.T) {
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"status": {
+				Summary: "Show deployment status",
+			},
+			"deploy": {
+				Summary:  "Deploy a release",
+				Category: "Deployment",
+				Help:     "Deploy builds and publishes a release.\n\nUse --region to pick a region.",
+			},
+			"rollback": {
+				Summary:  "Revert to the previous release",
+				Category: "Deployment",
+			},
+			"config": {
+				Help:     "Settings are read from ship.yml. Run ship init to create one.",
+				HelpOnly: true,
+				Category: "Configuration",
+			},
+			"credentials": {
+				Summary:  "Storing API tokens",
+				Help:     "Tokens are stored in the system keychain.",
+				HelpOnly: true,
+			},
+		},
+	}
+
+	expectedOutput := `usage: ship [--version] [--help] <command> [<args>]
+
+Commands
+
+  ship status     Show deployment status
+  ship help       List help topics
+
+Deployment
+
+  ship deploy     Deploy a release
+  ship rollback   Revert to the previous release
+`
+	if output := cli.CommandHelp(app); output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	expectedOutput = `usage: ship help <topic>
+
+Help Topics
+
+  credentials        Storing API tokens
+
+Configuration
+
+  config             Settings are read from ship.yml.
+
+Deployment
+
+  deploy (command)   Deploy a release
+`
+	output, err := cli.Help(app, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+}
+
 func TestVersion(t *testing.T) {
 	app := &cli.CLI{
 		Name: "chocolate",
@@ -145,8 +216,8 @@ func TestHelp(t *testing.T) {
 
 Help Topics
 
-  candy (command)
-  cookies
+  candy (command)   There are many tasty varieties of candy.
+  cookies           We don't support cookies directly, but here's how you can make some:
 `
 
 		if output != expectedOutput {
@@ -187,6 +258,33 @@ We don't support cookies directly, but here's how you can make some:
 
 	})
 
+	t.Run("help with header and footer", func(tt *testing.T) {
+		app.Commands["sprinkles"] = &cli.Command{
+			Header: "\nWARNING: sprinkles are an experimental topping\n",
+			Help:   "Add sprinkles to any dessert.",
+			Footer: "See https://example.com/sprinkles for more toppings",
+		}
+		defer delete(app.Commands, "sprinkles")
+
+		output, err := cli.Help(app, []string{"sprinkles"})
+		if err != nil {
+			tt.Fatal(err)
+		}
+
+		expectedOutput := `WARNING: sprinkles are an experimental topping
+
+sprinkles Command Help
+
+Add sprinkles to any dessert.
+
+See https://example.com/sprinkles for more toppings
+`
+
+		if output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+
 	t.Run("missing help topic", func(tt *testing.T) {
 		_, err := cli.Help(app, []string{"cake"})
 		expectedError := "unknown help topic 'cake'"
@@ -314,6 +412,7 @@ func redirectIO() (cleanup func(), stdout *os.File) {
 
 func TestCLI_Run(t *testing.T) {
 	app := &cli.CLI{
+		Name: "testapp",
 		Commands: map[string]*cli.Command{
 			"reverse": {
 				Summary: "reverse the arguments",
@@ -341,14 +440,16 @@ func TestCLI_Run(t *testing.T) {
 		cleanup, _ := redirectIO()
 		defer cleanup()
 
+		// Run takes the name from os.Args when it is not set
+		unnamed := &cli.CLI{Commands: app.Commands}
 		expectedAppName := "testapp"
 		os.Args = []string{expectedAppName}
-		if err := app.Run(); err != nil {
+		if err := unnamed.Run(); err != nil {
 			t.Fatal(err)
 		}
 
-		if app.Name != expectedAppName {
-			t.Errorf("Expected %q, found %q", expectedAppName, app.Name)
+		if unnamed.Name != expectedAppName {
+			t.Errorf("Expected %q, found %q", expectedAppName, unnamed.Name)
 		}
 	})
 
@@ -356,8 +457,7 @@ func TestCLI_Run(t *testing.T) {
 		cleanup, stdout := redirectIO()
 		defer cleanup()
 
-		os.Args = []string{"testapp"}
-		if err := app.Run(); err != nil {
+		if err := app.RunArgs(nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -378,8 +478,7 @@ func TestCLI_Run(t *testing.T) {
 		cleanup, stdout := redirectIO()
 		defer cleanup()
 
-		os.Args = []string{"testapp", "--help"}
-		if err := app.Run(); err != nil {
+		if err := app.RunArgs([]string{"--help"}); err != nil {
 			t.Fatal(err)
 		}
 
@@ -400,10 +499,9 @@ func TestCLI_Run(t *testing.T) {
 		cleanup, stdout := redirectIO()
 		defer cleanup()
 
-		os.Args = []string{"testapp", "reverse", "testarg1", "testarg2", "testarg3"}
 		expectedOutput := "testarg3 testarg2 testarg1\n"
 
-		if err := app.Run(); err != nil {
+		if err := app.RunArgs([]string{"reverse", "testarg1", "testarg2", "testarg3"}); err != nil {
 			t.Fatal(err)
 		}
 
@@ -422,10 +520,9 @@ func TestCLI_Run(t *testing.T) {
 		cleanup, stdout := redirectIO()
 		defer cleanup()
 
-		os.Args = []string{"testapp", "--version"}
 		expectedOutput := "testapp version undefined\n"
 
-		if err := app.Run(); err != nil {
+		if err := app.RunArgs([]string{"--version"}); err != nil {
 			t.Fatal(err)
 		}
 
@@ -444,13 +541,12 @@ func TestCLI_Run(t *testing.T) {
 		cleanup, stdout := redirectIO()
 		defer cleanup()
 
-		os.Args = []string{"testapp", "help"}
 		expectedOutput, err := cli.Help(app, []string{})
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		if err := app.Run(); err != nil {
+		if err := app.RunArgs([]string{"help"}); err != nil {
 			t.Fatal(err)
 		}
 
@@ -466,9 +562,7 @@ func TestCLI_Run(t *testing.T) {
 	})
 
 	t.Run("invalid command", func(t *testing.T) {
-		os.Args = []string{"testapp", "cookies"}
-
-		err := app.Run()
+		err := app.RunArgs([]string{"cookies"})
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -480,10 +574,15 @@ func TestCLI_Run(t *testing.T) {
 		}
 	})
 
-	t.Run("command not implemented", func(t *testing.T) {
-		os.Args = []string{"testapp", "todo"}
+	t.Run("misspelled command", func(t *testing.T) {
+		expectedOutput := "'eror' is not a testapp command, did you mean error?"
+		if err := app.RunArgs([]string{"eror"}); err == nil || err.Error() != expectedOutput {
+			t.Errorf("Expected %q, found %v", expectedOutput, err)
+		}
+	})
 
-		err := app.Run()
+	t.Run("command not implemented", func(t *testing.T) {
+		err := app.RunArgs([]string{"todo"})
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -496,9 +595,7 @@ func TestCLI_Run(t *testing.T) {
 	})
 
 	t.Run("command error", func(t *testing.T) {
-		os.Args = []string{"testapp", "error"}
-
-		err := app.Run()
+		err := app.RunArgs([]string{"error"})
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -513,7 +610,7 @@ func TestCLI_Run(t *testing.T) {
 	t.Run("invalid program name", func(t *testing.T) {
 		app.Name = "has a space"
 
-		err := app.Run()
+		err := app.RunArgs([]string{"error"})
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -526,3 +623,710 @@ func TestCLI_Run(t *testing.T) {
 	})
 
 }
+
+func TestExperimental(t *testing.T) {
+	app := &cli.CLI{
+		Name: "labs",
+		Commands: map[string]*cli.Command{
+			"stable": {
+				Summary: "works every time",
+				Run:     func(args []string) error { return nil },
+			},
+			"preview": {
+				Summary:      "might work",
+				Help:         "This command is still under construction.",
+				Experimental: true,
+				Run:          func(args []string) error { return nil },
+			},
+		},
+	}
+
+	t.Run("hidden from command list", func(t *testing.T) {
+		if strings.Contains(cli.CommandHelp(app), "preview") {
+			t.Errorf("Expected experimental command to be hidden:\n%s", cli.CommandHelp(app))
+		}
+
+		output, err := cli.Help(app, []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(output, "preview") {
+			t.Errorf("Expected experimental help topic to be hidden:\n%s", output)
+		}
+	})
+
+	t.Run("refuses to run", func(t *testing.T) {
+		err := app.RunArgs([]string{"preview"})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		expectedOutput := "'preview' is an experimental command. Set LABS_EXPERIMENTAL=1 or pass --enable-experimental to use it."
+		if err.Error() != expectedOutput {
+			t.Errorf("Expected %q, found %q", expectedOutput, err.Error())
+		}
+	})
+
+	t.Run("environment variable", func(t *testing.T) {
+		os.Setenv("LABS_EXPERIMENTAL", "1")
+		defer os.Unsetenv("LABS_EXPERIMENTAL")
+
+		if err := app.RunArgs([]string{"preview"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(cli.CommandHelp(app), "labs preview") {
+			t.Errorf("Expected experimental command to be listed:\n%s", cli.CommandHelp(app))
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		if err := app.RunArgs([]string{"--enable-experimental", "preview"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("flag only applies to one run", func(t *testing.T) {
+		if err := app.RunArgs([]string{"preview"}); err == nil {
+			t.Error("Expected the experimental command to be refused without the flag")
+		}
+	})
+}
+
+func TestSubcommands(t *testing.T) {
+	var created []string
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: stderr},
+		Commands: map[string]*cli.Command{
+			"release": {
+				Summary:     "Manage releases",
+				Description: "Releases are immutable snapshots of your application.",
+				Commands: map[string]*cli.Command{
+					"create": {
+						Summary:     "Create a release",
+						Description: "Creates a release from the current build.",
+						Run: func(args []string) error {
+							created = args
+							return nil
+						},
+					},
+					"list": {
+						Summary: "List releases",
+						Run:     func(args []string) error { return nil },
+					},
+					"promote": {
+						Summary:  "Promote a release to production",
+						Category: "Lifecycle",
+						Run:      func(args []string) error { return nil },
+					},
+					"purge": {
+						Summary: "Delete everything",
+						Hidden:  true,
+						Run:     func(args []string) error { return nil },
+					},
+				},
+			},
+		},
+	}
+
+	run := func(args ...string) error {
+		stdout.Reset()
+		stderr.Reset()
+		return app.RunArgs(args)
+	}
+
+	t.Run("invoke", func(tt *testing.T) {
+		if err := run("release", "create", "1.2.0"); err != nil {
+			tt.Fatal(err)
+		}
+		if len(created) != 1 || created[0] != "1.2.0" {
+			tt.Errorf("Expected [1.2.0], found %q", created)
+		}
+	})
+
+	expectedList := `Commands
+
+  ship release create    Create a release
+  ship release list      List releases
+
+Lifecycle
+
+  ship release promote   Promote a release to production
+`
+
+	t.Run("bare", func(tt *testing.T) {
+		if err := run("release"); err != nil {
+			tt.Fatal(err)
+		}
+		expectedOutput := `release Command Help
+
+usage: ship release <command> [<args>]
+
+Releases are immutable snapshots of your application.
+
+` + expectedList
+		if stdout.String() != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, stdout.String())
+		}
+	})
+
+	t.Run("unknown", func(tt *testing.T) {
+		err := run("release", "craete")
+		expected := "'craete' is not a ship release command, did you mean create?"
+		if err == nil || err.Error() != expected {
+			tt.Errorf("Expected %q, found %v", expected, err)
+		}
+		expectedOutput := "usage: ship release <command> [<args>]\n\n" + expectedList + "\n"
+		if stderr.String() != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, stderr.String())
+		}
+
+		err = run("release", "xyzzy")
+		expected = "'xyzzy' is not a ship release command. See 'ship release --help'."
+		if err == nil || err.Error() != expected {
+			tt.Errorf("Expected %q, found %v", expected, err)
+		}
+	})
+
+	t.Run("completion", func(tt *testing.T) {
+		result := cli.Complete(app, []string{"release", "p"})
+		if strings.Join(result.Candidates, ",") != "promote" {
+			tt.Errorf("Expected [promote], found %q", result.Candidates)
+		}
+	})
+
+	t.Run("help path", func(tt *testing.T) {
+		if err := run("help", "release", "create"); err != nil {
+			tt.Fatal(err)
+		}
+		expectedOutput := "release create Command Help\n\nCreates a release from the current build.\n"
+		if stdout.String() != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, stdout.String())
+		}
+
+		err := run("help", "release", "rollback")
+		if err == nil || err.Error() != "unknown help topic 'release rollback'" {
+			tt.Errorf("Expected %q, found %v", "unknown help topic 'release rollback'", err)
+		}
+
+		result := cli.Complete(app, []string{"help", "release", "l"})
+		if strings.Join(result.Candidates, ",") != "list" {
+			tt.Errorf("Expected [list], found %q", result.Candidates)
+		}
+	})
+
+	t.Run("usage", func(tt *testing.T) {
+		if usage := cli.CommandUsage(app, "release create"); usage != "ship release create" {
+			tt.Errorf("Expected %q, found %q", "ship release create", usage)
+		}
+	})
+}
+
+func TestProgramName(t *testing.T) {
+	ogArgs := os.Args
+	defer func() { os.Args = ogArgs }()
+
+	os.Args = []string{`C:\Tools\ship.EXE`}
+	app := &cli.CLI{Printer: &cli.Printer{Stdout: &bytes.Buffer{}}}
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// filepath.Base only splits on backslashes on Windows
+	if app.Name != "ship" && app.Name != `C:\Tools\ship` {
+		t.Errorf("Expected %q, found %q", "ship", app.Name)
+	}
+
+	type TestCase struct {
+		Name     string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{"ship", ""},
+		{"ship.v2", ""},
+		{"my ship", `program name ("my ship") must not contain spaces, try renaming the binary`},
+		{"ship.", `program name ("ship.") must not end with a dot, try renaming the binary`},
+		{"nul", `program name ("nul") is reserved on Windows, try renaming the binary`},
+		{"Com1.tool", `program name ("Com1.tool") is reserved on Windows, try renaming the binary`},
+		{"console", ""},
+	}
+
+	for _, c := range cases {
+		app := &cli.CLI{Name: c.Name, Printer: &cli.Printer{Stdout: &bytes.Buffer{}}}
+		err := app.RunArgs(nil)
+		if c.Expected == "" && err != nil {
+			t.Errorf("Expected %q to be valid, found %v", c.Name, err)
+		}
+		if c.Expected != "" && (err == nil || err.Error() != c.Expected) {
+			t.Errorf("Expected %q, found %v", c.Expected, err)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	ogArgs := os.Args
+	defer func() { os.Args = ogArgs }()
+
+	var received []string
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	flags.Bool("draft", false, "create a draft release")
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout},
+		Links:   map[string]string{"rc": "release create"},
+		Commands: map[string]*cli.Command{
+			"release": {
+				Commands: map[string]*cli.Command{
+					"create": {
+						Flags: flags,
+						Args:  []cli.ArgSpec{{Name: "version", Required: true}},
+						Run: func(args []string) error {
+							received = args
+							return nil
+						},
+					},
+				},
+			},
+		},
+	}
+
+	os.Args = []string{"/usr/local/bin/rc", "v1.2"}
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0] != "v1.2" {
+		t.Errorf("Expected %q, found %q", []string{"v1.2"}, received)
+	}
+
+	os.Args = []string{"rc", "--help"}
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "usage: rc [flags] <version>\n") {
+		t.Errorf("Expected the usage to start with the link name, found\n%s", stdout.String())
+	}
+}
+
+func TestCommandNotFound(t *testing.T) {
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"deploy": {Run: func(args []string) error { return nil }},
+		},
+	}
+
+	err := app.RunArgs([]string{"launch", "now"})
+	expected := "'launch' is not a ship command. See 'ship --help'."
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+
+	var name string
+	var args []string
+	app.CommandNotFound = func(n string, a []string) error {
+		name, args = n, a
+		if n == "launch" {
+			return nil
+		}
+		return fmt.Errorf("no such thing as %s", n)
+	}
+
+	if err := app.RunArgs([]string{"launch", "now"}); err != nil {
+		t.Fatal(err)
+	}
+	if name != "launch" || len(args) != 1 || args[0] != "now" {
+		t.Errorf("Expected launch [now], found %s %q", name, args)
+	}
+
+	if err := app.RunArgs([]string{"fly"}); err == nil || err.Error() != "no such thing as fly" {
+		t.Errorf("Expected %q, found %v", "no such thing as fly", err)
+	}
+
+	// Defined commands never reach the handler
+	name = ""
+	if err := app.RunArgs([]string{"deploy"}); err != nil || name != "" {
+		t.Errorf("Expected deploy to run normally, found %v %q", err, name)
+	}
+}
+
+func TestHandleError(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: stderr},
+	}
+
+	if status := app.HandleError(errors.New("no route to host")); status != 1 {
+		t.Errorf("Expected status 1, found %d", status)
+	}
+	if stderr.String() != "error: no route to host\n" {
+		t.Errorf("Expected %q, found %q", "error: no route to host\n", stderr.String())
+	}
+
+	var handled error
+	app.ErrorHandler = func(err error) int {
+		handled = err
+		return 69
+	}
+	stderr.Reset()
+	err := errors.New("service unavailable")
+	if status := app.HandleError(err); status != 69 {
+		t.Errorf("Expected status 69, found %d", status)
+	}
+	if handled != err || stderr.Len() != 0 {
+		t.Errorf("Expected the handler to receive the error, found %v and output %q", handled, stderr.String())
+	}
+}
+
+func TestOutputWidth(t *testing.T) {
+	if value, ok := os.LookupEnv("COLUMNS"); ok {
+		defer os.Setenv("COLUMNS", value)
+	} else {
+		defer os.Unsetenv("COLUMNS")
+	}
+	os.Unsetenv("COLUMNS")
+
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "garden",
+		Printer: &cli.Printer{Stdout: stdout},
+		Commands: map[string]*cli.Command{
+			"plant": {
+				Summary: "put seeds in the ground and water them regularly until they sprout",
+				Help:    "Plants grow best in full sun with well-drained soil and regular watering.\n\n    garden plant --seeds tomato --rows 4 --spacing 18in",
+				Run:     func(args []string) error { return nil },
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"--width", "50", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The width only applies to that run, and isn't exported to the process
+	if app.Printer.Width != 0 || os.Getenv("COLUMNS") != "" {
+		t.Errorf("Expected --width to only apply to one run, found Width %d and COLUMNS %q", app.Printer.Width, os.Getenv("COLUMNS"))
+	}
+
+	expectedOutput := `usage: garden [--version] [--help] <command> [<args>]
+
+Commands
+
+  garden plant   put seeds in the ground and water
+                 them regularly until they sprout
+  garden help    List help topics
+`
+	if output := stdout.String(); output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+	stdout.Reset()
+
+	// Indented lines are preformatted and should not be wrapped
+	expectedOutput = `plant Command Help
+
+Plants grow best in full sun with well-drained
+soil and regular watering.
+
+    garden plant --seeds tomato --rows 4 --spacing 18in
+`
+	if err := app.RunArgs([]string{"--width=50", "help", "plant"}); err != nil {
+		t.Fatal(err)
+	}
+	if output := stdout.String(); output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	err := app.RunArgs([]string{"--width=wide", "plant"})
+	expectedError := `invalid value "wide" for flag --width: expected a positive number`
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Expected %q, found %v", expectedError, err)
+	}
+}
+
+func TestHelpLayout(t *testing.T) {
+	app := &cli.CLI{
+		Name: "garden",
+		Layout: cli.HelpLayout{
+			MaxWidth:  44,
+			Indent:    4,
+			ColumnGap: 1,
+		},
+		Commands: map[string]*cli.Command{
+			"plant": {
+				Summary: "put seeds in the ground and water them regularly",
+				Help:    "Plants grow best in full sun with well-drained soil.",
+			},
+			"harvest": {
+				Summary: "collect ripe vegetables",
+			},
+		},
+	}
+
+	expectedOutput := `usage: garden [--version] [--help] <command> [<args>]
+
+Commands
+
+    garden harvest collect ripe vegetables
+    garden plant   put seeds in the ground
+                   and water them regularly
+    garden help    List help topics
+`
+	if output := cli.CommandHelp(app); output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	expectedOutput = `usage: garden help <topic>
+
+Help Topics
+
+    plant (command) put seeds in the ground
+                    and water them regularly
+`
+	output, err := cli.Help(app, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	expectedOutput = `plant Command Help
+
+Plants grow best in full sun with
+well-drained soil.
+`
+	output, err = cli.Help(app, []string{"plant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	flags := flag.NewFlagSet("copy", flag.ContinueOnError)
+	flags.Bool("recursive", false, "copy directories recursively")
+
+	app := &cli.CLI{
+		Name: "files",
+		Commands: map[string]*cli.Command{
+			"copy": {
+				Flags: flags,
+				Args: []cli.ArgSpec{
+					{Name: "source", Required: true},
+					{Name: "destination"},
+				},
+				Help: "Copy files from one place to another.",
+			},
+			"exec": {
+				Synopsis: "files exec [-- <cmd> [args...]]",
+				Help:     "Run a command with files mounted.",
+			},
+			"list": {},
+		},
+	}
+
+	type TestCase struct {
+		Command  string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{Command: "copy", Expected: "files copy [flags] <source> [<destination>]"},
+		{Command: "exec", Expected: "files exec [-- <cmd> [args...]]"},
+		{Command: "list", Expected: "files list"},
+		{Command: "missing", Expected: ""},
+	}
+
+	for _, testCase := range cases {
+		actual := cli.CommandUsage(app, testCase.Command)
+		if actual != testCase.Expected {
+			t.Errorf("Expected %q, found %q", testCase.Expected, actual)
+		}
+	}
+
+	expectedOutput := `exec Command Help
+
+usage: files exec [-- <cmd> [args...]]
+
+Run a command with files mounted.
+`
+	output, err := cli.Help(app, []string{"exec"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+}
+
+func TestCommandDescription(t *testing.T) {
+	app := &cli.CLI{
+		Name: "garden",
+		Commands: map[string]*cli.Command{
+			"water": {
+				Summary:     "water your plants",
+				Synopsis:    "garden water [<bed>...]",
+				Description: "Water the specified garden beds, or all of them if none are specified.",
+				Run: func(args []string) error {
+					return errors.New("should not run")
+				},
+			},
+		},
+	}
+
+	expectedOutput := `water Command Help
+
+usage: garden water [<bed>...]
+
+Water the specified garden beds, or all of them if none are specified.
+`
+
+	output, err := cli.Help(app, []string{"water"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	topics, err := cli.Help(app, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(topics, "water (command)") {
+		t.Errorf("Expected command with a description to be listed as a help topic:\n%s", topics)
+	}
+
+	cleanup, stdout := redirectIO()
+	defer cleanup()
+
+	if err := app.RunArgs([]string{"water", "tomatoes", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cleanup() // Cleanup to flush stdout/err to disk
+	data, err := ioutil.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, string(data))
+	}
+}
+
+func TestCommandVersion(t *testing.T) {
+	app := &cli.CLI{
+		Name:    "toolbox",
+		Version: "1.2.0",
+		Commands: map[string]*cli.Command{
+			"db": {
+				Version: "11.4",
+				Run: func(args []string) error {
+					return errors.New("should not run")
+				},
+			},
+			"lint": {
+				Run: func(args []string) error {
+					fmt.Println(strings.Join(args, " "))
+					return nil
+				},
+			},
+		},
+	}
+
+	type TestCase struct {
+		Args     []string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{
+			Args:     []string{"db", "--version"},
+			Expected: "toolbox db version 11.4\n",
+		},
+		{
+			// Commands without a version may use --version themselves
+			Args:     []string{"lint", "--version"},
+			Expected: "--version\n",
+		},
+		{
+			Args:     []string{"--version", "--json"},
+			Expected: `{"name":"toolbox","version":"1.2.0","commands":{"db":"11.4"}}` + "\n",
+		},
+	}
+
+	for _, testCase := range cases {
+		cleanup, stdout := redirectIO()
+
+		if err := app.RunArgs(testCase.Args); err != nil {
+			t.Fatal(err)
+		}
+
+		cleanup() // Cleanup to flush stdout/err to disk
+		output, err := ioutil.ReadFile(stdout.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(output) != testCase.Expected {
+			t.Errorf("Expected %q, found %q with input %q", testCase.Expected, string(output), testCase.Args)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	var checked []string
+	run := func(args []string) error { return nil }
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"deploy": {Summary: "deploy the app", Run: run},
+			"release": {
+				Summary: "manage releases",
+				Commands: map[string]*cli.Command{
+					"create": {Summary: "create a release", Run: run},
+					"delete": {Summary: "delete a release", Run: run},
+				},
+			},
+			"billing": {Summary: "manage billing", Run: run},
+		},
+		Authorize: func(command string) error {
+			checked = append(checked, command)
+			if command == "billing" || command == "release delete" {
+				return errors.New("you need the admin role to run '" + command + "'")
+			}
+			return nil
+		},
+	}
+
+	if err := app.RunArgs([]string{"release", "create"}); err != nil {
+		t.Error(err)
+	}
+	expected := []string{"release", "release create"}
+	if strings.Join(checked, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %q, found %q", expected, checked)
+	}
+
+	expectedErr := "you need the admin role to run 'release delete'"
+	if err := app.RunArgs([]string{"release", "delete"}); err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %q, found %v", expectedErr, err)
+	}
+
+	if output := cli.CommandHelp(app); !strings.Contains(output, "ship billing") {
+		t.Errorf("Expected denied commands to be listed by default, found %q", output)
+	}
+
+	app.HideUnauthorized = true
+	if output := cli.CommandHelp(app); strings.Contains(output, "ship billing") || !strings.Contains(output, "ship deploy") {
+		t.Errorf("Expected only permitted commands to be listed, found %q", output)
+	}
+	if result := cli.Complete(app, []string{"release", ""}); strings.Join(result.Candidates, ",") != "create" {
+		t.Errorf("Expected only permitted subcommands to be completed, found %q", result.Candidates)
+	}
+}
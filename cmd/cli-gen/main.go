@@ -0,0 +1,431 @@
+// Command cli-gen generates a cli.Command map from functions annotated with
+// doc-comment directives, so command definitions can live next to their
+// implementations. It is designed to be run by go generate:
+//
+//	//go:generate cli-gen
+//
+// cli-gen scans the Go files in the current directory (or -dir) for functions
+// whose doc comments contain a //cli:command directive:
+//
+//	// Deploy ships the application to the selected environment.
+//	//
+//	//cli:command deploy
+//	//cli:summary Deploy the application
+//	//cli:flag env string staging Environment to deploy to
+//	//cli:flag force bool false Skip confirmation
+//	//cli:arg app required
+//	func Deploy(ctx *cli.Context) error {
+//		...
+//	}
+//
+// The supported directives are:
+//
+//	//cli:command NAME                 marks the function as a command
+//	//cli:summary TEXT                 one-line summary for command help
+//	//cli:flag NAME TYPE DEFAULT USAGE defines a flag (DEFAULT may be quoted)
+//	//cli:required NAME                marks a flag as required
+//	//cli:arg NAME [required]          defines a positional argument
+//	//cli:hidden                       hides the command from command help
+//
+// Flag types are string, bool, int, int64, uint, uint64, float64, and duration.
+// The rest of the doc comment becomes the command's Description.
+//
+// Functions must have the signature func(*cli.Context) error, which is used for
+// RunContext, or func([]string) error, which is used for Run. The generated
+// file defines a function (named by -func) returning the Command map.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const directivePrefix = "//cli:"
+
+// generator holds the options for a single run of cli-gen.
+type generator struct {
+	Dir      string
+	Output   string
+	FuncName string
+}
+
+// command is a function annotated with a //cli:command directive.
+type command struct {
+	Name        string
+	Func        string
+	Context     bool
+	Summary     string
+	Description string
+	Hidden      bool
+	Flags       []flagDef
+	Required    []string
+	Args        []argDef
+	Pos         token.Position
+}
+
+type flagDef struct {
+	Name    string
+	Type    string
+	Default string
+	Usage   string
+}
+
+type argDef struct {
+	Name     string
+	Required bool
+}
+
+// flagTypes maps directive types to FlagSet methods.
+var flagTypes = map[string]string{
+	"string":   "String",
+	"bool":     "Bool",
+	"int":      "Int",
+	"int64":    "Int64",
+	"uint":     "Uint",
+	"uint64":   "Uint64",
+	"float64":  "Float64",
+	"duration": "Duration",
+}
+
+func main() {
+	g := &generator{}
+	flag.StringVar(&g.Dir, "dir", ".", "Directory containing the annotated package")
+	flag.StringVar(&g.Output, "output", "cli_gen.go", "Name of the generated file, relative to -dir")
+	flag.StringVar(&g.FuncName, "func", "generatedCommands", "Name of the generated function")
+	flag.Parse()
+
+	source, err := g.Generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cli-gen: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(g.Dir, g.Output), source, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "cli-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// Generate parses the package in g.Dir and returns the formatted source of the
+// generated file.
+func (g *generator) Generate() ([]byte, error) {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, g.Dir, func(info os.FileInfo) bool {
+		return info.Name() != g.Output && !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(packages) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", g.Dir, len(packages))
+	}
+
+	var pkgName string
+	var commands []*command
+	for name, pkg := range packages {
+		pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Doc == nil || fn.Recv != nil {
+					continue
+				}
+				cmd, err := parseCommand(fset, fn)
+				if err != nil {
+					return nil, err
+				}
+				if cmd != nil {
+					commands = append(commands, cmd)
+				}
+			}
+		}
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	for i := 1; i < len(commands); i++ {
+		if commands[i].Name == commands[i-1].Name {
+			return nil, fmt.Errorf("%s: command %q is already defined at %s", commands[i].Pos, commands[i].Name, commands[i-1].Pos)
+		}
+	}
+
+	return render(pkgName, g.FuncName, commands)
+}
+
+// parseCommand reads the directives from a function's doc comment, returning
+// nil if the function is not annotated.
+func parseCommand(fset *token.FileSet, fn *ast.FuncDecl) (*command, error) {
+	cmd := &command{Func: fn.Name.Name, Pos: fset.Position(fn.Pos())}
+	var description []string
+
+	for _, comment := range fn.Doc.List {
+		if !strings.HasPrefix(comment.Text, directivePrefix) {
+			text := strings.TrimPrefix(comment.Text, "//")
+			description = append(description, strings.TrimPrefix(text, " "))
+			continue
+		}
+
+		directive := strings.TrimPrefix(comment.Text, directivePrefix)
+		keyword, rest := splitToken(directive)
+		var err error
+		switch keyword {
+		case "command":
+			cmd.Name = rest
+		case "summary":
+			cmd.Summary = rest
+		case "hidden":
+			cmd.Hidden = true
+		case "required":
+			cmd.Required = append(cmd.Required, rest)
+		case "arg":
+			name, option := splitToken(rest)
+			cmd.Args = append(cmd.Args, argDef{Name: name, Required: option == "required"})
+		case "flag":
+			err = cmd.parseFlag(rest)
+		default:
+			err = fmt.Errorf("unknown directive %q", keyword)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fset.Position(comment.Pos()), err)
+		}
+	}
+
+	if cmd.Name == "" {
+		return nil, nil
+	}
+
+	switch signature(fn) {
+	case "context":
+		cmd.Context = true
+	case "args":
+	default:
+		return nil, fmt.Errorf("%s: %s must have the signature func(*cli.Context) error or func([]string) error", cmd.Pos, fn.Name.Name)
+	}
+
+	for _, name := range cmd.Required {
+		if !cmd.hasFlag(name) {
+			return nil, fmt.Errorf("%s: required flag --%s is not defined", cmd.Pos, name)
+		}
+	}
+
+	cmd.Description = strings.TrimSpace(strings.Join(description, "\n"))
+	return cmd, nil
+}
+
+// parseFlag parses the arguments to a //cli:flag directive.
+func (cmd *command) parseFlag(text string) error {
+	name, text := splitToken(text)
+	typ, text := splitToken(text)
+	if name == "" || typ == "" {
+		return fmt.Errorf("flag requires a name and type")
+	}
+	if _, ok := flagTypes[typ]; !ok {
+		return fmt.Errorf("flag --%s has unsupported type %q", name, typ)
+	}
+
+	var def string
+	if strings.HasPrefix(text, `"`) {
+		quoted, err := strconv.QuotedPrefix(text)
+		if err != nil {
+			return fmt.Errorf("invalid default for flag --%s: %s", name, err)
+		}
+		def, _ = strconv.Unquote(quoted)
+		text = strings.TrimSpace(text[len(quoted):])
+	} else {
+		def, text = splitToken(text)
+	}
+
+	literal, err := defaultLiteral(typ, def)
+	if err != nil {
+		return fmt.Errorf("invalid default %q for flag --%s: %s", def, name, err)
+	}
+
+	cmd.Flags = append(cmd.Flags, flagDef{Name: name, Type: typ, Default: literal, Usage: text})
+	return nil
+}
+
+func (cmd *command) hasFlag(name string) bool {
+	for _, f := range cmd.Flags {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultLiteral validates a default value and returns it as a Go literal.
+func defaultLiteral(typ, value string) (string, error) {
+	var err error
+	switch typ {
+	case "string":
+		return strconv.Quote(value), nil
+	case "bool":
+		if value == "" {
+			return "false", nil
+		}
+		_, err = strconv.ParseBool(value)
+	case "int", "int64":
+		if value == "" {
+			return "0", nil
+		}
+		_, err = strconv.ParseInt(value, 0, 64)
+	case "uint", "uint64":
+		if value == "" {
+			return "0", nil
+		}
+		_, err = strconv.ParseUint(value, 0, 64)
+	case "float64":
+		if value == "" {
+			return "0", nil
+		}
+		_, err = strconv.ParseFloat(value, 64)
+	case "duration":
+		if value == "" {
+			return "0", nil
+		}
+		var d time.Duration
+		d, err = time.ParseDuration(value)
+		return fmt.Sprintf("%d", int64(d)), err
+	}
+	return value, err
+}
+
+// signature classifies a function as a RunContext ("context") or Run ("args")
+// implementation, or returns "" if it is neither.
+func signature(fn *ast.FuncDecl) string {
+	params, results := fn.Type.Params.List, fn.Type.Results
+	if len(params) != 1 || len(params[0].Names) > 1 || results == nil || len(results.List) != 1 {
+		return ""
+	}
+	if ident, ok := results.List[0].Type.(*ast.Ident); !ok || ident.Name != "error" {
+		return ""
+	}
+
+	switch t := params[0].Type.(type) {
+	case *ast.StarExpr:
+		if sel, ok := t.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "Context" {
+			return "context"
+		}
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && ident.Name == "string" {
+			return "args"
+		}
+	}
+	return ""
+}
+
+// render produces the formatted source for the generated file.
+func render(pkgName, funcName string, commands []*command) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by cli-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(buf, "import (\n")
+	if hasFlags(commands) {
+		fmt.Fprintf(buf, "\t\"flag\"\n")
+		if hasDuration(commands) {
+			fmt.Fprintf(buf, "\t\"time\"\n")
+		}
+		fmt.Fprintf(buf, "\n")
+	}
+	fmt.Fprintf(buf, "\t\"github.com/cbednarski/cli\"\n)\n\n")
+
+	fmt.Fprintf(buf, "// %s returns the commands defined by cli: directives in this package.\n", funcName)
+	fmt.Fprintf(buf, "func %s() map[string]*cli.Command {\n", funcName)
+	fmt.Fprintf(buf, "commands := map[string]*cli.Command{}\n\n")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(buf, "commands[%q] = &cli.Command{\n", cmd.Name)
+		if cmd.Context {
+			fmt.Fprintf(buf, "RunContext: %s,\n", cmd.Func)
+		} else {
+			fmt.Fprintf(buf, "Run: %s,\n", cmd.Func)
+		}
+		if cmd.Summary != "" {
+			fmt.Fprintf(buf, "Summary: %q,\n", cmd.Summary)
+		}
+		if cmd.Description != "" {
+			fmt.Fprintf(buf, "Description: %q,\n", cmd.Description)
+		}
+		if cmd.Hidden {
+			fmt.Fprintf(buf, "Hidden: true,\n")
+		}
+		if len(cmd.Required) > 0 {
+			fmt.Fprintf(buf, "RequiredFlags: %#v,\n", cmd.Required)
+		}
+		if len(cmd.Args) > 0 {
+			fmt.Fprintf(buf, "Args: []cli.ArgSpec{\n")
+			for _, arg := range cmd.Args {
+				if arg.Required {
+					fmt.Fprintf(buf, "{Name: %q, Required: true},\n", arg.Name)
+				} else {
+					fmt.Fprintf(buf, "{Name: %q},\n", arg.Name)
+				}
+			}
+			fmt.Fprintf(buf, "},\n")
+		}
+		fmt.Fprintf(buf, "}\n")
+
+		if len(cmd.Flags) > 0 {
+			fmt.Fprintf(buf, "commands[%q].Flags = flag.NewFlagSet(%q, flag.ContinueOnError)\n", cmd.Name, cmd.Name)
+			for _, f := range cmd.Flags {
+				def := f.Default
+				if f.Type == "duration" {
+					def = fmt.Sprintf("time.Duration(%s)", def)
+				}
+				fmt.Fprintf(buf, "commands[%q].Flags.%s(%q, %s, %q)\n", cmd.Name, flagTypes[f.Type], f.Name, def, f.Usage)
+			}
+		}
+		fmt.Fprintf(buf, "\n")
+	}
+
+	fmt.Fprintf(buf, "return commands\n}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s", err)
+	}
+	return source, nil
+}
+
+func hasFlags(commands []*command) bool {
+	for _, cmd := range commands {
+		if len(cmd.Flags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDuration(commands []*command) bool {
+	for _, cmd := range commands {
+		for _, f := range cmd.Flags {
+			if f.Type == "duration" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitToken returns the first space-separated token in text and the
+// remainder with surrounding whitespace removed.
+func splitToken(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if idx := strings.IndexAny(text, " \t"); idx >= 0 {
+		return text[:idx], strings.TrimSpace(text[idx:])
+	}
+	return text, ""
+}
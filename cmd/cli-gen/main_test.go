@@ -0,0 +1,138 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const annotatedSource = `package app
+
+import "github.com/cbednarski/cli"
+
+// Deploy ships the application to the selected environment.
+//
+//cli:command deploy
+//cli:summary Deploy the application
+//cli:flag env string "us east" Environment to deploy to
+//cli:flag force bool false Skip confirmation
+//cli:flag timeout duration 1m Time to wait
+//cli:required env
+//cli:arg app required
+//cli:arg version
+func Deploy(ctx *cli.Context) error {
+	return nil
+}
+
+//cli:command status
+//cli:hidden
+func status(args []string) error {
+	return nil
+}
+
+// helper is not a command.
+func helper() {}
+`
+
+const expectedSource = `// Code generated by cli-gen. DO NOT EDIT.
+
+package app
+
+import (
+	"flag"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+// generatedCommands returns the commands defined by cli: directives in this package.
+func generatedCommands() map[string]*cli.Command {
+	commands := map[string]*cli.Command{}
+
+	commands["deploy"] = &cli.Command{
+		RunContext:    Deploy,
+		Summary:       "Deploy the application",
+		Description:   "Deploy ships the application to the selected environment.",
+		RequiredFlags: []string{"env"},
+		Args: []cli.ArgSpec{
+			{Name: "app", Required: true},
+			{Name: "version"},
+		},
+	}
+	commands["deploy"].Flags = flag.NewFlagSet("deploy", flag.ContinueOnError)
+	commands["deploy"].Flags.String("env", "us east", "Environment to deploy to")
+	commands["deploy"].Flags.Bool("force", false, "Skip confirmation")
+	commands["deploy"].Flags.Duration("timeout", time.Duration(60000000000), "Time to wait")
+
+	commands["status"] = &cli.Command{
+		Run:    status,
+		Hidden: true,
+	}
+
+	return commands
+}
+`
+
+func writePackage(t *testing.T, source string) string {
+	dir, err := ioutil.TempDir("", "cli-gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "app.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writePackage(t, annotatedSource)
+	defer os.RemoveAll(dir)
+
+	g := &generator{Dir: dir, Output: "cli_gen.go", FuncName: "generatedCommands"}
+	source, err := g.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(source) != expectedSource {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedSource, source)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	type TestCase struct {
+		Source string
+		Error  string
+	}
+
+	cases := []TestCase{
+		{
+			Source: "package app\n\n//cli:command bad\nfunc bad() {}\n",
+			Error:  "must have the signature",
+		},
+		{
+			Source: "package app\n\n//cli:command bad\n//cli:flag count int many\nfunc bad(args []string) error { return nil }\n",
+			Error:  `invalid default "many" for flag --count`,
+		},
+		{
+			Source: "package app\n\n//cli:command bad\n//cli:required missing\nfunc bad(args []string) error { return nil }\n",
+			Error:  "required flag --missing is not defined",
+		},
+		{
+			Source: "package app\n\n//cli:command bad\n//cli:bogus\nfunc bad(args []string) error { return nil }\n",
+			Error:  `unknown directive "bogus"`,
+		},
+	}
+
+	for _, testCase := range cases {
+		dir := writePackage(t, testCase.Source)
+		g := &generator{Dir: dir, Output: "cli_gen.go", FuncName: "generatedCommands"}
+		_, err := g.Generate()
+		os.RemoveAll(dir)
+		if err == nil || !strings.Contains(err.Error(), testCase.Error) {
+			t.Errorf("Expected error containing %q, found %v", testCase.Error, err)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Command cli-licenses generates a list of cli.License values for the modules
+// required by go.mod, so a program can satisfy the attribution requirements
+// of its dependencies' licenses with the built-in licenses and about commands.
+// It is designed to be run by go generate:
+//
+//	//go:generate cli-licenses
+//
+// License texts are read from the module cache, so the modules must have been
+// downloaded, for example with "go mod download". Each module's LICENSE,
+// LICENCE, COPYING, and NOTICE files are included, and common licenses are
+// identified by their SPDX identifier.
+//
+// The generated file defines a function (named by -func) that returns the
+// licenses, which can be assigned to CLI.Licenses:
+//
+//	app.Licenses = thirdPartyLicenses()
+//
+// Replace directives in go.mod are not followed.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// generator holds the options for a single run of cli-licenses.
+type generator struct {
+	Dir      string
+	Output   string
+	FuncName string
+	ModCache string
+}
+
+// module is a requirement listed in go.mod.
+type module struct {
+	Path    string
+	Version string
+	License string
+	Text    string
+}
+
+// licenseFiles are the prefixes of files that hold license notices.
+var licenseFiles = []string{"LICENSE", "LICENCE", "COPYING", "NOTICE"}
+
+func main() {
+	g := &generator{}
+	flag.StringVar(&g.Dir, "dir", ".", "Directory containing go.mod")
+	flag.StringVar(&g.Output, "output", "licenses_gen.go", "Name of the generated file, relative to -dir")
+	flag.StringVar(&g.FuncName, "func", "thirdPartyLicenses", "Name of the generated function")
+	flag.StringVar(&g.ModCache, "modcache", "", "Module cache directory (default from go env GOMODCACHE)")
+	flag.Parse()
+
+	source, err := g.Generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cli-licenses: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(g.Dir, g.Output), source, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "cli-licenses: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// Generate reads go.mod in g.Dir and returns the formatted source of the
+// generated file.
+func (g *generator) Generate() ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(g.Dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	modules, err := parseRequirements(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	cache := g.ModCache
+	if cache == "" {
+		if cache, err = moduleCache(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mod := range modules {
+		dir := filepath.Join(cache, escapePath(mod.Path)+"@"+escapePath(mod.Version))
+		text, err := readLicense(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s@%s: %s", mod.Path, mod.Version, err)
+		}
+		mod.Text = text
+		mod.License = identifyLicense(text)
+	}
+
+	pkgName, err := packageName(g.Dir, g.Output)
+	if err != nil {
+		return nil, err
+	}
+	return render(pkgName, g.FuncName, modules)
+}
+
+// parseRequirements returns the modules listed in require directives, sorted
+// by path.
+func parseRequirements(gomod string) ([]*module, error) {
+	var modules []*module
+	inBlock := false
+	for i, line := range strings.Split(gomod, "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case !inBlock && fields[0] == "require":
+			fields = fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				inBlock = true
+				continue
+			}
+		case !inBlock:
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("go.mod:%d: malformed requirement", i+1)
+		}
+		path, err := unquote(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("go.mod:%d: %s", i+1, err)
+		}
+		modules = append(modules, &module{Path: path, Version: fields[1]})
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Path < modules[j].Path
+	})
+	return modules, nil
+}
+
+// unquote removes the quotes from a module path, if it is quoted.
+func unquote(path string) (string, error) {
+	if strings.HasPrefix(path, `"`) || strings.HasPrefix(path, "`") {
+		return strconv.Unquote(path)
+	}
+	return path, nil
+}
+
+// moduleCache returns the location of the module cache.
+func moduleCache() (string, error) {
+	if cache := os.Getenv("GOMODCACHE"); cache != "" {
+		return cache, nil
+	}
+	output, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate the module cache: %s", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// escapePath encodes a module path or version the way the module cache does,
+// replacing each upper-case letter with an exclamation mark followed by the
+// lower-case letter.
+func escapePath(path string) string {
+	var escaped strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			escaped.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// readLicense returns the contents of the license files in dir.
+func readLicense(dir string) (string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("not found in the module cache, run go mod download")
+		}
+		return "", err
+	}
+
+	var texts []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isLicenseFile(entry.Name()) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		texts = append(texts, strings.TrimSpace(string(data)))
+	}
+	if len(texts) == 0 {
+		return "", fmt.Errorf("no license file found in %s", dir)
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// isLicenseFile reports whether name is a license file, such as LICENSE.md or
+// COPYING.
+func isLicenseFile(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, prefix := range licenseFiles {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// identifyLicense returns the SPDX identifier of common licenses, or an empty
+// string if the license is not recognized.
+func identifyLicense(text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	contains := func(s string) bool {
+		return strings.Contains(normalized, s)
+	}
+
+	switch {
+	case contains("Apache License") && contains("Version 2.0"):
+		return "Apache-2.0"
+	case contains("Mozilla Public License") && contains("2.0"):
+		return "MPL-2.0"
+	case contains("Permission is hereby granted, free of charge"):
+		return "MIT"
+	case contains("Permission to use, copy, modify, and/or distribute"), contains("Permission to use, copy, modify, and distribute"):
+		return "ISC"
+	case contains("Redistribution and use in source and binary forms"):
+		if contains("Neither the name") || contains("may be used to endorse or promote") {
+			return "BSD-3-Clause"
+		}
+		return "BSD-2-Clause"
+	}
+	return ""
+}
+
+// packageName returns the name of the package in dir, ignoring the generated
+// file and tests. It defaults to main if dir has no Go files.
+func packageName(dir, output string) (string, error) {
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return info.Name() != output && !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	if len(packages) > 1 {
+		return "", fmt.Errorf("expected one package in %s, found %d", dir, len(packages))
+	}
+	for name := range packages {
+		return name, nil
+	}
+	return "main", nil
+}
+
+// render produces the generated file.
+func render(pkgName, funcName string, modules []*module) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by cli-licenses. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(buf, "import \"github.com/cbednarski/cli\"\n\n")
+	fmt.Fprintf(buf, "// %s returns the licenses of the modules required by go.mod.\n", funcName)
+	fmt.Fprintf(buf, "func %s() []cli.License {\n", funcName)
+	fmt.Fprintf(buf, "return []cli.License{\n")
+	for _, mod := range modules {
+		fmt.Fprintf(buf, "{\n")
+		fmt.Fprintf(buf, "Module: %q,\n", mod.Path)
+		fmt.Fprintf(buf, "Version: %q,\n", mod.Version)
+		if mod.License != "" {
+			fmt.Fprintf(buf, "License: %q,\n", mod.License)
+		}
+		fmt.Fprintf(buf, "Text: %q,\n", mod.Text)
+		fmt.Fprintf(buf, "},\n")
+	}
+	fmt.Fprintf(buf, "}\n}\n")
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testGoMod = `module example.com/ferry
+
+go 1.16
+
+require github.com/cbednarski/cli v1.4.0
+
+require (
+	github.com/BurntSushi/toml v1.2.1
+	golang.org/x/sys v0.5.0 // indirect
+)
+`
+
+const mitLicense = `MIT License
+
+Copyright (c) 2013 TOML authors
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software...`
+
+const bsdLicense = `Copyright (c) 2009 The Go Authors. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met: ... Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.`
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"app/go.mod":  testGoMod,
+		"app/main.go": "package main\n",
+		"cache/github.com/cbednarski/cli@v1.4.0/LICENSE":    mitLicense,
+		"cache/github.com/!burnt!sushi/toml@v1.2.1/COPYING": mitLicense,
+		"cache/golang.org/x/sys@v0.5.0/LICENSE":             bsdLicense,
+		"cache/golang.org/x/sys@v0.5.0/PATENTS":             "Additional IP Rights Grant",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := &generator{
+		Dir:      filepath.Join(dir, "app"),
+		Output:   "licenses_gen.go",
+		FuncName: "thirdPartyLicenses",
+		ModCache: filepath.Join(dir, "cache"),
+	}
+	source, err := g.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"// Code generated by cli-licenses. DO NOT EDIT.",
+		"package main",
+		"func thirdPartyLicenses() []cli.License {",
+		`Module:  "github.com/BurntSushi/toml",`,
+		`Version: "v1.2.1",`,
+		`License: "BSD-3-Clause",`,
+		`Text:    "MIT License\n\nCopyright (c) 2013 TOML authors`,
+	} {
+		if !strings.Contains(string(source), expected) {
+			t.Errorf("Expected generated source to contain %q\n%s", expected, source)
+		}
+	}
+	if strings.Contains(string(source), "Additional IP Rights Grant") {
+		t.Error("Expected PATENTS to be excluded")
+	}
+	// Modules are sorted by path
+	if strings.Index(string(source), `"github.com/BurntSushi`) > strings.Index(string(source), `"github.com/cbednarski/cli",`) {
+		t.Error("Expected modules to be sorted")
+	}
+
+	g.ModCache = filepath.Join(dir, "empty")
+	if _, err := g.Generate(); err == nil || !strings.Contains(err.Error(), "go mod download") {
+		t.Errorf("Expected a missing module error, found %v", err)
+	}
+}
+
+func TestIdentifyLicense(t *testing.T) {
+	type TestCase struct {
+		Text     string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{mitLicense, "MIT"},
+		{bsdLicense, "BSD-3-Clause"},
+		{"Redistribution and use in source and binary forms, with or without modification", "BSD-2-Clause"},
+		{"Apache License\n   Version 2.0, January 2004", "Apache-2.0"},
+		{"Mozilla Public License Version 2.0", "MPL-2.0"},
+		{"Permission to use, copy, modify, and/or distribute this software", "ISC"},
+		{"All rights reserved.", ""},
+	}
+
+	for _, c := range cases {
+		if found := identifyLicense(c.Text); found != c.Expected {
+			t.Errorf("Expected %q, found %q for %q", c.Expected, found, c.Text)
+		}
+	}
+}
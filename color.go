@@ -0,0 +1,30 @@
+package cli
+
+import "os"
+
+// ANSI escape sequences used to style terminal output.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// colorEnabled reports whether styled output should be written to f. Color is
+// disabled when f is not a terminal, when the NO_COLOR environment variable
+// is set (see https://no-color.org), or when TERM is "dumb".
+func colorEnabled(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// colorize wraps text in the specified ANSI style.
+func colorize(style, text string) string {
+	return style + text + ansiReset
+}
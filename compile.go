@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Dispatcher runs a CLI whose command tree has been validated and analyzed
+// ahead of time by Compile.
+type Dispatcher struct {
+	cli *CLI
+}
+
+// Run parses os.Args and invokes the selected command. See CLI.Run.
+func (d *Dispatcher) Run() error {
+	return d.cli.Run()
+}
+
+// CLI returns the compiled CLI.
+func (d *Dispatcher) CLI() *CLI {
+	return d.cli
+}
+
+// compiledTree holds the results of Compile.
+type compiledTree struct {
+	commands map[string]*Command
+	usages   map[string]string
+}
+
+// Compile validates the whole command tree once and precomputes the sorted
+// command list, column widths, and usage strings used for help output, so Run
+// does not repeat that work on every invocation. This is worthwhile for CLIs
+// with thousands of commands.
+//
+// Compile loads HelpDir and constructs any lazy commands. Afterwards, the
+// command tree is frozen: AddCommand and RemoveCommand fail, and changes made
+// directly to Commands are ignored.
+func (c *CLI) Compile() (*Dispatcher, error) {
+	if c.Name == "" {
+		c.Name = programName(os.Args[0])
+	}
+	if err := c.validateName(); err != nil {
+		return nil, err
+	}
+
+	helpDir := c.HelpDir
+	if dir := os.Getenv(c.envVar("HELP_DIR")); dir != "" {
+		helpDir = dir
+	}
+	if helpDir != "" {
+		if err := LoadHelpDir(c, helpDir); err != nil {
+			return nil, err
+		}
+	}
+
+	commands := c.resolvedCommands()
+	tree := &compiledTree{
+		commands: commands,
+		usages:   map[string]string{},
+	}
+
+	for name, command := range commands {
+		if err := validateCommand(name, command); err != nil {
+			return nil, err
+		}
+		tree.usages[name] = CommandUsage(c, name)
+	}
+
+	c.mu.Lock()
+	c.compiled = tree
+	c.listing = nil
+	c.mu.Unlock()
+
+	// Sort the command list now rather than on first use
+	c.commandListing()
+
+	return &Dispatcher{cli: c}, nil
+}
+
+// validateCommand checks a command for programmer errors that Run would
+// otherwise only detect when the command is invoked.
+func validateCommand(name string, command *Command) error {
+	if strings.ContainsAny(name, " \n\t") {
+		return fmt.Errorf("command names (%q) must not contain spaces", name)
+	}
+	return validateCommandTree(name, command)
+}
+
+// validateCommandTree checks command, which has the specified name or path,
+// and all of its subcommands.
+func validateCommandTree(name string, command *Command) error {
+	if errs := commandErrors(name, command); len(errs) > 0 {
+		return errs[0]
+	}
+	for subName, subcommand := range command.Commands {
+		if err := validateCommandTree(name+" "+subName, subcommand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commandErrors returns the programmer errors in command, which has the
+// specified name or path, without checking its subcommands.
+func commandErrors(name string, command *Command) (errs []error) {
+	if command == nil {
+		return []error{fmt.Errorf("command %q must not be nil", name)}
+	}
+	for _, flagName := range command.RequiredFlags {
+		if command.Flags == nil || command.Flags.Lookup(flagName) == nil {
+			errs = append(errs, fmt.Errorf("command %q: required flag (%q) is not defined", name, flagName))
+		}
+	}
+	for _, flagName := range command.SensitiveFlags {
+		if command.Flags == nil || command.Flags.Lookup(flagName) == nil {
+			errs = append(errs, fmt.Errorf("command %q: sensitive flag (%q) is not defined", name, flagName))
+		}
+	}
+	for _, subName := range SortedCommandNames(command.Commands) {
+		if subName == "" {
+			errs = append(errs, fmt.Errorf("command %q: subcommand name must not be empty", name))
+		} else if strings.ContainsAny(subName, " \n\t") {
+			errs = append(errs, fmt.Errorf("command %q: subcommand names (%q) must not contain spaces", name, subName))
+		}
+	}
+	return
+}
+
+// Validate checks the definition of the CLI and all of its commands, and
+// returns every problem it finds. It reports the programmer errors that Run
+// and Compile would otherwise panic or fail on, such as command names that
+// contain spaces, along with mistakes that only affect the user, such as a
+// command that is shadowed by a built-in command or that is listed in help
+// without a summary, and conflicts in Reserved. Call it from a test:
+//
+//	func TestCLI(t *testing.T) {
+//		for _, err := range app.Validate() {
+//			t.Error(err)
+//		}
+//	}
+//
+// Validate constructs any lazy commands, but does not load HelpDir.
+func (c *CLI) Validate() (errs []error) {
+	if c.Name != "" {
+		if err := c.validateName(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, c.validateReserved()...)
+
+	commands := c.resolvedCommands()
+	for _, name := range SortedCommandNames(commands) {
+		switch {
+		case name == "":
+			errs = append(errs, fmt.Errorf("command name must not be empty"))
+			continue
+		case strings.ContainsAny(name, " \n\t"):
+			errs = append(errs, fmt.Errorf("command names (%q) must not contain spaces", name))
+			continue
+		case strings.HasPrefix(name, "-"):
+			errs = append(errs, fmt.Errorf("command %q can't be invoked because it starts with '-'", name))
+		case c.builtin(name):
+			errs = append(errs, fmt.Errorf("command %q is shadowed by the built-in %s command, rename it or add it to Reserved.Reclaim", name, c.builtinKey(name)))
+		}
+		errs = append(errs, c.lintCommand(name, commands[name])...)
+	}
+	return
+}
+
+// lintCommand returns the problems Validate reports for command, which has
+// the specified name or path, and its subcommands.
+func (c *CLI) lintCommand(name string, command *Command) []error {
+	errs := commandErrors(name, command)
+	if command == nil {
+		return errs
+	}
+	errs = append(errs, platformErrors(name, command)...)
+
+	// Commands for other platforms are checked as though they were listed so
+	// Validate reports the same problems on every platform
+	if c.listed(command) || (!command.supported() && !command.Hidden && !command.HelpOnly) {
+		if command.Run == nil && command.RunContext == nil && command.Runner == nil && command.Eval == nil && len(command.Commands) == 0 {
+			errs = append(errs, fmt.Errorf("command %q is listed in help but has no Run function", name))
+		}
+		if command.topicSummary() == "" {
+			errs = append(errs, fmt.Errorf("command %q has no Summary", name))
+		}
+	}
+
+	for _, subName := range SortedCommandNames(command.Commands) {
+		if subName != "" && !strings.ContainsAny(subName, " \n\t") {
+			errs = append(errs, c.lintCommand(name+" "+subName, command.Commands[subName])...)
+		}
+	}
+	return errs
+}
+
+// tree returns the compiled command tree, or nil if Compile has not been
+// called.
+func (c *CLI) tree() *compiledTree {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.compiled
+}
@@ -0,0 +1,162 @@
+package cli_test
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestCompile(t *testing.T) {
+	cleanup, _ := redirectIO()
+	defer cleanup()
+
+	ran := false
+	set := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	set.String("env", "", "")
+
+	app := &cli.CLI{
+		Name: "prog",
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Summary: "Deploy the application",
+				Flags:   set,
+				Args:    []cli.ArgSpec{{Name: "app", Required: true}},
+				Run: func(args []string) error {
+					ran = true
+					return nil
+				},
+			},
+			"status":                  {Summary: "Show status"},
+			"a-very-long-experiment":  {Summary: "Try something", Experimental: true},
+			"a-very-long-hidden-name": {Hidden: true},
+		},
+	}
+	expectedHelp := cli.CommandHelp(app)
+
+	dispatcher, err := app.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if help := cli.CommandHelp(dispatcher.CLI()); help != expectedHelp {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedHelp, help)
+	}
+	if usage := cli.CommandUsage(app, "deploy"); usage != "prog deploy [flags] <app>" {
+		t.Errorf("Expected %q, found %q", "prog deploy [flags] <app>", usage)
+	}
+
+	// The compiled tree is frozen
+	if err := app.AddCommand("late", &cli.Command{}); err == nil {
+		t.Error("Expected AddCommand to fail after Compile")
+	}
+	if app.RemoveCommand("status") {
+		t.Error("Expected RemoveCommand to fail after Compile")
+	}
+	delete(app.Commands, "deploy")
+
+	os.Args = []string{"prog", "deploy", "web"}
+	if err := dispatcher.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("Expected deploy to run")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	type TestCase struct {
+		App   *cli.CLI
+		Error string
+	}
+
+	cases := []TestCase{
+		{
+			App:   &cli.CLI{Name: "my prog"},
+			Error: `program name ("my prog") must not contain spaces, try renaming the binary`,
+		},
+		{
+			App:   &cli.CLI{Name: "prog", Commands: map[string]*cli.Command{"two words": {}}},
+			Error: `command names ("two words") must not contain spaces`,
+		},
+		{
+			App:   &cli.CLI{Name: "prog", Commands: map[string]*cli.Command{"nil": nil}},
+			Error: `command "nil" must not be nil`,
+		},
+		{
+			App:   &cli.CLI{Name: "prog", Commands: map[string]*cli.Command{"deploy": {RequiredFlags: []string{"env"}}}},
+			Error: `command "deploy": required flag ("env") is not defined`,
+		},
+	}
+
+	for _, testCase := range cases {
+		_, err := testCase.App.Compile()
+		if err == nil || err.Error() != testCase.Error {
+			t.Errorf("Expected %q, found %v", testCase.Error, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	run := func(args []string) error { return nil }
+	set := flag.NewFlagSet("deploy", flag.ContinueOnError)
+
+	app := &cli.CLI{
+		Name: "prog",
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Summary:       "Deploy the application",
+				Flags:         set,
+				RequiredFlags: []string{"env"},
+				Run:           run,
+			},
+			"help":      {Summary: "My own help", Run: run},
+			"status":    {Run: run},
+			"todo":      {Summary: "Not done yet"},
+			"secret":    {Hidden: true},
+			"two words": {Summary: "Oops", Run: run},
+			"--dry-run": {Summary: "Not a command", Run: run},
+			"guide":     {HelpOnly: true, Help: "Read me"},
+			"release": {
+				Summary: "Manage releases",
+				Commands: map[string]*cli.Command{
+					"create": {Summary: "Create a release", Run: run},
+					"list":   {Run: run},
+					"bad":    nil,
+				},
+			},
+		},
+	}
+
+	expected := []string{
+		`command "--dry-run" can't be invoked because it starts with '-'`,
+		`command "deploy": required flag ("env") is not defined`,
+		`command "help" is shadowed by the built-in help command, rename it or add it to Reserved.Reclaim`,
+		`command "release bad" must not be nil`,
+		`command "release list" has no Summary`,
+		`command "status" has no Summary`,
+		`command "todo" is listed in help but has no Run function`,
+		`command names ("two words") must not contain spaces`,
+	}
+
+	errs := app.Validate()
+	var found []string
+	for _, err := range errs {
+		found = append(found, err.Error())
+	}
+	if strings.Join(found, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", strings.Join(expected, "\n"), strings.Join(found, "\n"))
+	}
+
+	valid := &cli.CLI{
+		Name: "prog",
+		Commands: map[string]*cli.Command{
+			"deploy": {Summary: "Deploy the application", Run: run},
+		},
+	}
+	if errs := valid.Validate(); len(errs) != 0 {
+		t.Errorf("Expected no errors, found %v", errs)
+	}
+}
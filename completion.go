@@ -0,0 +1,860 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CompletionKind selects how a value is completed when the user presses tab.
+type CompletionKind int
+
+const (
+	// CompleteDefault uses the shell's default completion, which is usually
+	// filenames.
+	CompleteDefault CompletionKind = iota
+
+	// CompleteNone disables completion.
+	CompleteNone
+
+	// CompleteFiles completes filenames.
+	CompleteFiles
+
+	// CompleteDirs completes directory names.
+	CompleteDirs
+
+	// CompleteHosts completes hostnames known to the shell, such as those in
+	// /etc/hosts and ~/.ssh/known_hosts.
+	CompleteHosts
+
+	// CompleteValues completes from a fixed list of values.
+	CompleteValues
+)
+
+// completionDirectives are the names used for each CompletionKind in the
+// __complete protocol.
+var completionDirectives = map[CompletionKind]string{
+	CompleteDefault: "default",
+	CompleteNone:    "none",
+	CompleteFiles:   "files",
+	CompleteDirs:    "dirs",
+	CompleteHosts:   "hosts",
+	CompleteValues:  "values",
+}
+
+// Completion is a hint that describes how to complete an argument. It is
+// translated into the native completion action for each shell.
+type Completion struct {
+	Kind CompletionKind
+
+	// Values lists the valid values for CompleteValues. If Values is set, Kind
+	// may be omitted.
+	Values []string
+
+	// Extensions limits CompleteFiles to files with these extensions, such as
+	// "yml" or ".yaml". Directories are always completed so the user can
+	// navigate into them.
+	Extensions []string
+}
+
+// kind returns the effective kind of completion.
+func (h Completion) kind() CompletionKind {
+	if h.Kind == CompleteDefault && len(h.Values) > 0 {
+		return CompleteValues
+	}
+	return h.Kind
+}
+
+// CompletionResult is the response to a completion request.
+type CompletionResult struct {
+	// Candidates are the possible completions for the current word. They are
+	// used when Kind is CompleteValues.
+	Candidates []string
+
+	// Kind tells the shell how to complete the current word.
+	Kind CompletionKind
+
+	// Extensions limits file completion to files with these extensions,
+	// without the leading dot. They are used when Kind is CompleteFiles.
+	Extensions []string
+
+	// Descriptions optionally describe Candidates, keyed by candidate. Shells
+	// that support it show the description next to the candidate.
+	Descriptions map[string]string
+}
+
+// String formats the result using the __complete protocol: one candidate per
+// line, followed by a line with a colon and the completion directive. A
+// candidate with a description is followed by a tab and the description. The
+// files directive may be followed by a space-separated list of extensions.
+func (r *CompletionResult) String() string {
+	output := ""
+	for _, candidate := range r.Candidates {
+		output += candidate
+		if description := r.Descriptions[candidate]; description != "" {
+			output += "\t" + strings.Join(strings.Fields(description), " ")
+		}
+		output += "\n"
+	}
+	output += ":" + completionDirectives[r.Kind]
+	if r.Kind == CompleteFiles && len(r.Extensions) > 0 {
+		output += " " + strings.Join(r.Extensions, " ")
+	}
+	return output + "\n"
+}
+
+// Complete returns completions for words, which are the arguments following the
+// program name. The last word is the one being completed and may be empty.
+// Complete is used by the hidden __complete command that backs the scripts
+// produced by CompletionScript.
+func Complete(c *CLI, words []string) *CompletionResult {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	// Complete the command name
+	if len(words) == 1 {
+		var names []string
+		for _, name := range []string{c.reserved().Help, "completion", "shell-init", "changelog", "about", "licenses", "examples", "tutorial", "jobs", "alias"} {
+			if c.builtin(name) {
+				names = append(names, name)
+			}
+		}
+		if c.UserAliases {
+			if aliases, err := c.LoadAliases(); err == nil {
+				for name := range aliases {
+					names = append(names, name)
+				}
+			}
+		}
+		commands := c.commands()
+		for _, name := range c.commandListing().names {
+			if c.listed(commands[name]) && c.permitted(name) {
+				names = append(names, name)
+			}
+		}
+		if c.Plugins {
+			for _, name := range c.pluginNames() {
+				if _, ok := commands[name]; !ok {
+					names = append(names, name)
+				}
+			}
+		}
+		return describeCommands(valuesResult(names, current), commands)
+	}
+
+	commandName := words[0]
+	previous := words[1 : len(words)-1]
+
+	switch c.builtinKey(commandName) {
+	case "help":
+		if len(previous) == 1 && previous[0] == "glossary" && c.hasGlossary() {
+			terms := make([]string, 0, len(c.Glossary))
+			for term := range c.Glossary {
+				terms = append(terms, term)
+			}
+			return valuesResult(terms, current)
+		}
+		if len(previous) > 0 {
+			// Complete subcommands, as in "help release <tab>"
+			if parent, ok := c.lookupPath(strings.Join(previous, " ")); ok && len(parent.Commands) > 0 {
+				var names []string
+				for name, command := range parent.Commands {
+					if c.listed(command) && c.permitted(strings.Join(previous, " ")+" "+name) {
+						names = append(names, name)
+					}
+				}
+				return valuesResult(names, current)
+			}
+			return &CompletionResult{Kind: CompleteNone}
+		}
+		var topics []string
+		if c.hasGlossary() {
+			topics = append(topics, "glossary")
+		}
+		for name, command := range c.resolvedCommands() {
+			if !command.Hidden && command.hasHelp() && (!command.Experimental || c.ExperimentalEnabled()) && command.supported() && (command.HelpOnly || c.permitted(name)) {
+				topics = append(topics, name)
+			}
+		}
+		return valuesResult(topics, current)
+	case "completion":
+		if c.Completion && len(previous) == 0 {
+			return valuesResult(append([]string{"cache"}, completionShells...), current)
+		}
+		if c.Completion && len(previous) == 1 && previous[0] == "cache" {
+			return valuesResult([]string{"clear"}, current)
+		}
+	case "alias":
+		if c.UserAliases && len(previous) == 0 {
+			return valuesResult([]string{"list", "set", "remove"}, current)
+		}
+	case "shell-init":
+		if c.ShellInit != nil && len(previous) == 0 {
+			return valuesResult(shellInitShells, current)
+		}
+	case "changelog":
+		if c.Changelog == "" {
+			break
+		}
+		if len(previous) > 0 && (previous[len(previous)-1] == "--since" || previous[len(previous)-1] == "-since") {
+			return valuesResult(c.changelogVersions(), current)
+		}
+		if strings.HasPrefix(current, "--since=") {
+			result := valuesResult(c.changelogVersions(), strings.TrimPrefix(current, "--since="))
+			for i := range result.Candidates {
+				result.Candidates[i] = "--since=" + result.Candidates[i]
+			}
+			return result
+		}
+		return valuesResult([]string{"--since"}, current)
+	case "examples":
+		if !c.hasExamples() {
+			break
+		}
+		if strings.HasPrefix(current, "-") {
+			return valuesResult([]string{"--run", "--yes"}, current)
+		}
+		if len(previous) == 0 {
+			var names []string
+			for name, command := range c.commands() {
+				if len(command.Examples) > 0 && c.listed(command) {
+					names = append(names, name)
+				}
+			}
+			return valuesResult(names, current)
+		}
+		return &CompletionResult{Kind: CompleteNone}
+	case "tutorial":
+		if len(c.Tutorial) > 0 {
+			return valuesResult([]string{"--restart", "--step"}, current)
+		}
+	case "jobs":
+		if !c.hasDetachable() {
+			break
+		}
+		if len(previous) == 0 {
+			return valuesResult([]string{"list", "logs", "stop"}, current)
+		}
+		if len(previous) == 1 && (previous[0] == "logs" || previous[0] == "stop") {
+			var ids []string
+			for _, job := range c.jobs() {
+				ids = append(ids, job.ID)
+			}
+			return valuesResult(ids, current)
+		}
+		return &CompletionResult{Kind: CompleteNone}
+	case "licenses":
+		if len(c.Licenses) > 0 && len(previous) == 0 {
+			modules := make([]string, len(c.Licenses))
+			for i, license := range c.Licenses {
+				modules[i] = license.Module
+			}
+			return valuesResult(modules, current)
+		}
+	}
+
+	command, ok := c.lookup(commandName)
+	if !ok && c.Plugins {
+		if path, ok := c.pluginPath(commandName); ok {
+			result := pluginCompletions(path, words[1:])
+			if result.Kind == CompleteValues {
+				// Don't rely on the plugin to filter its candidates
+				filtered := valuesResult(result.Candidates, current)
+				filtered.Descriptions = result.Descriptions
+				return filtered
+			}
+			return result
+		}
+	}
+	if !ok || command.HelpOnly {
+		return &CompletionResult{Kind: CompleteNone}
+	}
+
+	// Descend into the subcommands named by the preceding words
+	for len(command.Commands) > 0 {
+		if len(previous) == 0 {
+			var names []string
+			for name, subcommand := range command.Commands {
+				if c.listed(subcommand) && c.permitted(commandName+" "+name) {
+					names = append(names, name)
+				}
+			}
+			return describeCommands(valuesResult(names, current), command.Commands)
+		}
+		subcommand, ok := command.Commands[previous[0]]
+		if !ok || subcommand == nil || subcommand.HelpOnly {
+			return &CompletionResult{Kind: CompleteNone}
+		}
+		commandName += " " + previous[0]
+		command = subcommand
+		previous = previous[1:]
+	}
+
+	if completer, ok := command.Runner.(Completer); ok {
+		return valuesResult(c.cachedCompletions(commandName, command, completer, previous), current)
+	}
+
+	// Count the positional arguments before the current word to find which
+	// ArgSpec applies to it, skipping flags and their values
+	position := 0
+	terminated := false
+	var pending *flag.Flag
+	for _, word := range previous {
+		switch {
+		case pending != nil:
+			pending = nil
+		case terminated || len(word) < 2 || word[0] != '-':
+			position++
+		case word == "--":
+			terminated = true
+		case !strings.Contains(word, "="):
+			pending = command.valueFlag(strings.TrimLeft(word, "-"))
+		}
+	}
+
+	// Complete the value of a flag, passed as "--flag value" or "--flag=value"
+	if pending != nil {
+		return hintResult(command.flagCompletion(pending.Name), current)
+	}
+	if !terminated && strings.HasPrefix(current, "-") {
+		if idx := strings.Index(current, "="); idx > -1 {
+			prefix := current[:idx+1]
+			result := hintResult(command.flagCompletion(strings.TrimLeft(current[:idx], "-")), current[idx+1:])
+			for i := range result.Candidates {
+				result.Candidates[i] = prefix + result.Candidates[i]
+			}
+			return result
+		}
+		return valuesResult(command.flagNames(), current)
+	}
+
+	if position >= len(command.Args) {
+		if len(command.Args) > 0 {
+			return &CompletionResult{Kind: CompleteNone}
+		}
+		return &CompletionResult{Kind: CompleteDefault}
+	}
+
+	return hintResult(command.Args[position].Complete, current)
+}
+
+// cachedCompletions returns the candidates from completer, using results cached
+// in the CacheDir if the command has a CompletionTTL. Caching is best-effort;
+// if the cache can't be read or written, completer is called directly.
+func (c *CLI) cachedCompletions(name string, command *Command, completer Completer, args []string) []string {
+	if command.CompletionTTL <= 0 {
+		return completer.Complete(args)
+	}
+
+	dir, err := c.completionCacheDir()
+	if err != nil {
+		return completer.Complete(args)
+	}
+
+	// Use a hash so arbitrary arguments make a safe filename
+	key := sha256.Sum256([]byte(strings.Join(append([]string{name}, args...), "\x00")))
+	path := filepath.Join(dir, hex.EncodeToString(key[:]))
+
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < command.CompletionTTL {
+		if data, err := ioutil.ReadFile(path); err == nil {
+			if len(data) == 0 {
+				return nil
+			}
+			return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+	}
+
+	candidates := completer.Complete(args)
+
+	data := ""
+	for _, candidate := range candidates {
+		data += candidate + "\n"
+	}
+	if err := os.MkdirAll(dir, 0700); err == nil {
+		ioutil.WriteFile(path, []byte(data), 0600)
+	}
+
+	return candidates
+}
+
+// completionCacheDir returns the directory used to cache completions.
+func (c *CLI) completionCacheDir() (string, error) {
+	dir, err := c.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "completion"), nil
+}
+
+// ClearCompletionCache removes completion results cached for commands with a
+// CompletionTTL. It is invoked by "program completion cache clear".
+func (c *CLI) ClearCompletionCache() error {
+	dir, err := c.completionCacheDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// valueFlag returns the named flag if it takes a value, or nil if it is a
+// boolean flag or not defined.
+func (command *Command) valueFlag(name string) *flag.Flag {
+	if command.Flags == nil {
+		return nil
+	}
+	f := command.Flags.Lookup(name)
+	if f == nil {
+		return nil
+	}
+	if b, ok := f.Value.(boolFlag); ok && b.IsBoolFlag() {
+		return nil
+	}
+	return f
+}
+
+// flagCompletion returns the completion hint for the named flag's value.
+func (command *Command) flagCompletion(name string) Completion {
+	if hint, ok := command.FlagCompletions[name]; ok {
+		return hint
+	}
+	return Completion{Kind: CompleteNone}
+}
+
+// flagNames returns the command's flags in --name form.
+func (command *Command) flagNames() []string {
+	names := []string{}
+	if command.Flags != nil {
+		command.Flags.VisitAll(func(f *flag.Flag) {
+			names = append(names, "--"+f.Name)
+		})
+	}
+	return names
+}
+
+// hintResult converts a completion hint into a result.
+func hintResult(hint Completion, current string) *CompletionResult {
+	switch hint.kind() {
+	case CompleteValues:
+		return valuesResult(hint.Values, current)
+	case CompleteFiles:
+		result := &CompletionResult{Kind: CompleteFiles}
+		for _, ext := range hint.Extensions {
+			result.Extensions = append(result.Extensions, strings.TrimPrefix(ext, "."))
+		}
+		return result
+	}
+	return &CompletionResult{Kind: hint.kind()}
+}
+
+// valuesResult returns the values that begin with prefix, in lexical order.
+func valuesResult(values []string, prefix string) *CompletionResult {
+	result := &CompletionResult{Kind: CompleteValues}
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			result.Candidates = append(result.Candidates, value)
+		}
+	}
+	sort.Strings(result.Candidates)
+	return result
+}
+
+// describeCommands describes each candidate in result that names one of
+// commands with the command's Summary and Annotations, and returns result.
+func describeCommands(result *CompletionResult, commands map[string]*Command) *CompletionResult {
+	for _, name := range result.Candidates {
+		command := commands[name]
+		if command == nil {
+			continue
+		}
+		description := command.Summary
+		if len(command.Annotations) > 0 {
+			keys := make([]string, 0, len(command.Annotations))
+			for key := range command.Annotations {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for i, key := range keys {
+				keys[i] = key + "=" + command.Annotations[key]
+			}
+			if description == "" {
+				description = strings.Join(keys, ", ")
+			} else {
+				description += " (" + strings.Join(keys, ", ") + ")"
+			}
+		}
+		if description == "" {
+			continue
+		}
+		if result.Descriptions == nil {
+			result.Descriptions = map[string]string{}
+		}
+		result.Descriptions[name] = description
+	}
+	return result
+}
+
+// completionShells lists the shells supported by CompletionScript.
+var completionShells = []string{"bash", "fish", "powershell", "zsh"}
+
+// CompletionScript returns a completion script for the specified shell. The
+// script calls the hidden "program __complete" command to get completions, so
+// it does not need to be regenerated when commands change.
+//
+// The script passes a hash of itself to __complete in the
+// PROG_COMPLETION_SCHEMA environment variable. If the script was generated by
+// an older version of the program, the user is warned once, the next time
+// they run the program, to reinstall it.
+//
+// Users can enable completion by adding a line like the following to their
+// shell's startup file:
+//
+//	source <(program completion bash)
+//
+// or, in PowerShell, to their $PROFILE:
+//
+//	program completion powershell | Out-String | Invoke-Expression
+//
+// If the shell is omitted, the completion command uses DetectShell.
+func CompletionScript(c *CLI, shell string) (string, error) {
+	template, ok := completionTemplates[shell]
+	if !ok {
+		return "", c.Strings.errorf("unsupported shell %q, expected one of: %s", shell, strings.Join(completionShells, ", "))
+	}
+	return fmt.Sprintf(template, c.Name, shellIdentifier(c.Name), c.envVar("COMPLETION_SCHEMA"), completionSchema(shell)), nil
+}
+
+// completionTemplates are the completion scripts for each shell. They are
+// formatted with the program name, the name as a shell identifier, the name of
+// the schema variable, and the schema.
+var completionTemplates = map[string]string{
+	"bash":       bashCompletion,
+	"fish":       fishCompletion,
+	"powershell": powershellCompletion,
+	"zsh":        zshCompletion,
+}
+
+// staleCompletionFile is the name of the file in the StateDir that records
+// the schema of an outdated completion script, and whether the user has been
+// warned about it.
+const staleCompletionFile = "stale-completion"
+
+// completionSchema identifies the version of the completion script for shell,
+// such as "bash:3f2a9c01b7d4". It changes whenever the script changes.
+func completionSchema(shell string) string {
+	sum := sha256.Sum256([]byte(completionTemplates[shell]))
+	return shell + ":" + hex.EncodeToString(sum[:6])
+}
+
+// checkCompletionSchema is called by __complete to compare the schema passed
+// by the completion script with the current one. The warning can't be shown
+// during completion, since the shell discards the output, so an outdated
+// script is recorded in the StateDir for warnStaleCompletion. Scripts that
+// don't pass a schema, such as when __complete is run by hand, are ignored.
+func (c *CLI) checkCompletionSchema() {
+	schema := os.Getenv(c.envVar("COMPLETION_SCHEMA"))
+	shell := strings.SplitN(schema, ":", 2)[0]
+	if schema == "" || completionTemplates[shell] == "" || schema == completionSchema(shell) {
+		return
+	}
+
+	dir, err := c.StateDir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, staleCompletionFile)
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) > 0 && fields[0] == schema {
+			return
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err == nil {
+		ioutil.WriteFile(path, []byte(schema+"\n"), 0644)
+	}
+}
+
+// warnStaleCompletion prints a warning to stderr if an outdated completion
+// script was recorded by checkCompletionSchema and the user has not been
+// warned about it yet. Like showWhatsNew, it is skipped for built-in commands
+// whose output is read by the shell and when stderr is not a terminal.
+func (c *CLI) warnStaleCompletion(commandName string) {
+	switch commandName {
+	case "__complete", "completion", "shell-init":
+		return
+	}
+	if f, ok := c.Printer.stderr().(*os.File); ok && !isTerminal(f) {
+		return
+	}
+
+	dir, err := c.StateDir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, staleCompletionFile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 1 {
+		return
+	}
+	schema := fields[0]
+	shell := strings.SplitN(schema, ":", 2)[0]
+	if schema == completionSchema(shell) {
+		return
+	}
+
+	if err := ioutil.WriteFile(path, []byte(schema+" warned\n"), 0644); err != nil {
+		return
+	}
+	c.Printer.Errf("%s %s\n", c.Printer.Symbols().Warning, c.Strings.sprintf("Your %s completions were installed by an older version of %s. Reinstall them with '%s completion %s'.", shell, c.Name, c.Name, shell))
+}
+
+const bashCompletion = `# bash completion for %[1]s
+
+_%[2]s_completion() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+
+    # Directly after --flag= the word being replaced is empty, not "="
+    [[ "$cur" == "=" ]] && cur=""
+
+    # bash splits --flag=value into three words, so join them back together
+    local -a args
+    local i
+    for ((i = 1; i <= COMP_CWORD; i++)); do
+        if [[ ${#args[@]} -gt 0 && ( "${COMP_WORDS[i]}" == "=" || "${COMP_WORDS[i-1]}" == "=" ) ]]; then
+            args[${#args[@]}-1]+="${COMP_WORDS[i]}"
+        else
+            args+=("${COMP_WORDS[i]}")
+        fi
+    done
+    local word="${args[${#args[@]}-1]}"
+
+    local lines=($(%[3]s=%[4]s %[1]s __complete "${args[@]}" 2>/dev/null))
+    local count=${#lines[@]}
+    [[ $count -eq 0 ]] && return
+    local directive="${lines[$count-1]}"
+    unset "lines[$count-1]"
+
+
+    case "$directive" in
+        :files\ *)
+            # Only complete directories and files with matching extensions
+            compopt -o filenames 2>/dev/null
+            local -a extensions
+            IFS=' ' read -ra extensions <<< "${directive#:files }"
+            local file ext
+            COMPREPLY=()
+            for file in $(compgen -f -- "$cur"); do
+                if [[ -d "$file" ]]; then
+                    COMPREPLY+=("$file")
+                    continue
+                fi
+                for ext in "${extensions[@]}"; do
+                    if [[ "$file" == *."$ext" ]]; then
+                        COMPREPLY+=("$file")
+                        break
+                    fi
+                done
+            done
+            ;;
+        :files|:default)
+            compopt -o filenames 2>/dev/null
+            COMPREPLY=($(compgen -f -- "$cur"))
+            ;;
+        :dirs)
+            compopt -o filenames 2>/dev/null
+            COMPREPLY=($(compgen -d -- "$cur"))
+            ;;
+        :hosts)
+            COMPREPLY=($(compgen -A hostname -- "$cur"))
+            ;;
+        :values)
+            # bash can't show descriptions, so remove them, along with the
+            # part of the word that bash isn't replacing
+            lines=("${lines[@]%%%%$'\t'*}")
+            COMPREPLY=("${lines[@]#"${word%%"$cur"}"}")
+            ;;
+        *)
+            COMPREPLY=()
+            ;;
+    esac
+}
+
+complete -F _%[2]s_completion %[1]s
+`
+
+const zshCompletion = `#compdef %[1]s
+# zsh completion for %[1]s
+
+_%[2]s() {
+    local -a lines candidates
+    lines=("${(@f)$(%[3]s=%[4]s %[1]s __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    local directive="${lines[-1]}"
+    candidates=("${(@)lines[1,-2]}")
+
+    case "$directive" in
+        :files\ *)
+            local -a extensions
+            extensions=(${=directive#:files })
+            [[ $PREFIX == -*=* ]] && compset -P '*='
+            _files -g "*.(${(j:|:)extensions})"
+            ;;
+        :files|:default)
+            [[ $PREFIX == -*=* ]] && compset -P '*='
+            _files
+            ;;
+        :dirs)
+            [[ $PREFIX == -*=* ]] && compset -P '*='
+            _files -/
+            ;;
+        :hosts)
+            [[ $PREFIX == -*=* ]] && compset -P '*='
+            _hosts
+            ;;
+        :values)
+            # Show descriptions, which follow a tab, next to the candidates
+            local -a values displays
+            local line
+            for line in "${candidates[@]}"; do
+                values+=("${line%%%%$'\t'*}")
+                if [[ "$line" == *$'\t'* ]]; then
+                    displays+=("${line%%%%$'\t'*}  -- ${line#*$'\t'}")
+                else
+                    displays+=("$line")
+                fi
+            done
+            compadd -l -d displays -a values
+            ;;
+    esac
+}
+
+if [ "$funcstack[1]" = "_%[2]s" ]; then
+    _%[2]s "$@"
+else
+    compdef _%[2]s %[1]s
+fi
+`
+
+const fishCompletion = `# fish completion for %[1]s
+
+function __%[2]s_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    set -l current (commandline -ct)
+    set -l lines (env %[3]s=%[4]s %[1]s __complete $args "$current" 2>/dev/null)
+    test (count $lines) -eq 0; and return
+    set -l directive $lines[-1]
+    set -e lines[-1]
+
+    # Complete the value of --flag=value, keeping the flag in the results
+    set -l prefix ""
+    if string match -q -- '-*=*' $current
+        set prefix (string replace -r '=.*' '=' -- $current)
+        set current (string replace -r '^[^=]*=' '' -- $current)
+    end
+
+    switch $directive
+        case ':files *'
+            # Only complete directories and files with matching extensions
+            set -l extensions (string split ' ' -- (string replace ':files ' '' -- $directive))
+            __fish_complete_path "$current" | string match -r -- '(/|\.('(string join '|' -- $extensions)'))(\t.*)?$' | string replace -r '^' -- $prefix
+        case :files :default
+            __fish_complete_path "$current" | string replace -r '^' -- $prefix
+        case :dirs
+            __fish_complete_directories "$current" | string replace -r '^' -- $prefix
+        case :hosts
+            __fish_print_hostnames | string replace -r '^' -- $prefix
+        case :values
+            printf '%%s\n' $lines
+    end
+end
+
+complete -c %[1]s -f -a '(__%[2]s_complete)'
+`
+
+const powershellCompletion = `# powershell completion for %[1]s
+
+Register-ArgumentCompleter -Native -CommandName '%[1]s' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    # Pass the words up to the cursor, without the program name
+    $words = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.EndOffset -le $cursorPosition } |
+        Select-Object -Skip 1 |
+        ForEach-Object { if ($_ -is [System.Management.Automation.Language.StringConstantExpressionAst]) { $_.Value } else { $_.Extent.Text } })
+    if ($wordToComplete -eq '') {
+        # Older versions of PowerShell drop empty arguments to native commands
+        if ($PSVersionTable.PSVersion -lt [version]'7.3' -or $PSNativeCommandArgumentPassing -eq 'Legacy') {
+            $words += '""'
+        } else {
+            $words += ''
+        }
+    }
+
+    $env:%[3]s = '%[4]s'
+    $lines = @(& '%[1]s' __complete @words 2>$null)
+    Remove-Item Env:%[3]s
+    if ($lines.Count -eq 0) {
+        return
+    }
+    $directive = $lines[-1]
+    $candidates = @($lines | Select-Object -SkipLast 1)
+
+    # Complete the value of --flag=value, keeping the flag in the results
+    $prefix = ''
+    $current = $wordToComplete
+    if ($current -match '^(-[^=]*=)(.*)$') {
+        $prefix = $Matches[1]
+        $current = $Matches[2]
+    }
+
+    # Paths are completed relative to the directory being completed in
+    $dir = Split-Path -Path "$current." -Parent
+    function ConvertTo-CompletionPath($name) {
+        if ($dir) { $prefix + (Join-Path $dir $name) } else { $prefix + $name }
+    }
+
+    switch -Wildcard ($directive) {
+        ':values' {
+            # Show descriptions, which follow a tab, as tooltips
+            foreach ($line in $candidates) {
+                $value, $description = $line -split "` + "`t" + `", 2
+                if (-not $description) {
+                    $description = $value
+                }
+                [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+            }
+        }
+        ':files *' {
+            # Only complete directories and files with matching extensions
+            $extensions = @($directive.Substring(':files '.Length) -split ' ')
+            Get-ChildItem -Path "$current*" -ErrorAction Ignore |
+                Where-Object { $_.PSIsContainer -or $extensions -contains $_.Extension.TrimStart('.') } |
+                ForEach-Object {
+                    $path = ConvertTo-CompletionPath $_.Name
+                    [System.Management.Automation.CompletionResult]::new($path, $_.Name, 'ProviderItem', $path)
+                }
+        }
+        ':dirs' {
+            Get-ChildItem -Path "$current*" -Directory -ErrorAction Ignore |
+                ForEach-Object {
+                    $path = ConvertTo-CompletionPath $_.Name
+                    [System.Management.Automation.CompletionResult]::new($path, $_.Name, 'ProviderContainer', $path)
+                }
+        }
+        # PowerShell completes paths when nothing is returned, which covers
+        # :files and :default; it has no host completion
+    }
+}
+`
@@ -0,0 +1,355 @@
+package cli_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func newCompletionTestApp() *cli.CLI {
+	deployFlags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	deployFlags.Bool("force", false, "")
+	deployFlags.String("region", "", "")
+	deployFlags.String("config", "", "")
+
+	return &cli.CLI{
+		Name:       "ship",
+		Completion: true,
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Summary: "deploy an application",
+				Help:    "Deploy the application to an environment.",
+				Flags:   deployFlags,
+				FlagCompletions: map[string]cli.Completion{
+					"region": {Values: []string{"us-east", "us-west", "eu"}},
+					"config": {Kind: cli.CompleteFiles},
+				},
+				Args: []cli.ArgSpec{
+					{Name: "env", Complete: cli.Completion{Values: []string{"staging", "production", "dev"}}},
+					{Name: "manifest", Complete: cli.Completion{Kind: cli.CompleteFiles, Extensions: []string{".yml", "yaml"}}},
+				},
+			},
+			"ssh": {
+				Args: []cli.ArgSpec{
+					{Name: "host", Complete: cli.Completion{Kind: cli.CompleteHosts}},
+				},
+			},
+			"logs": {},
+			"debug": {
+				Hidden: true,
+			},
+		},
+	}
+}
+
+func TestComplete(t *testing.T) {
+	app := newCompletionTestApp()
+
+	type TestCase struct {
+		Words              []string
+		ExpectedCandidates []string
+		ExpectedKind       cli.CompletionKind
+	}
+
+	cases := []TestCase{
+		{
+			Words:              []string{""},
+			ExpectedCandidates: []string{"completion", "deploy", "help", "logs", "ssh"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:              []string{"d"},
+			ExpectedCandidates: []string{"deploy"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:              []string{"deploy", ""},
+			ExpectedCandidates: []string{"dev", "production", "staging"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:              []string{"deploy", "--force", "st"},
+			ExpectedCandidates: []string{"staging"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:        []string{"deploy", "staging", "app.y"},
+			ExpectedKind: cli.CompleteFiles,
+		},
+		{
+			Words:        []string{"deploy", "staging", "app.yml", ""},
+			ExpectedKind: cli.CompleteNone,
+		},
+		{
+			Words:              []string{"deploy", "--"},
+			ExpectedCandidates: []string{"--config", "--force", "--region"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:              []string{"deploy", "--region", "us"},
+			ExpectedCandidates: []string{"us-east", "us-west"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:              []string{"deploy", "--region=us"},
+			ExpectedCandidates: []string{"--region=us-east", "--region=us-west"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:        []string{"deploy", "--config", ""},
+			ExpectedKind: cli.CompleteFiles,
+		},
+		{
+			// Flag values are not counted as positional arguments
+			Words:              []string{"deploy", "--region", "eu", "-f", "st"},
+			ExpectedCandidates: []string{"staging"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:        []string{"deploy", "staging", "--", "--app.yml"},
+			ExpectedKind: cli.CompleteFiles,
+		},
+		{
+			Words:        []string{"ssh", ""},
+			ExpectedKind: cli.CompleteHosts,
+		},
+		{
+			Words:        []string{"logs", ""},
+			ExpectedKind: cli.CompleteDefault,
+		},
+		{
+			Words:              []string{"help", ""},
+			ExpectedCandidates: []string{"deploy"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:              []string{"completion", "b"},
+			ExpectedCandidates: []string{"bash"},
+			ExpectedKind:       cli.CompleteValues,
+		},
+		{
+			Words:        []string{"unknown", ""},
+			ExpectedKind: cli.CompleteNone,
+		},
+	}
+
+	for _, testCase := range cases {
+		result := cli.Complete(app, testCase.Words)
+		if !reflect.DeepEqual(result.Candidates, testCase.ExpectedCandidates) {
+			t.Errorf("Expected %#v, found %#v with input %q", testCase.ExpectedCandidates, result.Candidates, testCase.Words)
+		}
+		if result.Kind != testCase.ExpectedKind {
+			t.Errorf("Expected kind %d, found %d with input %q", testCase.ExpectedKind, result.Kind, testCase.Words)
+		}
+	}
+
+	expectedOutput := "staging\n:values\n"
+	if output := cli.Complete(app, []string{"deploy", "st"}).String(); output != expectedOutput {
+		t.Errorf("Expected %q, found %q", expectedOutput, output)
+	}
+
+	expectedOutput = ":files yml yaml\n"
+	if output := cli.Complete(app, []string{"deploy", "staging", ""}).String(); output != expectedOutput {
+		t.Errorf("Expected %q, found %q", expectedOutput, output)
+	}
+
+	expectedOutput = ":files\n"
+	if output := cli.Complete(app, []string{"deploy", "--config", ""}).String(); output != expectedOutput {
+		t.Errorf("Expected %q, found %q", expectedOutput, output)
+	}
+}
+
+func TestCompletionDescriptions(t *testing.T) {
+	app := newCompletionTestApp()
+	app.Commands["deploy"].Annotations = map[string]string{"stability": "beta", "owner": "platform"}
+	app.Commands["logs"].Annotations = map[string]string{"stability": "stable"}
+	app.Commands["db"] = &cli.Command{
+		Summary: "manage the database",
+		Commands: map[string]*cli.Command{
+			"migrate": {Summary: "run\tdatabase\nmigrations"},
+		},
+	}
+
+	expectedOutput := "db\tmanage the database\n" +
+		"deploy\tdeploy an application (owner=platform, stability=beta)\n" +
+		"logs\tstability=stable\n" +
+		":values\n"
+	output := cli.Complete(app, []string{""}).String()
+	for _, line := range strings.SplitAfter(expectedOutput, "\n") {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected %q in %q", line, output)
+		}
+	}
+
+	// Descriptions never break the one candidate per line protocol
+	expectedOutput = "migrate\trun database migrations\n:values\n"
+	if output := cli.Complete(app, []string{"db", ""}).String(); output != expectedOutput {
+		t.Errorf("Expected %q, found %q", expectedOutput, output)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	app := newCompletionTestApp()
+
+	calls := map[string]string{
+		"bash":       "ship __complete",
+		"zsh":        "ship __complete",
+		"fish":       "ship __complete",
+		"powershell": "& 'ship' __complete",
+	}
+	for shell, call := range calls {
+		script, err := cli.CompletionScript(app, shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(script, call) {
+			t.Errorf("Expected %s script to call the __complete command:\n%s", shell, script)
+		}
+	}
+
+	_, err := cli.CompletionScript(app, "cmd")
+	expectedError := `unsupported shell "cmd", expected one of: bash, fish, powershell, zsh`
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Expected %q, found %v", expectedError, err)
+	}
+}
+
+type slowCompleter struct {
+	calls int
+}
+
+func (s *slowCompleter) Run(ctx *cli.Context, args []string) error {
+	return nil
+}
+
+func (s *slowCompleter) Complete(args []string) []string {
+	s.calls++
+	return []string{"web-1", "web-2", "worker-1"}
+}
+
+func TestCompletionCache(t *testing.T) {
+	cleanup, _ := redirectIO()
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "cli-completion-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("SHIP_CACHE_DIR", dir)
+	defer os.Unsetenv("SHIP_CACHE_DIR")
+
+	completer := &slowCompleter{}
+	app := &cli.CLI{
+		Name:       "ship",
+		Completion: true,
+		Commands: map[string]*cli.Command{
+			"ssh": {Runner: completer, CompletionTTL: time.Hour},
+		},
+	}
+
+	expected := []string{"web-1", "web-2"}
+	for i := 0; i < 2; i++ {
+		result := cli.Complete(app, []string{"ssh", "web"})
+		if !reflect.DeepEqual(result.Candidates, expected) {
+			t.Errorf("Expected %#v, found %#v", expected, result.Candidates)
+		}
+	}
+	if completer.calls != 1 {
+		t.Errorf("Expected completer to be called once, found %d", completer.calls)
+	}
+
+	// Different arguments are cached separately
+	cli.Complete(app, []string{"ssh", "--user", "admin", ""})
+	if completer.calls != 2 {
+		t.Errorf("Expected completer to be called twice, found %d", completer.calls)
+	}
+
+	if err := app.RunArgs([]string{"completion", "cache", "clear"}); err != nil {
+		t.Fatal(err)
+	}
+	cli.Complete(app, []string{"ssh", "web"})
+	if completer.calls != 3 {
+		t.Errorf("Expected cache to be cleared, found %d calls", completer.calls)
+	}
+}
+
+func TestStaleCompletion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "completion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_STATE_DIR", dir)
+	defer os.Unsetenv("SHIP_STATE_DIR")
+	defer os.Unsetenv("SHIP_COMPLETION_SCHEMA")
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:       "ship",
+		Completion: true,
+		Printer:    &cli.Printer{Stdout: stdout, Stderr: stderr, ASCII: true},
+		Commands: map[string]*cli.Command{
+			"deploy": {Run: func(args []string) error { return nil }},
+		},
+	}
+
+	script, err := cli.CompletionScript(app, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`SHIP_COMPLETION_SCHEMA=(bash:[0-9a-f]+) ship __complete`).FindStringSubmatch(script)
+	if match == nil {
+		t.Fatalf("Expected the script to pass its schema to __complete:\n%s", script)
+	}
+	current := match[1]
+
+	type TestCase struct {
+		Schema   string
+		Expected string
+	}
+
+	warning := "! Your bash completions were installed by an older version of ship. Reinstall them with 'ship completion bash'.\n"
+	cases := []TestCase{
+		// Completions from an up-to-date script, or run by hand, are fine
+		{current, ""},
+		{"", ""},
+		// The warning is shown once for an outdated script
+		{"bash:000000000000", warning},
+		{"bash:000000000000", ""},
+		{current, ""},
+		// and again if a different outdated script is used
+		{"bash:111111111111", warning},
+	}
+
+	for i, c := range cases {
+		os.Setenv("SHIP_COMPLETION_SCHEMA", c.Schema)
+		if err := app.RunArgs([]string{"__complete", "dep"}); err != nil {
+			t.Fatal(err)
+		}
+		if output := stdout.String(); output != "deploy\n:values\n" {
+			t.Errorf("%d: Expected completions, found %q", i, output)
+		}
+		if output := stderr.String(); output != "" {
+			t.Errorf("%d: Expected no output from __complete, found %q", i, output)
+		}
+		stdout.Reset()
+
+		if err := app.RunArgs([]string{"deploy"}); err != nil {
+			t.Fatal(err)
+		}
+		if output := stderr.String(); output != c.Expected {
+			t.Errorf("%d: Expected %q, found %q", i, c.Expected, output)
+		}
+		stderr.Reset()
+	}
+}
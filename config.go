@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// configFile is the name of the configuration file in the ConfigDir.
+const configFile = "config.json"
+
+// configVersionKey is the key in the configuration file that records its
+// format version. When CLI.ConfigVersion is set it is reserved and must not
+// be used by the program's own settings.
+const configVersionKey = "version"
+
+// Migration upgrades a configuration file by one version, for example by
+// renaming or restructuring settings. It receives the decoded JSON object and
+// modifies it in place; the version is updated by LoadConfig. See
+// CLI.ConfigMigrations.
+type Migration func(config map[string]interface{}) error
+
+// ConfigPath returns the path of the program's configuration file,
+// config.json in the ConfigDir.
+func (c *CLI) ConfigPath() (string, error) {
+	dir, err := c.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFile), nil
+}
+
+// LoadConfig decodes the program's configuration file into v, which is
+// typically a pointer to a struct. If the file does not exist, v is left
+// unchanged.
+//
+// If the file was written in an older format than ConfigVersion, the
+// ConfigMigrations are run in order to bring it up to date before it is
+// decoded. The original file is kept as a backup, such as config.json.v1.bak,
+// and the migrated file is saved so the migrations only run once. Files
+// written by a newer version of the program are rejected rather than risk
+// losing settings the program doesn't understand.
+func (c *CLI) LoadConfig(v interface{}) error {
+	path, err := c.ConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	config, err := decodeConfig(data)
+	if err != nil {
+		return c.Strings.errorf("failed to read config from %s: %s", path, err)
+	}
+	// Programs that don't set ConfigVersion may use "version" themselves
+	version := c.ConfigVersion
+	if c.ConfigVersion > 0 {
+		if version, err = configVersion(config); err != nil {
+			return c.Strings.errorf("failed to read config from %s: %s", path, err)
+		}
+	}
+	if version > c.ConfigVersion {
+		return c.Strings.errorf("config file %s is version %d, but this version of %s only supports up to version %d, try upgrading %s", path, version, c.Name, c.ConfigVersion, c.Name)
+	}
+
+	if version < c.ConfigVersion {
+		for from := version; from < c.ConfigVersion; from++ {
+			if migrate := c.ConfigMigrations[from]; migrate != nil {
+				if err := migrate(config); err != nil {
+					return c.Strings.errorf("failed to upgrade config file %s from version %d: %s", path, from, err)
+				}
+			}
+		}
+		config[configVersionKey] = c.ConfigVersion
+
+		backup, err := backupConfig(path, version, data)
+		if err != nil {
+			return err
+		}
+		if err := writeConfig(path, config); err != nil {
+			return err
+		}
+		c.Printer.Errf("%s %s\n", c.Printer.Symbols().Info, c.Strings.sprintf("Upgraded config file %s to version %d, the previous version was saved to %s", path, c.ConfigVersion, backup))
+	}
+
+	// Decode from the migrated object so v sees the current format
+	if data, err = json.Marshal(config); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return c.Strings.errorf("failed to read config from %s: %s", path, err)
+	}
+	return nil
+}
+
+// SaveConfig writes v, which must encode to a JSON object, to the program's
+// configuration file, recording the ConfigVersion so a later version of the
+// program knows which ConfigMigrations to run.
+func (c *CLI) SaveConfig(v interface{}) error {
+	path, err := c.ConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	config, err := decodeConfig(data)
+	if err != nil || config == nil {
+		return c.Strings.errorf("config must be a JSON object, found %s", data)
+	}
+	if c.ConfigVersion > 0 {
+		if _, ok := config[configVersionKey]; ok {
+			return c.Strings.errorf("config must not have a %q field, it is used to record the ConfigVersion", configVersionKey)
+		}
+		config[configVersionKey] = c.ConfigVersion
+	}
+	return writeConfig(path, config)
+}
+
+// decodeConfig decodes data, which should be a JSON object. Numbers are
+// decoded as json.Number rather than float64 so that large integers, such as
+// IDs, are saved unchanged when the file is migrated.
+func decodeConfig(data []byte) (map[string]interface{}, error) {
+	var config map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+	return config, nil
+}
+
+// configVersion returns the format version recorded in config, which is 0 if
+// there is none.
+func configVersion(config map[string]interface{}) (int, error) {
+	value, ok := config[configVersionKey]
+	if !ok {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid version %v", value)
+	}
+	version, err := number.Float64()
+	if err != nil || version < 0 || version != float64(int(version)) {
+		return 0, fmt.Errorf("invalid version %v", value)
+	}
+	return int(version), nil
+}
+
+// backupConfig saves data, the contents of the configuration file at path
+// before it was upgraded from version, next to it and returns the path of
+// the backup. Existing backups are never overwritten.
+func backupConfig(path string, version int, data []byte) (string, error) {
+	base := path + ".v" + strconv.Itoa(version) + ".bak"
+	backup := base
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			backup = base + "." + strconv.Itoa(i)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return backup, f.Close()
+	}
+}
+
+// writeConfig replaces the configuration file at path with config. The file
+// is written to a temporary file first so it is never left half-written.
+func writeConfig(path string, config map[string]interface{}) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := ioutil.WriteFile(temp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+type shipConfig struct {
+	Region  string   `json:"region"`
+	Targets []string `json:"targets"`
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_CONFIG_DIR", dir)
+	defer os.Unsetenv("SHIP_CONFIG_DIR")
+	path := filepath.Join(dir, "config.json")
+
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:          "ship",
+		Printer:       &cli.Printer{Stderr: stderr, ASCII: true},
+		ConfigVersion: 2,
+		ConfigMigrations: map[int]cli.Migration{
+			// Version 1 added the version field
+			0: nil,
+			// Version 2 replaced target with a list of targets
+			1: func(config map[string]interface{}) error {
+				if target, ok := config["target"]; ok {
+					config["targets"] = []interface{}{target}
+					delete(config, "target")
+				}
+				return nil
+			},
+		},
+	}
+
+	// A missing file leaves the defaults alone
+	config := shipConfig{Region: "us-east"}
+	if err := app.LoadConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Region != "us-east" {
+		t.Errorf("Expected default region, found %q", config.Region)
+	}
+
+	type TestCase struct {
+		Name     string
+		File     string
+		Expected shipConfig
+		Backup   string
+	}
+
+	cases := []TestCase{
+		{
+			"unversioned",
+			`{"region": "eu", "target": "web"}`,
+			shipConfig{Region: "eu", Targets: []string{"web"}},
+			"config.json.v0.bak",
+		},
+		{
+			"version 1",
+			`{"version": 1, "region": "eu", "target": "web"}`,
+			shipConfig{Region: "eu", Targets: []string{"web"}},
+			"config.json.v1.bak",
+		},
+		{
+			// Existing backups are kept
+			"version 1 again",
+			`{"version": 1, "region": "us-west", "target": "api"}`,
+			shipConfig{Region: "us-west", Targets: []string{"api"}},
+			"config.json.v1.bak.1",
+		},
+		{
+			"current",
+			`{"version": 2, "region": "eu", "targets": ["web", "api"]}`,
+			shipConfig{Region: "eu", Targets: []string{"web", "api"}},
+			"",
+		},
+	}
+
+	for _, c := range cases {
+		if err := ioutil.WriteFile(path, []byte(c.File), 0600); err != nil {
+			t.Fatal(err)
+		}
+		stderr.Reset()
+
+		var config shipConfig
+		if err := app.LoadConfig(&config); err != nil {
+			t.Errorf("%s: %s", c.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(config, c.Expected) {
+			t.Errorf("%s: Expected %+v, found %+v", c.Name, c.Expected, config)
+		}
+
+		if c.Backup == "" {
+			if stderr.Len() > 0 {
+				t.Errorf("%s: Expected no output, found %q", c.Name, stderr.String())
+			}
+			continue
+		}
+
+		backup, err := ioutil.ReadFile(filepath.Join(dir, c.Backup))
+		if err != nil || string(backup) != c.File {
+			t.Errorf("%s: Expected backup %s to contain %q, found %q (%v)", c.Name, c.Backup, c.File, backup, err)
+		}
+		expected := fmt.Sprintf("* Upgraded config file %s to version 2, the previous version was saved to %s\n", path, filepath.Join(dir, c.Backup))
+		if output := stderr.String(); output != expected {
+			t.Errorf("%s: Expected %q, found %q", c.Name, expected, output)
+		}
+
+		// The migrated file is saved so the migration only runs once
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"version": 2`) || strings.Contains(string(data), `"target"`) {
+			t.Errorf("%s: Expected the file to be migrated, found:\n%s", c.Name, data)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_CONFIG_DIR", dir)
+	defer os.Unsetenv("SHIP_CONFIG_DIR")
+	path := filepath.Join(dir, "config.json")
+
+	app := &cli.CLI{
+		Name:          "ship",
+		Printer:       &cli.Printer{Stderr: &bytes.Buffer{}},
+		ConfigVersion: 2,
+		ConfigMigrations: map[int]cli.Migration{
+			1: func(config map[string]interface{}) error {
+				return fmt.Errorf("unknown target %v", config["target"])
+			},
+		},
+	}
+
+	type TestCase struct {
+		File     string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{
+			`{"version": 3}`,
+			"config file " + path + " is version 3, but this version of ship only supports up to version 2, try upgrading ship",
+		},
+		{
+			`{"version": "two"}`,
+			"failed to read config from " + path + ": invalid version two",
+		},
+		{
+			`{"version": 1, "target": "db"}`,
+			"failed to upgrade config file " + path + " from version 1: unknown target db",
+		},
+	}
+
+	for _, c := range cases {
+		if err := ioutil.WriteFile(path, []byte(c.File), 0600); err != nil {
+			t.Fatal(err)
+		}
+		var config shipConfig
+		if err := app.LoadConfig(&config); err == nil || err.Error() != c.Expected {
+			t.Errorf("Expected %q, found %v", c.Expected, err)
+		}
+
+		// The file is left alone when it can't be loaded
+		if data, err := ioutil.ReadFile(path); err != nil || string(data) != c.File {
+			t.Errorf("Expected %s to be unchanged, found %q", path, data)
+		}
+	}
+}
+
+func TestSaveConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_CONFIG_DIR", filepath.Join(dir, "ship"))
+	defer os.Unsetenv("SHIP_CONFIG_DIR")
+
+	app := &cli.CLI{Name: "ship", ConfigVersion: 2}
+	if err := app.SaveConfig(shipConfig{Region: "eu", Targets: []string{"web"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "ship", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "region": "eu",
+  "targets": [
+    "web"
+  ],
+  "version": 2
+}
+`
+	if string(data) != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Found ---\n%s\n", expected, data)
+	}
+
+	var config shipConfig
+	if err := app.LoadConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Region != "eu" || len(config.Targets) != 1 {
+		t.Errorf("Expected the saved config, found %+v", config)
+	}
+
+	if err := app.SaveConfig([]string{"web"}); err == nil {
+		t.Error("Expected an error saving a config that is not an object")
+	}
+
+	// Errors are translated
+	app.Strings = cli.Strings{"config must be a JSON object, found %s": "la configuration doit être un objet JSON, trouvé %s"}
+	expected = `la configuration doit être un objet JSON, trouvé ["web"]`
+	if err := app.SaveConfig([]string{"web"}); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+}
+
+func TestUnversionedConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_CONFIG_DIR", dir)
+	defer os.Unsetenv("SHIP_CONFIG_DIR")
+
+	// Without a ConfigVersion, "version" belongs to the program
+	type TestConfig struct {
+		Version string `json:"version"`
+	}
+	app := &cli.CLI{Name: "ship"}
+	if err := app.SaveConfig(TestConfig{Version: "1.4.0"}); err != nil {
+		t.Fatal(err)
+	}
+	var config TestConfig
+	if err := app.LoadConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Version != "1.4.0" {
+		t.Errorf("Expected %q, found %q", "1.4.0", config.Version)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"version": 3}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var numbered struct {
+		Version int `json:"version"`
+	}
+	if err := app.LoadConfig(&numbered); err != nil || numbered.Version != 3 {
+		t.Errorf("Expected version 3, found %d (%v)", numbered.Version, err)
+	}
+}
+
+func TestLoadConfigLargeNumbers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_CONFIG_DIR", dir)
+	defer os.Unsetenv("SHIP_CONFIG_DIR")
+	path := filepath.Join(dir, "config.json")
+
+	// 2^53 + 1 can't be represented by a float64
+	if err := ioutil.WriteFile(path, []byte(`{"version": 1, "account": 9007199254740993}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	app := &cli.CLI{Name: "ship", Printer: &cli.Printer{Stderr: &bytes.Buffer{}}, ConfigVersion: 2}
+
+	var config struct {
+		Account int64 `json:"account"`
+	}
+	if err := app.LoadConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Account != 9007199254740993 {
+		t.Errorf("Expected %d, found %d", int64(9007199254740993), config.Account)
+	}
+
+	// The migrated file keeps the number too
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"account": 9007199254740993`) {
+		t.Errorf("Expected the account to be saved unchanged, found:\n%s", data)
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Context carries everything a command needs to do its work, so command
+// implementations do not need to rely on globals such as os.Args or os.Stdout.
+// It is passed to Command.RunContext.
+//
+// Context embeds a context.Context that is canceled when the program receives
+// an interrupt (Ctrl-C) or termination signal, so long-running commands can
+// clean up and exit gracefully. A second interrupt terminates the program
+// immediately. When the program is run with RunArgsContext, the Context is
+// also canceled when the context passed to it is. Because of the embedding, a
+// *Context may be passed anywhere a context.Context is expected.
+type Context struct {
+	context.Context
+
+	// CLI is the application that is running the command.
+	CLI *CLI
+
+	// Name is the name the command was invoked with.
+	Name string
+
+	// Command is the command being run.
+	Command *Command
+
+	// Args holds the positional arguments passed to the command, after flags
+	// have been parsed.
+	Args []string
+
+	// Flags holds the command's parsed flags. It is the same FlagSet as
+	// Command.Flags and may be nil.
+	Flags *flag.FlagSet
+
+	// Printer should be used for output. It is the same as CLI.Printer.
+	Printer *Printer
+
+	// Stdin, Stdout, and Stderr are the standard streams for the command.
+	// Stdout and Stderr write through Printer.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// newContext creates a Context for the specified command. The returned cancel
+// function must be called when the command has finished to release resources
+// associated with signal handling.
+func (c *CLI) newContext(name string, command *Command, args []string) (ctx *Context, cancel func()) {
+	base := c.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	parent, cancelParent := context.WithCancel(base)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancelParent()
+			// Restore default handling so a second signal exits immediately
+			signal.Stop(signals)
+		case <-parent.Done():
+		}
+	}()
+
+	var stdin io.Reader = os.Stdin
+	if c.Printer.session != nil {
+		stdin = &recordedInput{r: os.Stdin, session: c.Printer.session}
+	}
+
+	ctx = &Context{
+		Context: parent,
+		CLI:     c,
+		Name:    name,
+		Command: command,
+		Args:    args,
+		Flags:   command.Flags,
+		Printer: c.Printer,
+		Stdin:   stdin,
+		Stdout:  c.Printer.StdoutWriter(),
+		Stderr:  c.Printer.StderrWriter(),
+	}
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancelParent()
+	}
+}
+
+// StdinIsPiped reports whether data is being piped or redirected into the
+// command's stdin. See the StdinIsPiped function for details.
+func (ctx *Context) StdinIsPiped() bool {
+	stdin := ctx.Stdin
+	if recorded, ok := stdin.(*recordedInput); ok {
+		stdin = recorded.r
+	}
+	if f, ok := stdin.(*os.File); ok && f != os.Stdin {
+		return fileIsPiped(f)
+	}
+	if stdin != os.Stdin {
+		// Any other reader was supplied programmatically, so it's not a
+		// terminal and we should read from it.
+		return true
+	}
+	return StdinIsPiped()
+}
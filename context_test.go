@@ -0,0 +1,96 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestRunContext(t *testing.T) {
+	var loud bool
+	flags := flag.NewFlagSet("greet", flag.ContinueOnError)
+	flags.BoolVar(&loud, "loud", false, "shout the greeting")
+
+	stdout := &bytes.Buffer{}
+	var received *cli.Context
+
+	app := &cli.CLI{
+		Name: "testapp",
+		Printer: &cli.Printer{
+			Stdout: stdout,
+		},
+		Commands: map[string]*cli.Command{
+			"greet": {
+				Flags: flags,
+				RunContext: func(ctx *cli.Context) error {
+					received = ctx
+					ctx.Printer.Outf("hello %s\n", ctx.Args[0])
+					return ctx.Err()
+				},
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"greet", "--loud", "world"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if received == nil {
+		t.Fatal("Expected RunContext to be called")
+	}
+	if received.CLI != app || received.Name != "greet" || received.Command != app.Commands["greet"] {
+		t.Errorf("Expected context to reference the CLI and command, found %#v", received)
+	}
+	if !reflect.DeepEqual(received.Args, []string{"world"}) {
+		t.Errorf("Expected %#v, found %#v", []string{"world"}, received.Args)
+	}
+	if received.Flags.Lookup("loud").Value.String() != "true" {
+		t.Error("Expected parsed flags to be available")
+	}
+	if stdout.String() != "hello world\n" {
+		t.Errorf("Expected %q, found %q", "hello world\n", stdout.String())
+	}
+
+	// The context should be canceled after the command returns
+	select {
+	case <-received.Done():
+	default:
+		t.Error("Expected context to be canceled after the command finished")
+	}
+}
+
+func TestContextStdinIsPiped(t *testing.T) {
+	ctx := &cli.Context{Stdin: &bytes.Buffer{}}
+	if !ctx.StdinIsPiped() {
+		t.Error("Expected programmatic stdin to be treated as piped")
+	}
+}
+
+func TestRunArgsContext(t *testing.T) {
+	var received []string
+	app := &cli.CLI{
+		Name: "testapp",
+		Commands: map[string]*cli.Command{
+			"wait": {
+				RunContext: func(ctx *cli.Context) error {
+					received = ctx.Args
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := app.RunArgsContext(ctx, []string{"wait", "forever"}); err != context.Canceled {
+		t.Errorf("Expected %v, found %v", context.Canceled, err)
+	}
+	if !reflect.DeepEqual(received, []string{"forever"}) {
+		t.Errorf("Expected %#v, found %#v", []string{"forever"}, received)
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cooldownFile is the name of the file in the StateDir that records when each
+// command with a Cooldown last ran successfully.
+const cooldownFile = "cooldowns.json"
+
+// loadCooldowns reads the times commands last ran from the StateDir.
+func (c *CLI) loadCooldowns() (map[string]time.Time, error) {
+	cooldowns := map[string]time.Time{}
+	dir, err := c.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, cooldownFile))
+	if os.IsNotExist(err) {
+		return cooldowns, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cooldowns); err != nil {
+		return nil, err
+	}
+	return cooldowns, nil
+}
+
+// checkCooldown returns an error if the command at path last ran less than
+// its Cooldown ago, unless force is set. Errors reading the state file are
+// ignored so they can't prevent the program from working.
+func (c *CLI) checkCooldown(path string, command *Command, force bool) error {
+	if command.Cooldown <= 0 || force {
+		return nil
+	}
+	cooldowns, err := c.loadCooldowns()
+	if err != nil {
+		return nil
+	}
+	last, ok := cooldowns[path]
+	if !ok {
+		return nil
+	}
+	elapsed := time.Since(last)
+	if elapsed < 0 || elapsed >= command.Cooldown {
+		return nil
+	}
+	remaining := (command.Cooldown - elapsed).Round(time.Second)
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+	return c.Strings.errorf("'%s' ran %s ago and can run again in %s, pass --force to run it now", path, elapsed.Round(time.Second), remaining)
+}
+
+// recordCooldown saves the time the command at path ran, if it has a
+// Cooldown. Failing to save is not fatal, so errors are reported but
+// otherwise ignored.
+func (c *CLI) recordCooldown(path string, command *Command) {
+	if command.Cooldown <= 0 {
+		return
+	}
+	cooldowns, err := c.loadCooldowns()
+	if err != nil {
+		cooldowns = map[string]time.Time{}
+	}
+	cooldowns[path] = time.Now().UTC()
+
+	dir, err := c.StateDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0755)
+	}
+	var data []byte
+	if err == nil {
+		data, err = json.MarshalIndent(cooldowns, "", "  ")
+	}
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(dir, cooldownFile), append(data, '\n'), 0644)
+	}
+	if err != nil {
+		c.Printer.Errf("Unable to save when '%s' last ran: %s\n", path, err)
+	}
+}
@@ -0,0 +1,70 @@
+package cli_test
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestCooldown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cooldown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("SHIP_STATE_DIR", dir)
+	defer os.Unsetenv("SHIP_STATE_DIR")
+
+	runs := 0
+	fail := false
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	verbose := flags.Bool("verbose", false, "")
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"sync": {
+				Flags:    flags,
+				Cooldown: time.Hour,
+				Run: func(args []string) error {
+					runs++
+					if fail {
+						return errors.New("sync failed")
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	// Failed runs don't start the cooldown
+	fail = true
+	if err := app.RunArgs([]string{"sync"}); err == nil {
+		t.Error("Expected sync to fail")
+	}
+	fail = false
+	if err := app.RunArgs([]string{"sync"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = app.RunArgs([]string{"sync"})
+	if err == nil || !strings.HasPrefix(err.Error(), "'sync' ran 0s ago and can run again in 1h0m0s, pass --force") {
+		t.Errorf("Expected cooldown error, found %v", err)
+	}
+	if runs != 2 {
+		t.Errorf("Expected 2 runs, found %d", runs)
+	}
+
+	if err := app.RunArgs([]string{"sync", "--force", "--verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 3 || !*verbose {
+		t.Errorf("Expected --force to run sync with its flags, found %d runs", runs)
+	}
+}
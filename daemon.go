@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stopTimeout is how long the stop command waits for a service to exit.
+const stopTimeout = 10 * time.Second
+
+// exitNotRunning is the status of the status command when the service is not
+// running, following the LSB init script convention.
+const exitNotRunning = 3
+
+// PIDFile records the process ID of a long-running service, so other
+// invocations of the program can find it and only one copy runs at a time.
+type PIDFile struct {
+	// Path is the location of the file, such as a file in the StateDir.
+	Path string
+
+	// Strings translates the errors returned by Acquire and Status. It is
+	// usually set to the program's CLI.Strings.
+	Strings Strings
+}
+
+// Acquire writes the current process ID to the file. It fails if the file
+// names another process that is still running. A file left behind by a
+// process that has exited is replaced.
+func (p *PIDFile) Acquire() error {
+	if err := os.MkdirAll(filepath.Dir(p.Path), 0755); err != nil {
+		return err
+	}
+
+	for {
+		f, err := os.OpenFile(p.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+
+		pid, running, err := p.Status()
+		if err != nil {
+			return err
+		}
+		if running {
+			return p.Strings.errorf("already running (pid %d)", pid)
+		}
+		// Stale, so remove it and try again
+		if err := os.Remove(p.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+}
+
+// Release removes the file if it belongs to the current process.
+func (p *PIDFile) Release() error {
+	pid, err := p.read()
+	if err != nil || pid != os.Getpid() {
+		return err
+	}
+	return os.Remove(p.Path)
+}
+
+// Status returns the process ID in the file and whether that process is
+// running. If there is no file, pid is 0.
+func (p *PIDFile) Status() (pid int, running bool, err error) {
+	pid, err = p.read()
+	if err != nil || pid == 0 {
+		return pid, false, err
+	}
+	return pid, processRunning(pid), nil
+}
+
+// read returns the process ID in the file, or 0 if there is no file.
+func (p *PIDFile) read() (int, error) {
+	data, err := ioutil.ReadFile(p.Path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, p.Strings.errorf("invalid pid file %s", p.Path)
+	}
+	return pid, nil
+}
+
+// Detached reports whether the program was started in the background by
+// Detach.
+func Detached(c *CLI) bool {
+	return envEnabled(c.envVar("DETACHED"))
+}
+
+// Detach starts the program again in the background with the same arguments,
+// in a new session without a controlling terminal, and returns the process ID
+// of the new process. The new process's output is appended to logPath, or
+// discarded if logPath is empty.
+//
+// A command that should run as a service calls Detach unless Detached reports
+// that it is already running in the background, in which case it acquires
+// its PIDFile and does its work:
+//
+//	if !cli.Detached(ctx.CLI) {
+//		pid, err := cli.Detach(ctx.CLI, logPath)
+//		...
+//	}
+//
+// Detaching is not supported on every platform.
+func Detach(c *CLI, logPath string) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	attr, err := detachAttr()
+	if err != nil {
+		return 0, err
+	}
+
+	output := os.DevNull
+	flags := os.O_WRONLY
+	if logPath != "" {
+		output = logPath
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	log, err := os.OpenFile(output, flags, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer log.Close()
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = c.childEnv(c.envVar("DETACHED") + "=1")
+	cmd.Stdout = log
+	cmd.Stderr = log
+	cmd.SysProcAttr = attr
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	return pid, cmd.Process.Release()
+}
+
+// DaemonCommands returns "status" and "stop" commands for a service that
+// records its process ID in pidFile, to be added to the program's Commands.
+// status exits with status 3 if the service is not running. stop asks the
+// service to exit and waits for it to do so.
+func DaemonCommands(pidFile *PIDFile) map[string]*Command {
+	return map[string]*Command{
+		"status": {
+			Summary:   "Show whether the service is running",
+			ExitCodes: map[int]string{exitNotRunning: "the service is not running"},
+			RunContext: func(ctx *Context) error {
+				pid, running, err := pidFile.Status()
+				if err != nil {
+					return err
+				}
+				if !running {
+					return WithExitCode(ctx.CLI.Strings.errorf("not running"), exitNotRunning)
+				}
+				ctx.Printer.Outf("running (pid %d)\n", pid)
+				return nil
+			},
+		},
+		"stop": {
+			Summary: "Stop the service",
+			RunContext: func(ctx *Context) error {
+				pid, running, err := pidFile.Status()
+				if err != nil {
+					return err
+				}
+				if !running {
+					ctx.Printer.Err("Not running\n")
+					return nil
+				}
+				if err := stopProcess(pid); err != nil {
+					return err
+				}
+
+				deadline := time.Now().Add(stopTimeout)
+				for processRunning(pid) {
+					if time.Now().After(deadline) {
+						return ctx.CLI.Strings.errorf("process %d did not stop after %s", pid, stopTimeout)
+					}
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(100 * time.Millisecond):
+					}
+				}
+				ctx.Printer.Errf("Stopped (pid %d)\n", pid)
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,38 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows && !plan9
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processRunning reports whether the process with the specified ID exists,
+// by sending it signal 0, because os.FindProcess always succeeds on these
+// platforms. Where signals aren't supported, such as WebAssembly, it can't be
+// known, so the process is assumed to have exited: a stale pid or lock file
+// must be replaced rather than block the program forever.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// stopProcess terminates the process.
+func stopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
+
+// detachAttr returns an error because detaching is not supported on this
+// platform.
+func detachAttr() (*syscall.SysProcAttr, error) {
+	return nil, errors.New("running in the background is not supported on this platform")
+}
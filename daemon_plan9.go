@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// processRunning reports whether the process with the specified ID exists,
+// by checking for its directory in /proc.
+func processRunning(pid int) bool {
+	_, err := os.Stat("/proc/" + strconv.Itoa(pid))
+	return err == nil
+}
+
+// stopProcess terminates the process.
+func stopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
+
+// detachAttr returns an error because detaching is not supported on this
+// platform.
+func detachAttr() (*syscall.SysProcAttr, error) {
+	return nil, errors.New("running in the background is not supported on this platform")
+}
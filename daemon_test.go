@@ -0,0 +1,97 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestPIDFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-pidfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pidFile := &cli.PIDFile{Path: filepath.Join(dir, "run", "ship.pid")}
+	if pid, running, err := pidFile.Status(); pid != 0 || running || err != nil {
+		t.Errorf("Expected no pid before Acquire, found %d %v %v", pid, running, err)
+	}
+
+	if err := pidFile.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	if pid, running, err := pidFile.Status(); pid != os.Getpid() || !running || err != nil {
+		t.Errorf("Expected pid %d to be running, found %d %v %v", os.Getpid(), pid, running, err)
+	}
+
+	// We're still running, so a second Acquire should fail
+	expected := fmt.Sprintf("already running (pid %d)", os.Getpid())
+	if err := pidFile.Acquire(); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+
+	if err := pidFile.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(pidFile.Path); !os.IsNotExist(err) {
+		t.Error("Expected Release to remove the pid file")
+	}
+
+	// A file left by a process that exited is replaced
+	if err := ioutil.WriteFile(pidFile.Path, []byte("999999999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := pidFile.Acquire(); err != nil {
+		t.Errorf("Expected a stale pid file to be replaced, found %s", err)
+	}
+}
+
+func TestDaemonCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pidFile := &cli.PIDFile{Path: filepath.Join(dir, "ship.pid")}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:     "ship",
+		Printer:  &cli.Printer{Stdout: stdout, Stderr: stderr},
+		Commands: cli.DaemonCommands(pidFile),
+	}
+
+	err = app.RunArgs([]string{"status"})
+	if err == nil || err.Error() != "not running" || cli.ExitCode(err) != 3 {
+		t.Errorf("Expected 'not running' with status 3, found %v", err)
+	}
+	if err := app.RunArgs([]string{"stop"}); err != nil || stderr.String() != "Not running\n" {
+		t.Errorf("Expected %q, found %q (%v)", "Not running\n", stderr.String(), err)
+	}
+
+	// A pid file left behind by a process that has exited is not running
+	if err := ioutil.WriteFile(pidFile.Path, []byte("999999999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = app.RunArgs([]string{"status"})
+	if err == nil || err.Error() != "not running" || cli.ExitCode(err) != 3 {
+		t.Errorf("Expected a stale pid file to be 'not running' with status 3, found %v", err)
+	}
+
+	if err := pidFile.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	defer pidFile.Release()
+
+	expected := fmt.Sprintf("running (pid %d)\n", os.Getpid())
+	if err := app.RunArgs([]string{"status"}); err != nil || stdout.String() != expected {
+		t.Errorf("Expected %q, found %q (%v)", expected, stdout.String(), err)
+	}
+}
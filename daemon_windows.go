@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+	detachedProcess                = 0x00000008
+)
+
+// processRunning reports whether the process with the specified ID exists.
+func processRunning(pid int) bool {
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// stopProcess terminates the process. Windows has no equivalent of SIGTERM for
+// processes without a console.
+func stopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
+
+// detachAttr starts the process without a console, in its own process group
+// so it doesn't receive Ctrl-C from the terminal.
+func detachAttr() (*syscall.SysProcAttr, error) {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}, nil
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffLine is a single line in an edit script. Kind is one of ' ' (unchanged),
+// '-' (removed), or '+' (added).
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// Diff returns a unified diff between old and new, suitable for previewing
+// changes to a file before they are made. The optional labels name the old and
+// new versions in the diff header, and default to "old" and "new". Diff returns
+// an empty string if old and new are identical.
+//
+// Diff does not use color, since it can't know where the diff will be written.
+// Use Printer.Diff to write a colored diff to stdout.
+//
+// Diff compares every line of old with every line of new, so it is intended
+// for reasonably-sized text such as configuration files.
+func Diff(old, new []byte, labels ...string) string {
+	return renderDiff(old, new, false, labels)
+}
+
+// Diff writes a unified diff between old and new to stdout, as described by
+// the Diff function. Removed lines are colored red and added lines are
+// colored green, unless the Printer removes styling because stdout is not a
+// terminal or color is disabled.
+func (p *Printer) Diff(old, new []byte, labels ...string) {
+	p.Out(renderDiff(old, new, true, labels))
+}
+
+// renderDiff formats the diff between old and new, styling it if color is set.
+func renderDiff(old, new []byte, color bool, labels []string) string {
+	oldLabel, newLabel := "old", "new"
+	if len(labels) > 0 {
+		oldLabel = labels[0]
+	}
+	if len(labels) > 1 {
+		newLabel = labels[1]
+	}
+
+	edits := diffLines(splitLines(string(old)), splitLines(string(new)))
+
+	style := func(s, text string) string {
+		if color {
+			return colorize(s, text)
+		}
+		return text
+	}
+
+	output := ""
+	for _, hunk := range diffHunks(edits) {
+		if output == "" {
+			output += style(ansiBold, "--- "+oldLabel) + "\n"
+			output += style(ansiBold, "+++ "+newLabel) + "\n"
+		}
+		output += style(ansiCyan, hunk.header()) + "\n"
+		for _, line := range hunk.lines {
+			text := string(line.kind) + line.text
+			switch line.kind {
+			case '-':
+				text = style(ansiRed, text)
+			case '+':
+				text = style(ansiGreen, text)
+			}
+			output += text + "\n"
+		}
+	}
+
+	return output
+}
+
+// splitLines splits text into lines. A trailing newline does not produce an
+// additional empty line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes an edit script that transforms a into b using the longest
+// common subsequence of lines.
+func diffLines(a, b []string) []diffLine {
+	// Lines at the beginning and end are often identical, so we'll skip them
+	// to keep the LCS table small.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []diffLine
+	for _, line := range a[:prefix] {
+		edits = append(edits, diffLine{' ', line})
+	}
+
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] holds the length of the longest common subsequence of x[i:]
+	// and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			edits = append(edits, diffLine{' ', x[i]})
+			i++
+			j++
+		case j >= len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, diffLine{'-', x[i]})
+			i++
+		default:
+			edits = append(edits, diffLine{'+', y[j]})
+			j++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, diffLine{' ', line})
+	}
+
+	return edits
+}
+
+// diffHunk is a group of nearby changes along with surrounding context.
+type diffHunk struct {
+	oldStart, oldCount int
+	newStart, newCount int
+	lines              []diffLine
+}
+
+func (h diffHunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.oldStart, h.oldCount), hunkRange(h.newStart, h.newCount))
+}
+
+// hunkRange formats a line range the same way as GNU diff.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range refers to the line before the change
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// diffHunks groups an edit script into hunks, keeping up to diffContext
+// unchanged lines around each change.
+func diffHunks(edits []diffLine) []diffHunk {
+	var hunks []diffHunk
+
+	idx := 0
+	for idx < len(edits) {
+		// Find the next change
+		for idx < len(edits) && edits[idx].kind == ' ' {
+			idx++
+		}
+		if idx == len(edits) {
+			break
+		}
+
+		start := idx - diffContext
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend the hunk until we see more than twice the context length of
+		// unchanged lines, which would leave a gap between two hunks.
+		end := idx
+		unchanged := 0
+		for end < len(edits) && unchanged <= 2*diffContext {
+			if edits[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			end++
+		}
+		if unchanged > diffContext {
+			end -= unchanged - diffContext
+		}
+
+		hunk := diffHunk{lines: edits[start:end]}
+
+		// Count lines from the start of the file to find where the hunk begins
+		hunk.oldStart, hunk.newStart = 1, 1
+		for _, line := range edits[:start] {
+			if line.kind != '+' {
+				hunk.oldStart++
+			}
+			if line.kind != '-' {
+				hunk.newStart++
+			}
+		}
+		for _, line := range hunk.lines {
+			if line.kind != '+' {
+				hunk.oldCount++
+			}
+			if line.kind != '-' {
+				hunk.newCount++
+			}
+		}
+
+		hunks = append(hunks, hunk)
+		idx = end
+	}
+
+	return hunks
+}
@@ -0,0 +1,104 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestDiff(t *testing.T) {
+	old := strings.Join([]string{
+		"[server]",
+		"host = localhost",
+		"port = 8080",
+		"",
+		"[database]",
+		"driver = sqlite",
+		"path = data.db",
+		"pool = 4",
+		"timeout = 30",
+		"retries = 3",
+		"",
+		"[logging]",
+		"level = info",
+	}, "\n") + "\n"
+
+	new := strings.Join([]string{
+		"[server]",
+		"host = 0.0.0.0",
+		"port = 8080",
+		"",
+		"[database]",
+		"driver = sqlite",
+		"path = data.db",
+		"pool = 4",
+		"timeout = 30",
+		"retries = 3",
+		"",
+		"[logging]",
+		"level = debug",
+		"format = json",
+	}, "\n") + "\n"
+
+	// Tests don't run in a terminal so we should not see any color codes
+	expectedOutput := `--- config.ini
++++ config.ini (edited)
+@@ -1,5 +1,5 @@
+ [server]
+-host = localhost
++host = 0.0.0.0
+ port = 8080
+ 
+ [database]
+@@ -10,4 +10,5 @@
+ retries = 3
+ 
+ [logging]
+-level = info
++level = debug
++format = json
+`
+
+	output := cli.Diff([]byte(old), []byte(new), "config.ini", "config.ini (edited)")
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	if output := cli.Diff([]byte(old), []byte(old)); output != "" {
+		t.Errorf("Expected no output for identical input, found %q", output)
+	}
+
+	expectedOutput = `--- old
++++ new
+@@ -0,0 +1,2 @@
++hello
++world
+`
+	output = cli.Diff(nil, []byte("hello\nworld\n"))
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+}
+
+func TestPrinterDiff(t *testing.T) {
+	type TestCase struct {
+		NoColor  bool
+		Expected string
+	}
+
+	cases := []TestCase{
+		{false, "\x1b[1m--- old\x1b[0m\n\x1b[1m+++ new\x1b[0m\n\x1b[36m@@ -1 +1 @@\x1b[0m\n\x1b[31m-a\x1b[0m\n\x1b[32m+b\x1b[0m\n"},
+		{true, "--- old\n+++ new\n@@ -1 +1 @@\n-a\n+b\n"},
+	}
+
+	for _, c := range cases {
+		stdout := &bytes.Buffer{}
+		printer := &cli.Printer{Stdout: stdout, NoColor: c.NoColor}
+		printer.Diff([]byte("a\n"), []byte("b\n"))
+		if output := stdout.String(); output != c.Expected {
+			t.Errorf("NoColor=%v: Expected %q, found %q", c.NoColor, c.Expected, output)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// CacheDir returns the directory where the program may store cached data that
+// can be safely deleted, such as completion results. It is a directory named
+// after the program inside the user's cache directory (see os.UserCacheDir),
+// and may be overridden by setting the PROG_CACHE_DIR environment variable.
+// The directory is not created.
+func (c *CLI) CacheDir() (string, error) {
+	if dir := os.Getenv(c.envVar("CACHE_DIR")); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, c.Name), nil
+}
+
+// ConfigDir returns the directory where the program's user configuration is
+// stored, such as hook scripts. It is a directory named after the program
+// inside the user's configuration directory (see os.UserConfigDir), and may be
+// overridden by setting the PROG_CONFIG_DIR environment variable. The
+// directory is not created.
+func (c *CLI) ConfigDir() (string, error) {
+	if dir := os.Getenv(c.envVar("CONFIG_DIR")); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, c.Name), nil
+}
+
+// StateDir returns the directory where the program keeps state that should
+// persist between runs but is not configuration, such as the last version
+// that was run. On Unix systems it follows the XDG base directory
+// specification, defaulting to ~/.local/state; on Windows it is inside the
+// local application data directory, and on macOS it is inside Application
+// Support. It may be overridden by setting the PROG_STATE_DIR environment
+// variable. The directory is not created.
+func (c *CLI) StateDir() (string, error) {
+	if dir := os.Getenv(c.envVar("STATE_DIR")); dir != "" {
+		return dir, nil
+	}
+
+	var dir string
+	var err error
+	switch runtime.GOOS {
+	case "windows":
+		dir, err = os.UserCacheDir()
+	case "darwin", "ios", "plan9":
+		dir, err = os.UserConfigDir()
+	default:
+		if dir = os.Getenv("XDG_STATE_HOME"); !filepath.IsAbs(dir) {
+			dir, err = os.UserHomeDir()
+			dir = filepath.Join(dir, ".local", "state")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, c.Name), nil
+}
@@ -0,0 +1,62 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestCacheDir(t *testing.T) {
+	app := &cli.CLI{Name: "prog"}
+
+	if userDir, err := os.UserCacheDir(); err == nil {
+		expected := filepath.Join(userDir, "prog")
+		if dir, err := app.CacheDir(); err != nil || dir != expected {
+			t.Errorf("Expected %q, found %q (%v)", expected, dir, err)
+		}
+	}
+
+	os.Setenv("PROG_CACHE_DIR", "/tmp/prog-cache")
+	defer os.Unsetenv("PROG_CACHE_DIR")
+	if dir, _ := app.CacheDir(); dir != "/tmp/prog-cache" {
+		t.Errorf("Expected %q, found %q", "/tmp/prog-cache", dir)
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	app := &cli.CLI{Name: "prog"}
+
+	if userDir, err := os.UserConfigDir(); err == nil {
+		expected := filepath.Join(userDir, "prog")
+		if dir, err := app.ConfigDir(); err != nil || dir != expected {
+			t.Errorf("Expected %q, found %q (%v)", expected, dir, err)
+		}
+	}
+
+	os.Setenv("PROG_CONFIG_DIR", "/tmp/prog-config")
+	defer os.Unsetenv("PROG_CONFIG_DIR")
+	if dir, _ := app.ConfigDir(); dir != "/tmp/prog-config" {
+		t.Errorf("Expected %q, found %q", "/tmp/prog-config", dir)
+	}
+}
+
+func TestStateDir(t *testing.T) {
+	app := &cli.CLI{Name: "prog"}
+
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		os.Setenv("XDG_STATE_HOME", "/tmp/state")
+		defer os.Unsetenv("XDG_STATE_HOME")
+		if dir, _ := app.StateDir(); dir != filepath.Join("/tmp/state", "prog") {
+			t.Errorf("Expected %q, found %q", filepath.Join("/tmp/state", "prog"), dir)
+		}
+	}
+
+	os.Setenv("PROG_STATE_DIR", "/tmp/prog-state")
+	defer os.Unsetenv("PROG_STATE_DIR")
+	if dir, _ := app.StateDir(); dir != "/tmp/prog-state" {
+		t.Errorf("Expected %q, found %q", "/tmp/prog-state", dir)
+	}
+}
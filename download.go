@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// downloadProgressInterval limits how often Download updates its progress bar.
+// When each update prints a line, as it does when stderr is redirected,
+// downloadLogInterval is used instead so logs aren't flooded.
+const (
+	downloadProgressInterval = 100 * time.Millisecond
+	downloadLogInterval      = 5 * time.Second
+)
+
+// DownloadOptions controls Download. The zero value is valid.
+type DownloadOptions struct {
+	// SHA256 is the expected SHA-256 checksum of the file, in hex. If it is
+	// set and the downloaded file does not match, the file is discarded and
+	// Download returns an error.
+	SHA256 string
+
+	// PublicKeys are the keys trusted to sign the file. If any are set, the
+	// signature at SignatureURL is downloaded and the file is discarded
+	// unless it was signed by one of them. See VerifySignature.
+	PublicKeys []PublicKey
+
+	// SignatureURL is the location of the file's signature. Defaults to the
+	// URL of the file with a .minisig suffix.
+	SignatureURL string
+
+	// Retry controls how failed or interrupted transfers are retried. Each
+	// attempt resumes from where the last one stopped if the server supports
+	// range requests.
+	Retry RetryPolicy
+
+	// Client makes the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Download fetches url to dest, showing its progress on stderr. It is shared
+// infrastructure for commands that install updates, plugins, or other
+// assets.
+//
+// The file is written to dest with a .part suffix and only renamed to dest
+// once it is complete, its checksum matches, and it is signed by one of the
+// PublicKeys if any are set, so dest is never left partially written or
+// replaced by a file that failed verification. A .part file left by an
+// earlier attempt or an earlier run is resumed rather than downloaded again,
+// but only if the server confirms, using the ETag or Last-Modified time saved
+// next to it, that the file hasn't changed since, and resumes from the end of
+// the .part file. Otherwise it is downloaded again from the start.
+//
+// Download returns an error without making any requests if the user passed
+// --offline. options may be nil.
+func Download(ctx *Context, url, dest string, options *DownloadOptions) error {
+	if options == nil {
+		options = &DownloadOptions{}
+	}
+	client := options.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var messages Strings
+	if ctx.CLI != nil {
+		messages = ctx.CLI.Strings
+	}
+	if ctx.CLI != nil && ctx.CLI.offlineRequested() {
+		return messages.errorf("unable to download %s in offline mode", url)
+	}
+
+	part := dest + ".part"
+	label := "download of " + filepath.Base(dest)
+	err := Retry(ctx, label, options.Retry, func(attempt int) error {
+		return downloadPart(ctx, client, url, part, messages)
+	})
+	if err != nil {
+		return err
+	}
+
+	if options.SHA256 != "" {
+		found, err := fileSHA256(part)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(found, options.SHA256) {
+			removePart(part)
+			return messages.errorf("checksum mismatch for %s: expected %s, found %s", url, options.SHA256, found)
+		}
+	}
+
+	if len(options.PublicKeys) > 0 {
+		signatureURL := options.SignatureURL
+		if signatureURL == "" {
+			signatureURL = url + ".minisig"
+		}
+		var signature []byte
+		err := Retry(ctx, "download of "+filepath.Base(dest)+" signature", options.Retry, func(attempt int) (err error) {
+			signature, err = fetchSignature(ctx, client, signatureURL, messages)
+			return
+		})
+		if err != nil {
+			return err
+		}
+		if err := verifySignature(part, signature, options.PublicKeys, messages); err != nil {
+			removePart(part)
+			return err
+		}
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return err
+	}
+	os.Remove(part + validatorSuffix)
+	return nil
+}
+
+// validatorSuffix is added to the name of a .part file to name the file that
+// holds the validator of the download, its ETag or Last-Modified time, so it
+// can be resumed with If-Range.
+const validatorSuffix = ".validator"
+
+// removePart removes a .part file and its validator.
+func removePart(part string) {
+	os.Remove(part)
+	os.Remove(part + validatorSuffix)
+}
+
+// responseValidator returns the value to send in If-Range to resume the
+// download in resp, or "" if it can't be resumed safely. Weak ETags can't be
+// used with If-Range.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// maxSignatureSize limits how much of a signature file is read.
+const maxSignatureSize = 64 << 10
+
+// fetchSignature downloads the signature at url. Errors are translated with
+// messages.
+func fetchSignature(ctx *Context, client *http.Client, url string, messages Strings) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, Permanent(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := messages.errorf("%s: %s", url, resp.Status)
+		if resp.StatusCode < 500 {
+			return nil, Permanent(err)
+		}
+		return nil, err
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+}
+
+// downloadPart fetches url into part, resuming from the end of part if it
+// already exists. Errors are translated with messages, and those that retrying
+// won't fix are marked Permanent.
+func downloadPart(ctx *Context, client *http.Client, url, part string, messages Strings) error {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Permanent(err)
+	}
+	// Only resume if the server can tell us the file hasn't changed, or the
+	// bytes of two different files would be joined. With If-Range, a server
+	// sends the whole file instead if it has.
+	if validator, _ := ioutil.ReadFile(part + validatorSuffix); offset > 0 && len(validator) > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		req.Header.Set("If-Range", strings.TrimSpace(string(validator)))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			// The server resumed from somewhere else, so start again
+			resp.Body.Close()
+			removePart(part)
+			return downloadPart(ctx, client, url, part, messages)
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is no longer valid, so start again
+		removePart(part)
+		return messages.errorf("unable to resume %s", url)
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+		if validator := responseValidator(resp); validator != "" {
+			if err := ioutil.WriteFile(part+validatorSuffix, []byte(validator+"\n"), 0644); err != nil {
+				return Permanent(err)
+			}
+		} else {
+			os.Remove(part + validatorSuffix)
+		}
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout:
+		return messages.errorf("%s: %s", url, resp.Status)
+	default:
+		return Permanent(messages.errorf("%s: %s", url, resp.Status))
+	}
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return Permanent(err)
+	}
+	defer f.Close()
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	writer := &progressWriter{
+		w:        f,
+		progress: ctx.Printer.Progress(100),
+		name:     filepath.Base(strings.TrimSuffix(part, ".part")),
+		written:  offset,
+		total:    total,
+		interval: downloadProgressInterval,
+	}
+	if !writer.progress.redraw() && !ctx.Printer.ProgressJSON {
+		writer.interval = downloadLogInterval
+	}
+	if _, err := io.Copy(writer, resp.Body); err != nil {
+		writer.progress.Done("")
+		return err
+	}
+	writer.progress.Done("")
+	return nil
+}
+
+// contentRangeStart returns the position of the first byte in a
+// Content-Range header, such as "bytes 4000-9999/10000".
+func contentRangeStart(header string) (int64, bool) {
+	if !strings.HasPrefix(header, "bytes ") {
+		return 0, false
+	}
+	i := strings.Index(header, "-")
+	if i < 0 {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(header[len("bytes "):i], 10, 64)
+	return start, err == nil
+}
+
+// progressWriter reports the bytes written through it to a Progress, as a
+// percentage of total. total is -1 if the size is not known.
+type progressWriter struct {
+	w        io.Writer
+	progress *Progress
+	name     string
+	written  int64
+	total    int64
+	interval time.Duration
+	updated  time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if time.Since(p.updated) < p.interval && p.written != p.total {
+		return n, err
+	}
+	p.updated = time.Now()
+
+	percent := 0
+	message := "Downloading " + p.name + " (" + formatSize(p.written)
+	if p.total > 0 {
+		percent = int(p.written * 100 / p.total)
+		message += " of " + formatSize(p.total)
+	}
+	p.progress.Update(percent, message+")")
+	return n, err
+}
+
+// formatSize formats a number of bytes for display, such as "4.2 MB".
+func formatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n), ""
+	for _, suffix = range []string{"kB", "MB", "GB", "TB"} {
+		value /= unit
+		if value < unit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// fileSHA256 returns the SHA-256 checksum of the file at path, in hex.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,142 @@
+package cli_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/asset.bin" && r.URL.Path != "/shifted.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v2"`)
+		if r.URL.Path == "/shifted.bin" && r.Header.Get("Range") != "" {
+			// Resume from a different byte than was asked for
+			w.Header().Set("Content-Range", "bytes 3000-9999/10000")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[3000:])
+			return
+		}
+		http.ServeContent(w, r, "asset.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dest := filepath.Join(dir, "asset.bin")
+
+	stderr := &bytes.Buffer{}
+	var url string
+	var options *cli.DownloadOptions
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: stderr, ASCII: true},
+		Commands: map[string]*cli.Command{
+			"fetch": {RunContext: func(ctx *cli.Context) error {
+				return cli.Download(ctx, url, dest, options)
+			}},
+		},
+	}
+
+	type TestCase struct {
+		Name      string
+		Path      string
+		Partial   []byte
+		Validator string
+		SHA256    string
+		Range     string
+		Restart   bool
+		Error     string
+	}
+
+	stale := bytes.Repeat([]byte("x"), 4000)
+	cases := []TestCase{
+		{Name: "full", Path: "/asset.bin", SHA256: checksum},
+		{Name: "resume", Path: "/asset.bin", Partial: content[:4000], Validator: `"v2"`, SHA256: checksum, Range: "bytes=4000-"},
+		// The server sends the whole file because it has changed since
+		{Name: "changed", Path: "/asset.bin", Partial: stale, Validator: `"v1"`, SHA256: checksum, Range: "bytes=4000-"},
+		// Without a validator there's no way to know if the file has changed
+		{Name: "unknown", Path: "/asset.bin", Partial: stale, SHA256: checksum},
+		// The server resumes from the wrong place, so the file is downloaded
+		// again from the start
+		{Name: "shifted", Path: "/shifted.bin", Partial: content[:4000], Validator: `"v2"`, SHA256: checksum, Range: "bytes=4000-", Restart: true},
+		{Name: "checksum", Path: "/asset.bin", SHA256: strings.Repeat("0", 64), Error: "checksum mismatch"},
+		{Name: "missing", Path: "/missing.bin", Error: "404 Not Found"},
+	}
+
+	for _, c := range cases {
+		os.Remove(dest)
+		os.Remove(dest + ".part")
+		os.Remove(dest + ".part.validator")
+		if c.Partial != nil {
+			if err := ioutil.WriteFile(dest+".part", c.Partial, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if c.Validator != "" {
+			if err := ioutil.WriteFile(dest+".part.validator", []byte(c.Validator+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ranges = nil
+		stderr.Reset()
+		url = server.URL + c.Path
+		options = &cli.DownloadOptions{SHA256: c.SHA256, Retry: cli.RetryPolicy{Attempts: 1}}
+
+		err := app.RunArgs([]string{"fetch"})
+		if c.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), c.Error) {
+				t.Errorf("%s: expected an error containing %q, found %v", c.Name, c.Error, err)
+			}
+			if _, err := os.Stat(dest); !os.IsNotExist(err) {
+				t.Errorf("%s: expected %s not to exist after a failed download", c.Name, dest)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", c.Name, err)
+		}
+
+		data, err := ioutil.ReadFile(dest)
+		if err != nil || !bytes.Equal(data, content) {
+			t.Errorf("%s: expected %s to hold the downloaded content, found %d bytes, %v", c.Name, dest, len(data), err)
+		}
+		expectedRanges := []string{c.Range}
+		if c.Restart {
+			expectedRanges = append(expectedRanges, "")
+		}
+		if !reflect.DeepEqual(ranges, expectedRanges) {
+			t.Errorf("%s: expected Range %q, found %q", c.Name, c.Range, ranges)
+		}
+		if _, err := os.Stat(dest + ".part.validator"); !os.IsNotExist(err) {
+			t.Errorf("%s: expected the validator to be removed after the download", c.Name)
+		}
+		expected := "[100/100] Downloading asset.bin (10.0 kB of 10.0 kB)\n"
+		if !strings.HasSuffix(stderr.String(), expected) {
+			t.Errorf("%s: expected progress %q, found %q", c.Name, expected, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"os"
+	"strings"
+)
+
+// checkRequiredEnv returns an error naming every variable in
+// command.RequiresEnv that is not set or is empty.
+func (c *CLI) checkRequiredEnv(command *Command) error {
+	var missing []string
+	for _, name := range command.RequiresEnv {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return c.Strings.errorf("environment variable %s must be set", missing[0])
+	}
+	return c.Strings.errorf("environment variables %s must be set", strings.Join(missing, ", "))
+}
+
+// envList formats the Environment section of a command's help page.
+func envList(c *CLI, command *Command) string {
+	indent := strings.Repeat(" ", c.layout().Indent)
+	output := ""
+	for _, name := range command.RequiresEnv {
+		output += indent + name + "\n"
+	}
+	return output
+}
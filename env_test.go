@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestRequiresEnv(t *testing.T) {
+	os.Unsetenv("SHIP_TEST_TOKEN")
+	os.Unsetenv("SHIP_TEST_REGION")
+	defer os.Unsetenv("SHIP_TEST_TOKEN")
+	defer os.Unsetenv("SHIP_TEST_REGION")
+
+	ran := false
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Help:        "Deploy the application",
+				RequiresEnv: []string{"SHIP_TEST_TOKEN", "SHIP_TEST_REGION"},
+				Run: func(args []string) error {
+					ran = true
+					return nil
+				},
+			},
+		},
+	}
+
+	expected := "environment variables SHIP_TEST_TOKEN, SHIP_TEST_REGION must be set"
+	if err := app.RunArgs([]string{"deploy"}); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+
+	os.Setenv("SHIP_TEST_TOKEN", "secret")
+	expected = "environment variable SHIP_TEST_REGION must be set"
+	if err := app.RunArgs([]string{"deploy"}); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+	if ran {
+		t.Error("Expected deploy not to run with missing environment variables")
+	}
+
+	os.Setenv("SHIP_TEST_REGION", "us-east-1")
+	if err := app.RunArgs([]string{"deploy"}); err != nil || !ran {
+		t.Errorf("Expected deploy to run, found %v", err)
+	}
+
+	if err := app.RunArgs([]string{"help", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	expected = `Environment
+
+  SHIP_TEST_TOKEN
+  SHIP_TEST_REGION
+`
+	if !strings.HasSuffix(stdout.String(), expected) {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout.String())
+	}
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hintError attaches a hint to an error. See WithHint.
+type hintError struct {
+	err  error
+	hint string
+}
+
+func (e *hintError) Error() string { return e.err.Error() }
+func (e *hintError) Unwrap() error { return e.err }
+func (e *hintError) Hint() string  { return e.hint }
+
+// WithHint annotates err with a suggestion for how the user can fix the
+// problem, such as "run 'ship login' first". The hint is shown below the
+// error by RenderError, while err.Error() is unchanged. WithHint returns nil
+// if err is nil.
+func WithHint(err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &hintError{err: err, hint: hint}
+}
+
+// ExitCoder is implemented by errors that specify the status the program
+// should exit with. See WithExitCode.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// exitError attaches an exit status to an error. See WithExitCode.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+func (e *exitError) ExitCode() int { return e.code }
+
+// WithExitCode annotates err with the status the program should exit with
+// when err is handled by Main. The status should be documented in the
+// command's ExitCodes. WithExitCode returns nil if err is nil.
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{err: err, code: code}
+}
+
+// ExitCode returns the status the program should exit with for err: 0 if err
+// is nil, the status of the first ExitCoder in err's chain, or 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return 1
+}
+
+// CheckExitCode returns an error if err would cause the program to exit with
+// a status that is not documented in the ExitCodes of the command at path,
+// such as "deploy" or "release create". Statuses 0 and 1 are always allowed.
+// It is meant for tests:
+//
+//	err := app.Run()
+//	if err := cli.CheckExitCode(app, "deploy", err); err != nil {
+//		t.Error(err)
+//	}
+func CheckExitCode(c *CLI, path string, err error) error {
+	command, ok := c.lookupPath(path)
+	if !ok {
+		return fmt.Errorf("command %q is not defined", path)
+	}
+	code := ExitCode(err)
+	if _, ok := command.ExitCodes[code]; ok || code == 0 || code == 1 {
+		return nil
+	}
+	return fmt.Errorf("command %q exited with undocumented status %d: %s", path, code, err)
+}
+
+// exitStatusList formats the Exit Status section of a command's help page.
+// Statuses 0 and 1 are listed with a generic meaning unless the command
+// documents them.
+func exitStatusList(c *CLI, exitCodes map[int]string) string {
+	meanings := map[int]string{
+		0: "The command completed successfully",
+		1: "An error occurred",
+	}
+	for code, meaning := range exitCodes {
+		meanings[code] = meaning
+	}
+
+	codes := []int{}
+	for code := range meanings {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	var rows [][]string
+	for _, code := range codes {
+		rows = append(rows, []string{strconv.Itoa(code), meanings[code]})
+	}
+	return c.columns(rows)
+}
+
+// UsageError indicates that a command was invoked incorrectly, such as with an
+// unknown flag or a missing argument. RenderError shows the command's usage
+// along with the error.
+type UsageError struct {
+	// Command is the name or path of the command that was invoked.
+	Command string
+	Err     error
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// RenderError formats err for display to the user. The top-level message is
+// highlighted, and errors wrapped with fmt.Errorf's %w verb are listed below
+// it as an indented list of causes:
+//
+//	error: unable to deploy web
+//	  caused by: connecting to api.example.com
+//	    caused by: dial tcp: connection refused
+//	hint: check your network connection
+//
+// Hints attached with WithHint are shown below the causes, and if err is a
+// UsageError, the usage of the command follows. Color is only used when
+// stderr is a terminal.
+//
+// The labels, and any message that exactly matches a key in c.Strings, such
+// as ErrTooManyArguments, are translated.
+func RenderError(c *CLI, err error) string {
+	color := c.Printer.styled(c.Printer.stderr())
+	style := func(s, text string) string {
+		if color {
+			return colorize(s, text)
+		}
+		return text
+	}
+
+	var hints []string
+	usage := ""
+	var messages []string
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if hinter, ok := current.(interface{ Hint() string }); ok && hinter.Hint() != "" {
+			hints = append(hints, hinter.Hint())
+		}
+		if usageErr, ok := current.(*UsageError); ok && usage == "" {
+			usage = CommandUsage(c, usageErr.Command)
+		}
+
+		// Show only the part of the message this error adds to its cause
+		message := current.Error()
+		if cause := errors.Unwrap(current); cause != nil {
+			if message == cause.Error() {
+				continue
+			}
+			message = strings.TrimSuffix(message, ": "+cause.Error())
+		}
+		messages = append(messages, c.Strings.get(message))
+	}
+	if len(messages) == 0 {
+		messages = []string{c.Strings.get(err.Error())}
+	}
+
+	output := style(ansiRed+ansiBold, c.Strings.get("error:")) + " " + style(ansiBold, messages[0]) + "\n"
+	for i, cause := range messages[1:] {
+		output += strings.Repeat("  ", i+1) + c.Strings.get("caused by:") + " " + cause + "\n"
+	}
+	for _, hint := range hints {
+		output += style(ansiCyan, c.Strings.get("hint:")) + " " + hint + "\n"
+	}
+	if usage != "" {
+		output += c.Strings.get("usage:") + " " + usage + "\n"
+	}
+	return output
+}
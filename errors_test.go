@@ -0,0 +1,147 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestRenderError(t *testing.T) {
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: &bytes.Buffer{}},
+	}
+
+	type TestCase struct {
+		Err      error
+		Expected string
+	}
+
+	refused := errors.New("dial tcp: connection refused")
+	cases := []TestCase{
+		{
+			errors.New("no route to host"),
+			"error: no route to host\n",
+		},
+		{
+			fmt.Errorf("unable to deploy web: %w", fmt.Errorf("connecting to api.example.com: %w", refused)),
+			`error: unable to deploy web
+  caused by: connecting to api.example.com
+    caused by: dial tcp: connection refused
+`,
+		},
+		{
+			// The wrapper's message doesn't repeat the cause
+			fmt.Errorf("login failed (%w)", refused),
+			`error: login failed (dial tcp: connection refused)
+  caused by: dial tcp: connection refused
+`,
+		},
+		{
+			cli.WithHint(fmt.Errorf("unable to deploy web: %w", refused), "check your network connection"),
+			`error: unable to deploy web
+  caused by: dial tcp: connection refused
+hint: check your network connection
+`,
+		},
+	}
+
+	for _, c := range cases {
+		if output := cli.RenderError(app, c.Err); output != c.Expected {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", c.Expected, output)
+		}
+	}
+
+	if cli.WithHint(nil, "unused") != nil {
+		t.Error("Expected WithHint(nil) to return nil")
+	}
+}
+
+func TestRenderUsageError(t *testing.T) {
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	flags.String("env", "staging", "")
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Flags: flags,
+				Args:  []cli.ArgSpec{{Name: "app", Required: true}},
+				Run:   func(args []string) error { return nil },
+			},
+		},
+	}
+
+	err := app.RunArgs([]string{"deploy", "--evn", "prod", "web"})
+	var usageErr *cli.UsageError
+	if !errors.As(err, &usageErr) || usageErr.Command != "deploy" {
+		t.Fatalf("Expected a UsageError for deploy, found %#v", err)
+	}
+
+	expected := "error: " + err.Error() + "\nusage: ship deploy [flags] <app>\n"
+	if output := cli.RenderError(app, err); output != expected {
+		t.Errorf("Expected %q, found %q", expected, output)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	rolledBack := cli.WithExitCode(errors.New("deployment rolled back"), 3)
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Help:      "Deploy the application",
+				ExitCodes: map[int]string{3: "The deployment failed and was rolled back"},
+				Run:       func(args []string) error { return rolledBack },
+			},
+			"status": {
+				Help: "Show deployment status",
+				Run:  func(args []string) error { return cli.WithExitCode(errors.New("degraded"), 4) },
+			},
+		},
+	}
+
+	err := app.RunArgs([]string{"deploy"})
+	if status := cli.ExitCode(err); status != 3 {
+		t.Errorf("Expected status 3, found %d", status)
+	}
+	if status := app.HandleError(fmt.Errorf("release failed: %w", err)); status != 3 {
+		t.Errorf("Expected status 3 through a wrapped error, found %d", status)
+	}
+	if err := cli.CheckExitCode(app, "deploy", err); err != nil {
+		t.Error(err)
+	}
+
+	err = app.RunArgs([]string{"status"})
+	expected := `command "status" exited with undocumented status 4: degraded`
+	if err := cli.CheckExitCode(app, "status", err); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+	if err := cli.CheckExitCode(app, "status", errors.New("plain")); err != nil {
+		t.Errorf("Expected status 1 to be allowed, found %v", err)
+	}
+
+	if cli.ExitCode(nil) != 0 || cli.WithExitCode(nil, 3) != nil {
+		t.Error("Expected nil errors to exit with status 0")
+	}
+
+	stdout := &bytes.Buffer{}
+	app.Printer.Stdout = stdout
+	if err := app.RunArgs([]string{"help", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	expected = `Exit Status
+
+  0   The command completed successfully
+  1   An error occurred
+  3   The deployment failed and was rolled back
+`
+	if !strings.HasSuffix(stdout.String(), expected) {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout.String())
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShellScript composes statements that change the state of the user's shell,
+// such as exporting environment variables or changing directories, which a
+// program can't do directly. It is used by commands with an Eval function,
+// whose output the user evaluates in their shell:
+//
+//	eval "$(program use staging)"
+//
+// Values are quoted so they are always interpreted literally.
+type ShellScript struct {
+	// Shell is the syntax to use: "bash", "zsh", "fish", "powershell", or
+	// "cmd". Other values use POSIX shell syntax.
+	Shell string
+
+	// messages translates the errors returned by the methods. See
+	// CLI.Strings.
+	messages   Strings
+	statements []string
+}
+
+// NewShellScript returns a ShellScript for the specified shell, or for the
+// user's shell (see DetectShell) if shell is empty.
+func NewShellScript(shell string) *ShellScript {
+	if shell == "" {
+		shell = DetectShell()
+	}
+	return &ShellScript{Shell: shell}
+}
+
+// Export sets an environment variable in the user's shell.
+func (s *ShellScript) Export(name, value string) error {
+	if !validEnvName(name) {
+		return s.messages.errorf("invalid environment variable name %q", name)
+	}
+
+	switch s.Shell {
+	case "fish":
+		s.statements = append(s.statements, fmt.Sprintf("set -gx %s %s", name, shellQuote(s.Shell, value)))
+	case "powershell":
+		s.statements = append(s.statements, fmt.Sprintf("$env:%s = %s", name, shellQuote(s.Shell, value)))
+	case "cmd":
+		if err := cmdSafe(value, s.messages); err != nil {
+			return s.messages.errorf("can't export %s: %s", name, err)
+		}
+		s.statements = append(s.statements, fmt.Sprintf(`set "%s=%s"`, name, value))
+	default:
+		s.statements = append(s.statements, fmt.Sprintf("export %s=%s", name, shellQuote(s.Shell, value)))
+	}
+	return nil
+}
+
+// Unset removes an environment variable from the user's shell.
+func (s *ShellScript) Unset(name string) error {
+	if !validEnvName(name) {
+		return s.messages.errorf("invalid environment variable name %q", name)
+	}
+
+	switch s.Shell {
+	case "fish":
+		s.statements = append(s.statements, fmt.Sprintf("set -e %s", name))
+	case "powershell":
+		s.statements = append(s.statements, fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", name))
+	case "cmd":
+		s.statements = append(s.statements, fmt.Sprintf(`set "%s="`, name))
+	default:
+		s.statements = append(s.statements, fmt.Sprintf("unset %s", name))
+	}
+	return nil
+}
+
+// Chdir changes the working directory of the user's shell.
+func (s *ShellScript) Chdir(dir string) error {
+	switch s.Shell {
+	case "powershell":
+		s.statements = append(s.statements, fmt.Sprintf("Set-Location -LiteralPath %s", shellQuote(s.Shell, dir)))
+	case "cmd":
+		if err := cmdSafe(dir, s.messages); err != nil {
+			return s.messages.errorf("can't change directory: %s", err)
+		}
+		s.statements = append(s.statements, fmt.Sprintf(`cd /d "%s"`, dir))
+	default:
+		s.statements = append(s.statements, fmt.Sprintf("cd -- %s", shellQuote(s.Shell, dir)))
+	}
+	return nil
+}
+
+// String returns the statements, one per line.
+func (s *ShellScript) String() string {
+	if len(s.statements) == 0 {
+		return ""
+	}
+	return strings.Join(s.statements, "\n") + "\n"
+}
+
+// validEnvName reports whether name is usable as an environment variable in
+// every supported shell.
+func validEnvName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// cmdSafe returns an error, translated with messages, if value can't be safely
+// used in a cmd.exe statement, which has no reliable way to quote these
+// characters.
+func cmdSafe(value string, messages Strings) error {
+	if strings.ContainsAny(value, "\"%!\r\n") {
+		return messages.errorf("value %q contains characters that cmd can't quote", value)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestShellScript(t *testing.T) {
+	type TestCase struct {
+		Shell    string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{
+			Shell:    "bash",
+			Expected: "export APP_ENV='it'\\''s staging'\nunset APP_DEBUG\ncd -- '/srv/my app'\n",
+		},
+		{
+			Shell:    "fish",
+			Expected: "set -gx APP_ENV 'it\\'s staging'\nset -e APP_DEBUG\ncd -- '/srv/my app'\n",
+		},
+		{
+			Shell:    "powershell",
+			Expected: "$env:APP_ENV = 'it''s staging'\nRemove-Item Env:APP_DEBUG -ErrorAction SilentlyContinue\nSet-Location -LiteralPath '/srv/my app'\n",
+		},
+		{
+			Shell:    "cmd",
+			Expected: "set \"APP_ENV=it's staging\"\nset \"APP_DEBUG=\"\ncd /d \"/srv/my app\"\n",
+		},
+	}
+
+	for _, testCase := range cases {
+		script := cli.NewShellScript(testCase.Shell)
+		if err := script.Export("APP_ENV", "it's staging"); err != nil {
+			t.Fatal(err)
+		}
+		if err := script.Unset("APP_DEBUG"); err != nil {
+			t.Fatal(err)
+		}
+		if err := script.Chdir("/srv/my app"); err != nil {
+			t.Fatal(err)
+		}
+		if script.String() != testCase.Expected {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", testCase.Expected, script.String())
+		}
+	}
+
+	script := cli.NewShellScript("bash")
+	if err := script.Export("BAD-NAME", ""); err == nil {
+		t.Error("Expected error for invalid variable name")
+	}
+	script = cli.NewShellScript("cmd")
+	if err := script.Export("PATH", "%PATH%;C:\\bin"); err == nil {
+		t.Error("Expected error for value cmd can't quote")
+	}
+}
+
+func TestEvalCommand(t *testing.T) {
+	ogShell := os.Getenv("SHELL")
+	defer os.Setenv("SHELL", ogShell)
+
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"use": {
+				Args: []cli.ArgSpec{{Name: "env", Required: true}},
+				Eval: func(ctx *cli.Context, script *cli.ShellScript) error {
+					ctx.Printer.Errf("Switched to %s\n", ctx.Args[0])
+					return script.Export("SHIP_ENV", ctx.Args[0])
+				},
+			},
+		},
+	}
+
+	os.Setenv("SHELL", "/usr/bin/fish")
+	if err := app.RunArgs([]string{"use", "staging"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The shell running the tests takes precedence over SHELL
+	output := stdout.String()
+	if output != "set -gx SHIP_ENV 'staging'\n" && !strings.HasPrefix(output, "export SHIP_ENV=") {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
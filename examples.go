@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Example demonstrates how to use a command.
+type Example struct {
+	// Description explains what the example does.
+	Description string
+
+	// Command is the complete command line, starting with the program name,
+	// such as "ship deploy --env staging web". Examples that are a single
+	// invocation of the program can be run with "program examples <command>
+	// --run <n>"; shell pipelines and other syntax are only displayed.
+	Command string
+}
+
+// shellOperators are words that make an example a shell command line rather
+// than a single invocation of the program.
+var shellOperators = []string{"|", "||", "&", "&&", ";", ">", ">>", "<", "2>", "2>&1"}
+
+// hasExamples reports whether any command declares Examples.
+func (c *CLI) hasExamples() bool {
+	for _, command := range c.commands() {
+		if len(command.Examples) > 0 && !command.HelpOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// renderExamples formats examples for display, optionally numbering them for
+// use with "program examples <command> --run <n>".
+func renderExamples(c *CLI, examples []Example, numbered bool) string {
+	indent := strings.Repeat(" ", c.layout().Indent)
+
+	output := ""
+	for i, example := range examples {
+		if i > 0 {
+			output += "\n"
+		}
+		prefix := indent
+		if numbered {
+			prefix += strconv.Itoa(i+1) + ". "
+		}
+		if example.Description == "" {
+			output += prefix + "$ " + example.Command + "\n"
+			continue
+		}
+		output += prefix + example.Description + "\n"
+		output += strings.Repeat(" ", len(prefix)+2) + "$ " + example.Command + "\n"
+	}
+	return output
+}
+
+// exampleArgs returns the arguments to run example with, after the program
+// name, or an error if the example is not a single invocation of the program.
+func (c *CLI) exampleArgs(example Example) ([]string, error) {
+	words, err := splitWords(example.Command, c.Strings)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 || words[0] != c.Name {
+		return nil, c.Strings.errorf("this example can't be run because it does not start with '%s'", c.Name)
+	}
+	for _, word := range words {
+		for _, operator := range shellOperators {
+			if word == operator {
+				return nil, c.Strings.errorf("this example can't be run because it uses shell syntax (%s), try running it in your shell instead", word)
+			}
+		}
+	}
+	return words[1:], nil
+}
+
+// examplesCommand implements the built-in examples command.
+func (c *CLI) examplesCommand(args []string) error {
+	set := flag.NewFlagSet("examples", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	run := set.Int("run", 0, "")
+	yes := set.Bool("yes", false, "")
+
+	parser := &flagParser{set: set, messages: c.Strings}
+	positionals, err := parser.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positionals) != 1 {
+		return c.Strings.errorf("usage: %s examples <command> [--run <n> [--yes]]", c.Name)
+	}
+
+	name := positionals[0]
+	command, ok := c.lookup(name)
+	if !ok || command.HelpOnly || (command.Experimental && !c.ExperimentalEnabled()) {
+		return c.unknownCommand(name)
+	}
+	if len(command.Examples) == 0 {
+		return c.Strings.errorf("command '%s' has no examples", name)
+	}
+
+	if *run == 0 {
+		c.Printer.Outf("Examples for %s\n\n", name)
+		c.Printer.Out(renderExamples(c, command.Examples, true))
+		c.Printer.Outf("\nRun '%s examples %s --run <n>' to try one.\n", c.Name, name)
+		return nil
+	}
+
+	if *run < 0 || *run > len(command.Examples) {
+		return c.Strings.errorf("example %d does not exist, '%s' has %d example(s)", *run, name, len(command.Examples))
+	}
+	example := command.Examples[*run-1]
+	exampleArgs, err := c.exampleArgs(example)
+	if err != nil {
+		return err
+	}
+
+	c.Printer.Errf("$ %s\n", example.Command)
+	if !*yes {
+		if !interactive() {
+			return c.Strings.errorf("refusing to run an example without confirmation, pass --yes to run it anyway")
+		}
+		answer, err := prompt("Run this example? [y/N] ", c.Strings)
+		if err != nil {
+			return err
+		}
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			c.Printer.Err("Not running example\n")
+			return nil
+		}
+	}
+
+	commandName, commandArgs := ParseArgs(exampleArgs)
+	return c.dispatch(commandName, commandArgs)
+}
@@ -0,0 +1,113 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestExamples(t *testing.T) {
+	var deployed []string
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: stderr},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Summary:     "Deploy an application",
+				Description: "Deploy builds and releases an application.",
+				Run: func(args []string) error {
+					deployed = args
+					return nil
+				},
+				Examples: []cli.Example{
+					{Description: "Deploy the web app", Command: "ship deploy web"},
+					{Command: "ship deploy api | tee deploy.log"},
+				},
+			},
+			"status": {
+				Summary: "Show status",
+				Run:     func(args []string) error { return nil },
+			},
+		},
+	}
+
+	run := func(args ...string) error {
+		stdout.Reset()
+		stderr.Reset()
+		return app.RunArgs(args)
+	}
+
+	t.Run("list", func(tt *testing.T) {
+		if err := run("examples", "deploy"); err != nil {
+			tt.Fatal(err)
+		}
+		expectedOutput := `Examples for deploy
+
+  1. Deploy the web app
+       $ ship deploy web
+
+  2. $ ship deploy api | tee deploy.log
+
+Run 'ship examples deploy --run <n>' to try one.
+`
+		if stdout.String() != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, stdout.String())
+		}
+	})
+
+	t.Run("help page", func(tt *testing.T) {
+		output, err := cli.Help(app, []string{"deploy"})
+		if err != nil {
+			tt.Fatal(err)
+		}
+		expected := "\nExamples\n\n  Deploy the web app\n    $ ship deploy web\n\n  $ ship deploy api | tee deploy.log\n"
+		if !strings.HasSuffix(output, expected) {
+			tt.Errorf("Expected help page to end with %q, found %q", expected, output)
+		}
+	})
+
+	t.Run("run", func(tt *testing.T) {
+		if err := run("examples", "deploy", "--run", "1", "--yes"); err != nil {
+			tt.Fatal(err)
+		}
+		if strings.Join(deployed, " ") != "web" {
+			tt.Errorf("Expected deploy to run with [web], found %q", deployed)
+		}
+		if stderr.String() != "$ ship deploy web\n" {
+			tt.Errorf("Expected %q, found %q", "$ ship deploy web\n", stderr.String())
+		}
+	})
+
+	t.Run("errors", func(tt *testing.T) {
+		type TestCase struct {
+			Args     []string
+			Expected string
+		}
+
+		cases := []TestCase{
+			{[]string{"examples", "deploy", "--run", "2", "--yes"}, "this example can't be run because it uses shell syntax (|), try running it in your shell instead"},
+			{[]string{"examples", "deploy", "--run", "3"}, "example 3 does not exist, 'deploy' has 2 example(s)"},
+			{[]string{"examples", "status"}, "command 'status' has no examples"},
+			{[]string{"examples", "launch"}, "'launch' is not a ship command. See 'ship --help'."},
+			{[]string{"examples"}, "usage: ship examples <command> [--run <n> [--yes]]"},
+		}
+
+		for _, c := range cases {
+			err := run(c.Args...)
+			if err == nil || err.Error() != c.Expected {
+				tt.Errorf("Expected %q, found %v", c.Expected, err)
+			}
+		}
+	})
+
+	t.Run("completion", func(tt *testing.T) {
+		result := cli.Complete(app, []string{"examples", ""})
+		if strings.Join(result.Candidates, ",") != "deploy" {
+			tt.Errorf("Expected [deploy], found %q", result.Candidates)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cbednarski/cli/text"
+)
+
+// boolFlag is implemented by flag.Value types that do not require an explicit
+// value, such as those created by flag.Bool. It mirrors the unexported
+// interface of the same name in the flag package.
+type boolFlag interface {
+	flag.Value
+	IsBoolFlag() bool
+}
+
+// flagParser separates the flags defined in a flag.FlagSet from positional
+// arguments. Unlike flag.FlagSet.Parse it accepts both -name and --name forms
+// and can optionally pass unknown flags through instead of failing.
+type flagParser struct {
+	set *flag.FlagSet
+
+	// permissive causes unknown flags to be returned as positional arguments
+	// instead of producing an error.
+	permissive bool
+
+	// flagsFirst stops parsing at the first positional argument, as POSIX
+	// getopt does. Otherwise flags and positional arguments may be mixed in
+	// any order, GNU-style.
+	flagsFirst bool
+
+	// messages translates the errors returned by parse. See CLI.Strings.
+	messages Strings
+}
+
+// parse sets the value of each flag found in args and returns the remaining
+// positional arguments. Parsing always stops at "--", and will also stop at
+// the first positional argument if flagsFirst is set.
+func (p *flagParser) parse(args []string) (positionals []string, err error) {
+	positionals = []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			positionals = append(positionals, args[i+1:]...)
+			return
+		}
+
+		if len(arg) < 2 || arg[0] != '-' {
+			if p.flagsFirst {
+				positionals = append(positionals, args[i:]...)
+				return
+			}
+			positionals = append(positionals, arg)
+			continue
+		}
+
+		name := strings.TrimPrefix(arg[1:], "-")
+		value := ""
+		hasValue := false
+		if idx := strings.Index(name, "="); idx > -1 {
+			name, value, hasValue = name[:idx], name[idx+1:], true
+		}
+
+		f := p.set.Lookup(name)
+		if f == nil {
+			if p.permissive {
+				positionals = append(positionals, arg)
+				continue
+			}
+			err = p.messages.errorf("unknown flag --%s%s", name, p.suggestion(name))
+			return
+		}
+
+		if bf, ok := f.Value.(boolFlag); ok && bf.IsBoolFlag() {
+			if !hasValue {
+				value = "true"
+			}
+		} else if !hasValue {
+			if i+1 >= len(args) {
+				err = p.messages.errorf("flag --%s requires a value", name)
+				return
+			}
+			i++
+			value = args[i]
+		}
+
+		if err = p.set.Set(name, value); err != nil {
+			err = p.messages.errorf("invalid value %q for flag --%s: %s", value, name, err)
+			return
+		}
+	}
+
+	return
+}
+
+// suggestion returns a ", did you mean ...?" hint listing known flags that are
+// similar to name, or an empty string if there are none.
+func (p *flagParser) suggestion(name string) string {
+	var known []string
+	p.set.VisitAll(func(f *flag.Flag) {
+		known = append(known, f.Name)
+	})
+
+	suggestions := Suggest(name, known)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	for idx := range suggestions {
+		suggestions[idx] = "--" + suggestions[idx]
+	}
+	return p.messages.sprintf(", did you mean %s?", strings.Join(suggestions, p.messages.get(" or ")))
+}
+
+// applyFlagEnv sets each flag in command.FlagEnv that was not passed on the
+// command line from its environment variable, if the variable is set.
+func (c *CLI) applyFlagEnv(command *Command) error {
+	if len(command.FlagEnv) == 0 {
+		return nil
+	}
+
+	set := map[string]bool{}
+	command.Flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	names := make([]string, 0, len(command.FlagEnv))
+	for name := range command.FlagEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := os.LookupEnv(command.FlagEnv[name])
+		if set[name] || !ok {
+			continue
+		}
+		if err := command.Flags.Set(name, value); err != nil {
+			return c.Strings.errorf("invalid value %q for %s: %s", value, command.FlagEnv[name], err)
+		}
+	}
+	return nil
+}
+
+// flagList formats the Flags section of a command's help page: each flag with
+// its type, followed by its usage, default value, environment variable, and
+// whether it is required. Defaults of sensitive flags are not shown.
+func flagList(c *CLI, command *Command) string {
+	if command.Flags == nil {
+		return ""
+	}
+
+	required := map[string]bool{}
+	for _, name := range command.RequiredFlags {
+		required[name] = true
+	}
+	sensitive := map[string]bool{}
+	for _, name := range command.SensitiveFlags {
+		sensitive[name] = true
+	}
+
+	var rows [][]string
+	command.Flags.VisitAll(func(f *flag.Flag) {
+		typeName, usage := flag.UnquoteUsage(f)
+		name := "--" + f.Name
+		if typeName != "" {
+			name += " " + typeName
+		}
+
+		var notes []string
+		if required[f.Name] {
+			notes = append(notes, "required")
+		} else if !sensitive[f.Name] && !isZeroDefault(f) {
+			if typeName == "string" {
+				notes = append(notes, fmt.Sprintf("default %q", f.DefValue))
+			} else {
+				notes = append(notes, "default "+f.DefValue)
+			}
+		}
+		if env := command.FlagEnv[f.Name]; env != "" {
+			notes = append(notes, "env "+env)
+		}
+		if len(notes) > 0 {
+			usage = strings.TrimSpace(usage + " (" + strings.Join(notes, ", ") + ")")
+		}
+
+		rows = append(rows, []string{name, usage})
+	})
+
+	return c.columns(rows)
+}
+
+// columns aligns rows for a section of a help page, using c's layout and
+// wrapping the last column to the width of the terminal.
+func (c *CLI) columns(rows [][]string) string {
+	layout := c.layout()
+	indent := strings.Repeat(" ", layout.Indent)
+	return text.Indent(text.Columnize(rows, layout.ColumnGap, c.helpWidth()-len(indent)), indent)
+}
+
+// isZeroDefault reports whether the default value of f is the zero value for
+// its type, which is not worth showing in help.
+func isZeroDefault(f *flag.Flag) bool {
+	switch f.DefValue {
+	case "", "false", "0", "0s", "[]":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package cli_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestCommandFlags(t *testing.T) {
+	var force bool
+	var name string
+	var received []string
+
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	flags.BoolVar(&force, "force", false, "skip confirmation")
+	flags.StringVar(&name, "name", "", "name of the deployment")
+
+	app := &cli.CLI{
+		Name: "testapp",
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Flags: flags,
+				Run: func(args []string) error {
+					received = args
+					return nil
+				},
+			},
+			"wrap": {
+				Flags:           flags,
+				PermissiveFlags: true,
+				Run: func(args []string) error {
+					received = args
+					return nil
+				},
+			},
+		},
+	}
+
+	type TestCase struct {
+		Args          []string
+		ExpectedForce bool
+		ExpectedName  string
+		ExpectedArgs  []string
+		ExpectedError string
+	}
+
+	cases := []TestCase{
+		{
+			Args:          []string{"deploy", "--force", "--name", "prod", "app1"},
+			ExpectedForce: true,
+			ExpectedName:  "prod",
+			ExpectedArgs:  []string{"app1"},
+		},
+		{
+			Args:         []string{"deploy", "-name=staging", "--", "--force"},
+			ExpectedName: "staging",
+			ExpectedArgs: []string{"--force"},
+		},
+		{
+			Args:         []string{"deploy", "--force=false", "app1", "app2"},
+			ExpectedArgs: []string{"app1", "app2"},
+		},
+		{
+			Args:          []string{"deploy", "app1", "--force", "app2", "--name=prod"},
+			ExpectedForce: true,
+			ExpectedName:  "prod",
+			ExpectedArgs:  []string{"app1", "app2"},
+		},
+		{
+			Args:          []string{"deploy", "--name"},
+			ExpectedError: "flag --name requires a value",
+		},
+		{
+			Args:          []string{"deploy", "--force=maybe"},
+			ExpectedError: `invalid value "maybe" for flag --force: parse error`,
+		},
+		{
+			Args:          []string{"deploy", "--verbose", "app1"},
+			ExpectedError: "unknown flag --verbose",
+		},
+		{
+			Args:          []string{"deploy", "--forse"},
+			ExpectedError: "unknown flag --forse, did you mean --force?",
+		},
+		{
+			Args:          []string{"wrap", "--verbose", "--force", "-x=1", "app1"},
+			ExpectedForce: true,
+			ExpectedArgs:  []string{"--verbose", "-x=1", "app1"},
+		},
+	}
+
+	for _, testCase := range cases {
+		force, name, received = false, "", nil
+
+		err := app.RunArgs(testCase.Args)
+		if testCase.ExpectedError != "" {
+			if err == nil || err.Error() != testCase.ExpectedError {
+				t.Errorf("Expected error %q, found %v with input %q", testCase.ExpectedError, err, testCase.Args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error %q with input %q", err, testCase.Args)
+			continue
+		}
+
+		if force != testCase.ExpectedForce {
+			t.Errorf("Expected force=%t, found %t with input %q", testCase.ExpectedForce, force, testCase.Args)
+		}
+		if name != testCase.ExpectedName {
+			t.Errorf("Expected name=%q, found %q with input %q", testCase.ExpectedName, name, testCase.Args)
+		}
+		if !reflect.DeepEqual(received, testCase.ExpectedArgs) {
+			t.Errorf("Expected %#v, found %#v with input %q", testCase.ExpectedArgs, received, testCase.Args)
+		}
+	}
+}
+
+func TestCommandFlagsFirst(t *testing.T) {
+	var force bool
+	var received []string
+
+	flags := flag.NewFlagSet("cp", flag.ContinueOnError)
+	flags.BoolVar(&force, "force", false, "overwrite existing files")
+
+	app := &cli.CLI{
+		Name:       "testapp",
+		FlagsFirst: true,
+		Commands: map[string]*cli.Command{
+			"cp": {
+				Flags: flags,
+				Run: func(args []string) error {
+					received = args
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"cp", "--force", "src", "--force", "dst"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedArgs := []string{"src", "--force", "dst"}
+	if !force {
+		t.Error("Expected force to be set")
+	}
+	if !reflect.DeepEqual(received, expectedArgs) {
+		t.Errorf("Expected %#v, found %#v", expectedArgs, received)
+	}
+}
+
+func TestFlagsHelp(t *testing.T) {
+	var env, token string
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	flags.StringVar(&env, "env", "staging", "environment to deploy to")
+	flags.Bool("force", false, "skip confirmation")
+	flags.Duration("timeout", 0, "give up after `wait`")
+	flags.Int("replicas", 3, "number of instances")
+	flags.StringVar(&token, "token", "secret", "API token")
+
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Description:    "Deploy the application.",
+				Flags:          flags,
+				RequiredFlags:  []string{"token"},
+				SensitiveFlags: []string{"token"},
+				FlagEnv:        map[string]string{"env": "SHIP_ENV", "token": "SHIP_TOKEN"},
+				Run:            func(args []string) error { return nil },
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"help", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `deploy Command Help
+
+usage: ship deploy [flags]
+
+Deploy the application.
+
+Flags
+
+  --env string     environment to deploy to (default "staging", env SHIP_ENV)
+  --force          skip confirmation
+  --replicas int   number of instances (default 3)
+  --timeout wait   give up after wait
+  --token string   API token (required, env SHIP_TOKEN)
+`
+	if stdout.String() != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout.String())
+	}
+
+	os.Setenv("SHIP_TOKEN", "from-env")
+	os.Setenv("SHIP_ENV", "production")
+	defer os.Unsetenv("SHIP_TOKEN")
+	defer os.Unsetenv("SHIP_ENV")
+
+	if err := app.RunArgs([]string{"deploy", "--env", "qa"}); err != nil {
+		t.Fatal(err)
+	}
+	if token != "from-env" || env != "qa" {
+		t.Errorf("Expected the environment to fill in only missing flags, found token %q and env %q", token, env)
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/cbednarski/cli/text"
+)
+
+// templateStyles are the styles available to the colorize template function.
+var templateStyles = map[string]string{
+	"bold":  ansiBold,
+	"red":   ansiRed,
+	"green": ansiGreen,
+	"cyan":  ansiCyan,
+}
+
+// FuncMap returns the functions the framework uses to format help, for use in
+// text/template templates that render custom help, such as a Header or a
+// command's Help text, so the output matches the built-in help:
+//
+//	pad 12 .Name       pads .Name with spaces to 12 columns
+//	wrap 60 .Text      wraps .Text at 60 columns
+//	indent 4 .Text     indents each line of .Text by 4 spaces
+//	trim .Text         removes leading and trailing whitespace
+//	colorize "bold" .  styles text as bold, red, green, or cyan
+//	columns .Rows      aligns a [][]string like the command list
+//	width              the width help is wrapped to, or 0
+//	name               the program name
+//
+// The width and layout follow c's settings, and colorize only adds styles
+// when stdout is a terminal that supports color.
+func FuncMap(c *CLI) template.FuncMap {
+	return template.FuncMap{
+		"pad": func(width int, s string) string {
+			return text.PadRight(s, width)
+		},
+		"wrap": func(width int, s string) string {
+			return text.Wrap(s, width)
+		},
+		"indent": func(n int, s string) string {
+			return text.Indent(s, strings.Repeat(" ", n))
+		},
+		"trim": strings.TrimSpace,
+		"colorize": func(style, s string) string {
+			if code, ok := templateStyles[style]; ok && c.Printer.styled(c.Printer.stdout()) {
+				return colorize(code, s)
+			}
+			return s
+		},
+		"columns": c.columns,
+		"width":   c.helpWidth,
+		"name": func() string {
+			return c.Name
+		},
+	}
+}
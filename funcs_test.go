@@ -0,0 +1,47 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"text/template"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestFuncMap(t *testing.T) {
+	if value, ok := os.LookupEnv("COLUMNS"); ok {
+		defer os.Setenv("COLUMNS", value)
+	} else {
+		defer os.Unsetenv("COLUMNS")
+	}
+	os.Setenv("COLUMNS", "40")
+
+	app := &cli.CLI{Name: "ship"}
+	tmpl := template.Must(template.New("help").Funcs(cli.FuncMap(app)).Parse(
+		`{{name}} {{pad 8 "deploy"}}| {{colorize "bold" "ships"}}
+{{wrap width .Text | indent 2}}
+{{columns .Rows}}{{trim "  done  "}}
+`))
+
+	data := map[string]interface{}{
+		"Text": "Deploy the application to every region in the selected environment.",
+		"Rows": [][]string{{"--env", "environment"}, {"--force", "skip checks"}},
+	}
+	buffer := &bytes.Buffer{}
+	if err := tmpl.Execute(buffer, data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Output is not a terminal, so colorize leaves the text alone
+	expected := `ship deploy  | ships
+  Deploy the application to every region
+  in the selected environment.
+  --env     environment
+  --force   skip checks
+done
+`
+	if buffer.String() != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, buffer.String())
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+)
+
+// glossaryIndent is how far definitions are indented below their term in the
+// glossary listing.
+const glossaryIndent = 4
+
+// hasGlossary reports whether "help glossary" is available. A command or help
+// topic named "glossary" takes precedence over the built-in glossary.
+func (c *CLI) hasGlossary() bool {
+	if len(c.Glossary) == 0 {
+		return false
+	}
+	_, ok := c.lookup("glossary")
+	return !ok
+}
+
+// GlossaryHelp returns the glossary for "program help glossary". With no
+// arguments every term in c.Glossary is listed in lexical order, followed by
+// its definition. With a single argument only that term is shown. Terms are
+// matched without regard to case.
+func GlossaryHelp(c *CLI, args []string) (output string, err error) {
+	width := c.helpWidth()
+
+	switch len(args) {
+	case 0:
+		terms := make([]string, 0, len(c.Glossary))
+		for term := range c.Glossary {
+			terms = append(terms, term)
+		}
+		sort.Slice(terms, func(i, j int) bool {
+			return strings.ToLower(terms[i]) < strings.ToLower(terms[j])
+		})
+
+		indent := strings.Repeat(" ", c.layout().Indent)
+		definitionIndent := indent + strings.Repeat(" ", glossaryIndent)
+		wrapWidth := width - len(definitionIndent)
+		if wrapWidth < minWrapWidth {
+			wrapWidth = 0
+		}
+
+		output += "Glossary\n"
+		for _, term := range terms {
+			output += "\n" + indent + term + "\n"
+			for _, line := range strings.Split(strings.TrimSpace(c.Glossary[term]), "\n") {
+				for _, wrapped := range wrapWords(line, wrapWidth) {
+					output += strings.TrimRight(definitionIndent+wrapped, " ") + "\n"
+				}
+			}
+		}
+	case 1:
+		term, ok := c.glossaryTerm(args[0])
+		if !ok {
+			err = c.Strings.errorf("unknown glossary term '%s'", args[0])
+			var terms []string
+			for term := range c.Glossary {
+				terms = append(terms, term)
+			}
+			if suggestions := Suggest(args[0], terms); len(suggestions) > 0 {
+				err = c.Strings.errorf("%s, did you mean %s?", err, strings.Join(suggestions, " or "))
+			}
+			return
+		}
+		output += term + "\n\n" + EnsureNewlines(wrap(strings.TrimSpace(c.Glossary[term]), width))
+	default:
+		err = c.Strings.translate(ErrTooManyArguments)
+	}
+
+	return
+}
+
+// glossaryTerm finds the glossary entry for name, preferring an exact match
+// over one that differs only in case.
+func (c *CLI) glossaryTerm(name string) (string, bool) {
+	if _, ok := c.Glossary[name]; ok {
+		return name, true
+	}
+	for term := range c.Glossary {
+		if strings.EqualFold(term, name) {
+			return term, true
+		}
+	}
+	return "", false
+}
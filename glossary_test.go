@@ -0,0 +1,109 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestGlossaryHelp(t *testing.T) {
+	app := &cli.CLI{
+		Name: "ledger",
+		Layout: cli.HelpLayout{
+			MaxWidth: 40,
+		},
+		Commands: map[string]*cli.Command{
+			"post": {
+				Summary: "Post a journal entry",
+				Help:    "Post adds an entry to the journal.",
+			},
+		},
+		Glossary: map[string]string{
+			"journal": "The chronological record of every transaction, before it is posted to accounts.",
+			"Accrual": "Revenue or expense recognized before cash changes hands.",
+		},
+	}
+
+	t.Run("topic list", func(tt *testing.T) {
+		expectedOutput := `usage: ledger help <topic>
+
+Help Topics
+
+  glossary         Definitions of terms
+                   used by ledger
+  post (command)   Post a journal entry
+`
+		output, err := cli.Help(app, []string{})
+		if err != nil {
+			tt.Fatal(err)
+		}
+		if output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+
+	t.Run("all terms", func(tt *testing.T) {
+		expectedOutput := `Glossary
+
+  Accrual
+      Revenue or expense recognized
+      before cash changes hands.
+
+  journal
+      The chronological record of every
+      transaction, before it is posted
+      to accounts.
+`
+		output, err := cli.Help(app, []string{"glossary"})
+		if err != nil {
+			tt.Fatal(err)
+		}
+		if output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+
+	t.Run("single term", func(tt *testing.T) {
+		expectedOutput := `Accrual
+
+Revenue or expense recognized before
+cash changes hands.
+`
+		output, err := cli.Help(app, []string{"glossary", "accrual"})
+		if err != nil {
+			tt.Fatal(err)
+		}
+		if output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+
+	t.Run("unknown term", func(tt *testing.T) {
+		_, err := cli.Help(app, []string{"glossary", "jurnal"})
+		expected := "unknown glossary term 'jurnal', did you mean journal?"
+		if err == nil || err.Error() != expected {
+			tt.Errorf("Expected %q, found %v", expected, err)
+		}
+	})
+
+	t.Run("completion", func(tt *testing.T) {
+		result := cli.Complete(app, []string{"help", "glossary", "jo"})
+		if len(result.Candidates) != 1 || result.Candidates[0] != "journal" {
+			tt.Errorf("Expected [journal], found %q", result.Candidates)
+		}
+	})
+
+	t.Run("command takes precedence", func(tt *testing.T) {
+		app.Commands["glossary"] = &cli.Command{Help: "Our own glossary.", HelpOnly: true}
+		defer delete(app.Commands, "glossary")
+
+		output, err := cli.Help(app, []string{"glossary"})
+		if err != nil {
+			tt.Fatal(err)
+		}
+		expected := "glossary Help\n\nOur own glossary.\n"
+		if output != expected {
+			tt.Errorf("Expected %q, found %q", expected, output)
+		}
+	})
+}
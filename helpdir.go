@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadHelpDir reads help topics from files in dir and adds them to c. Each file
+// is a help topic named after the file, without its extension, so
+// "install.txt" becomes "program help install". If the topic matches an
+// existing command, the file replaces that command's help text. Otherwise a
+// new help-only topic is added.
+//
+// This allows operators to add or translate help pages after a program is
+// installed, without rebuilding it. Hidden files and subdirectories are
+// ignored, and a dir that does not exist is not an error.
+func LoadHelpDir(c *CLI, dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return c.Strings.errorf("failed to load help topics: %s", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		topic := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if topic == "" || strings.ContainsAny(topic, " \n\t") {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return c.Strings.errorf("failed to load help topic %q: %s", topic, err)
+		}
+
+		c.mu.Lock()
+		if c.Commands == nil {
+			c.Commands = map[string]*Command{}
+		}
+		if command, ok := c.Commands[topic]; ok {
+			command.Help = string(data)
+			command.Description = ""
+		} else {
+			c.Commands[topic] = &Command{
+				Help:     string(data),
+				HelpOnly: true,
+			}
+		}
+		c.listing = nil
+		c.mu.Unlock()
+	}
+
+	return nil
+}
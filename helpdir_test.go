@@ -0,0 +1,69 @@
+package cli_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestLoadHelpDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-test-help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"install.txt": "Download the binary and put it in your PATH.",
+		"bake.md":     "Backen Sie den Kuchen bei 180 Grad.",
+		".hidden":     "Should not be loaded.",
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "drafts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &cli.CLI{
+		Name: "cake",
+		Commands: map[string]*cli.Command{
+			"bake": {
+				Summary: "heat things up",
+				Help:    "Bake the cake at 350 degrees.",
+			},
+		},
+	}
+
+	if err := cli.LoadHelpDir(app, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(app.Commands) != 2 {
+		t.Errorf("Expected 2 commands, found %d", len(app.Commands))
+	}
+
+	install, ok := app.Commands["install"]
+	if !ok {
+		t.Fatal("Expected install help topic to be loaded")
+	}
+	if !install.HelpOnly || install.Help != files["install.txt"] {
+		t.Errorf("Expected help-only topic with %q, found %#v", files["install.txt"], install)
+	}
+
+	if app.Commands["bake"].Help != files["bake.md"] {
+		t.Errorf("Expected %q, found %q", files["bake.md"], app.Commands["bake"].Help)
+	}
+	if app.Commands["bake"].Summary != "heat things up" {
+		t.Error("Expected loading help to preserve the command summary")
+	}
+
+	if err := cli.LoadHelpDir(app, filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("Expected missing help directory to be ignored, found %s", err)
+	}
+}
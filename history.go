@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historyFile is the name of the file in the StateDir that records how often
+// and how recently each command was run.
+const historyFile = "history.json"
+
+// historySectionSize is the number of commands listed in the history section.
+const historySectionSize = 5
+
+// HistorySection selects a section listing the user's common commands at the
+// top of the command help. See CLI.HistorySection.
+type HistorySection int
+
+const (
+	// HistoryNone does not track commands or show a section.
+	HistoryNone HistorySection = iota
+
+	// HistoryRecent shows a "Recently used" section with the commands the
+	// user ran most recently.
+	HistoryRecent
+
+	// HistoryFrequent shows a "Most used" section with the commands the user
+	// has run most often.
+	HistoryFrequent
+)
+
+// historyEntry records how the user has used a command.
+type historyEntry struct {
+	Count   int       `json:"count"`
+	LastRun time.Time `json:"last_run"`
+}
+
+// loadHistory reads the history recorded in the StateDir.
+func (c *CLI) loadHistory() (map[string]historyEntry, error) {
+	history := map[string]historyEntry{}
+	dir, err := c.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, historyFile))
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// recordHistory records that the user ran the command at path, counting
+// subcommands toward their top-level command. Errors reading or writing the
+// state file are ignored so they can't prevent the program from working.
+func (c *CLI) recordHistory(path string) {
+	if c.HistorySection == HistoryNone {
+		return
+	}
+	name := strings.Fields(path)[0]
+	if command, ok := c.lookup(name); !ok || !c.listed(command) {
+		return
+	}
+
+	history, err := c.loadHistory()
+	if err != nil {
+		return
+	}
+	entry := history[name]
+	entry.Count++
+	entry.LastRun = time.Now().UTC()
+	history[name] = entry
+
+	dir, err := c.StateDir()
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	ioutil.WriteFile(filepath.Join(dir, historyFile), append(data, '\n'), 0644)
+}
+
+// historySection returns the title and names of the commands for the section
+// selected by c.HistorySection, or no names if the user hasn't run any
+// commands yet. Commands that no longer exist or are not listed are skipped.
+func (c *CLI) historySection() (title string, names []string) {
+	if c.HistorySection == HistoryNone {
+		return "", nil
+	}
+	history, err := c.loadHistory()
+	if err != nil {
+		return "", nil
+	}
+
+	for name := range history {
+		if command, ok := c.lookup(name); ok && c.listed(command) && c.permitted(name) {
+			names = append(names, name)
+		}
+	}
+
+	title = "Recently used"
+	less := func(a, b historyEntry) bool {
+		return a.LastRun.After(b.LastRun)
+	}
+	if c.HistorySection == HistoryFrequent {
+		title = "Most used"
+		less = func(a, b historyEntry) bool {
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.LastRun.After(b.LastRun)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := history[names[i]], history[names[j]]
+		if less(a, b) || less(b, a) {
+			return less(a, b)
+		}
+		return c.SortOrder.less(names[i], names[j])
+	})
+
+	if len(names) > historySectionSize {
+		names = names[:historySectionSize]
+	}
+	return title, names
+}
@@ -0,0 +1,89 @@
+package cli_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestHistorySection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("CAKE_STATE_DIR", dir)
+	defer os.Unsetenv("CAKE_STATE_DIR")
+
+	run := func(args []string) error { return nil }
+	app := &cli.CLI{
+		Name: "cake",
+		Commands: map[string]*cli.Command{
+			"bake":    {Summary: "heat things up", Run: run},
+			"eat":     {Summary: "enjoy delicious cake!", Run: run},
+			"mix":     {Summary: "incorporate your ingredients", Run: run},
+			"cleanup": {Hidden: true, Run: run},
+		},
+		DisableVersion: true,
+		HistorySection: cli.HistoryRecent,
+	}
+
+	expectedOutput := `usage: cake [--help] <command> [<args>]
+
+Commands
+
+  cake bake   heat things up
+  cake eat    enjoy delicious cake!
+  cake mix    incorporate your ingredients
+  cake help   List help topics
+`
+	if output := cli.CommandHelp(app); output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	for _, name := range []string{"mix", "mix", "cleanup", "bake"} {
+		if err := app.RunArgs([]string{name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Commands that are refused are not recorded
+	app.Authorize = func(path string) error {
+		if path == "eat" {
+			return errors.New("not hungry")
+		}
+		return nil
+	}
+	if err := app.RunArgs([]string{"eat"}); err == nil {
+		t.Fatal("Expected eat to be refused")
+	}
+
+	expectedOutput = `usage: cake [--help] <command> [<args>]
+
+Recently used
+
+  cake bake   heat things up
+  cake mix    incorporate your ingredients
+
+Commands
+
+  cake bake   heat things up
+  cake eat    enjoy delicious cake!
+  cake mix    incorporate your ingredients
+  cake help   List help topics
+`
+	if output := cli.CommandHelp(app); output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+
+	app.HistorySection = cli.HistoryFrequent
+	expected := "Most used\n\n  cake mix    incorporate your ingredients\n  cake bake   heat things up\n"
+	if output := cli.CommandHelp(app); !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain %q, found %q", expected, output)
+	}
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultHookTimeout is used when Hooks.Timeout is zero.
+const DefaultHookTimeout = time.Minute
+
+// Hooks lets users extend the program with their own scripts that run before
+// and after commands, in the style of git hooks. For a command named deploy,
+// the hooks are executables named pre-deploy and post-deploy in the hooks
+// directory. Missing hooks are skipped.
+//
+// Each hook is run with the command's arguments as its arguments, with stdin
+// closed and its output sent to stderr, and with the following environment
+// variables set in addition to the program's environment (using a program
+// named prog as an example):
+//
+//	PROG_HOOK          "pre" or "post"
+//	PROG_COMMAND       the name of the command
+//	PROG_EXIT_STATUS   for post hooks, "0" if the command succeeded or "1"
+//	PROG_ERROR         for post hooks, the command's error message, if any
+//
+// A pre hook that fails or times out stops the command from running. A post
+// hook that fails causes the program to report an error if the command itself
+// succeeded. Set ContinueOnError to report failures as warnings instead.
+type Hooks struct {
+	// Dir is the directory containing hook scripts. It defaults to the hooks
+	// directory inside ConfigDir.
+	Dir string
+
+	// Timeout limits how long each hook may run before it is stopped and
+	// considered failed. Defaults to DefaultHookTimeout.
+	Timeout time.Duration
+
+	// ContinueOnError prints a warning when a hook fails instead of failing
+	// the command.
+	ContinueOnError bool
+}
+
+// hookDir returns the directory containing hook scripts.
+func (c *CLI) hookDir() (string, error) {
+	if c.Hooks.Dir != "" {
+		return c.Hooks.Dir, nil
+	}
+	dir, err := c.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hooks"), nil
+}
+
+// runHook runs the pre or post hook for a command, if one exists. For post
+// hooks, commandErr is the result of the command.
+func (c *CLI) runHook(stage, commandName string, args []string, commandErr error) error {
+	dir, err := c.hookDir()
+	if err != nil {
+		return nil
+	}
+	// Subcommand hooks are named after the full path, like pre-release-create
+	name := stage + "-" + strings.Replace(commandName, " ", "-", -1)
+	path, ok := findHook(dir, name)
+	if !ok {
+		return nil
+	}
+
+	timeout := c.Hooks.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = c.Printer.StderrWriter()
+	cmd.Stderr = c.Printer.StderrWriter()
+	cmd.Env = c.childEnv(
+		c.envVar("HOOK")+"="+stage,
+		c.envVar("COMMAND")+"="+commandName,
+	)
+	if stage == "post" {
+		status, message := "0", ""
+		if commandErr != nil {
+			status, message = "1", commandErr.Error()
+		}
+		cmd.Env = append(cmd.Env, c.envVar("EXIT_STATUS")+"="+status, c.envVar("ERROR")+"="+message)
+	}
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = c.Strings.errorf("timed out after %s", timeout)
+	}
+	if err == nil {
+		return nil
+	}
+
+	err = c.Strings.errorf("%s hook failed: %s", name, err)
+	if c.Hooks.ContinueOnError {
+		c.Printer.Errf("warning: %s\n", err)
+		return nil
+	}
+	return err
+}
+
+// findHook returns the path of the named hook in dir. On Windows, hooks may
+// have any executable extension.
+func findHook(dir, name string) (string, bool) {
+	candidates := []string{name}
+	if runtime.GOOS == "windows" {
+		for _, ext := range strings.Split(strings.ToLower(os.Getenv("PATHEXT")), ";") {
+			if ext != "" {
+				candidates = append(candidates, name+ext)
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		path := filepath.Join(dir, candidate)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+			continue
+		}
+		return path, true
+	}
+	return "", false
+}
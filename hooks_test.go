@@ -0,0 +1,105 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func writeHook(t *testing.T, dir, name, script string) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test hooks are shell scripts")
+	}
+
+	dir, err := ioutil.TempDir("", "cli-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := filepath.Join(dir, "log")
+	writeHook(t, dir, "pre-deploy", `echo "$SHIP_HOOK $SHIP_COMMAND $*" >> `+log+"\n")
+	writeHook(t, dir, "post-deploy", `echo "$SHIP_HOOK $SHIP_EXIT_STATUS $SHIP_ERROR" >> `+log+"\n")
+	writeHook(t, dir, "pre-blocked", "echo 'not allowed'\nexit 1\n")
+	writeHook(t, dir, "pre-slow", "exec sleep 5\n")
+
+	var deployErr error
+	ran := false
+	run := func(args []string) error {
+		ran = true
+		return deployErr
+	}
+
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: stderr},
+		Hooks:   &cli.Hooks{Dir: dir, Timeout: 100 * time.Millisecond},
+		Commands: map[string]*cli.Command{
+			"deploy":  {Run: run},
+			"blocked": {Run: run},
+			"slow":    {Run: run},
+			"nohooks": {Run: run},
+		},
+	}
+
+	if err := app.RunArgs([]string{"deploy", "web"}); err != nil {
+		t.Fatal(err)
+	}
+	deployErr = errors.New("boom")
+	if err := app.RunArgs([]string{"deploy", "web"}); err == nil || err.Error() != "boom" {
+		t.Errorf("Expected %q, found %v", "boom", err)
+	}
+
+	data, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "pre deploy web\npost 0 \npre deploy web\npost 1 boom\n"
+	if string(data) != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, string(data))
+	}
+
+	ran = false
+	deployErr = nil
+	if err := app.RunArgs([]string{"blocked"}); err == nil || err.Error() != "pre-blocked hook failed: exit status 1" {
+		t.Errorf("Expected pre hook failure, found %v", err)
+	}
+	if ran {
+		t.Error("Expected failed pre hook to stop the command")
+	}
+	if !strings.Contains(stderr.String(), "not allowed") {
+		t.Errorf("Expected hook output on stderr, found %q", stderr.String())
+	}
+
+	if err := app.RunArgs([]string{"slow"}); err == nil || err.Error() != "pre-slow hook failed: timed out after 100ms" {
+		t.Errorf("Expected timeout, found %v", err)
+	}
+
+	app.Hooks.ContinueOnError = true
+	stderr.Reset()
+	if err := app.RunArgs([]string{"blocked"}); err != nil {
+		t.Fatal(err)
+	}
+	if !ran || !strings.Contains(stderr.String(), "warning: pre-blocked hook failed") {
+		t.Errorf("Expected a warning and the command to run, found %q", stderr.String())
+	}
+
+	if err := app.RunArgs([]string{"nohooks"}); err != nil {
+		t.Fatal(err)
+	}
+}
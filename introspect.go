@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"strings"
+)
+
+// CLISpec is a machine-readable description of a CLI, for use by external
+// tooling such as documentation generators, shell integrations, and linters.
+type CLISpec struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Commands    []CommandSpec     `json:"commands"`
+}
+
+// CommandSpec is a machine-readable description of a Command.
+type CommandSpec struct {
+	Name         string            `json:"name"`
+	Summary      string            `json:"summary,omitempty"`
+	Synopsis     string            `json:"synopsis,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Version      string            `json:"version,omitempty"`
+	Hidden       bool              `json:"hidden,omitempty"`
+	HelpOnly     bool              `json:"help_only,omitempty"`
+	Experimental bool              `json:"experimental,omitempty"`
+	Flags        []FlagSpec        `json:"flags,omitempty"`
+	Args         []ArgSpec         `json:"args,omitempty"`
+	RequiresEnv  []string          `json:"requires_env,omitempty"`
+	Platforms    []string          `json:"platforms,omitempty"`
+	ExitCodes    map[int]string    `json:"exit_codes,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Commands     []CommandSpec     `json:"commands,omitempty"`
+}
+
+// FlagSpec is a machine-readable description of a command flag.
+type FlagSpec struct {
+	Name      string `json:"name"`
+	Type      string `json:"type,omitempty"`
+	Usage     string `json:"usage,omitempty"`
+	Default   string `json:"default,omitempty"`
+	Required  bool   `json:"required,omitempty"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+	Env       string `json:"env,omitempty"`
+}
+
+// Introspect returns a description of c and all of its commands, including
+// subcommands. Commands are listed in lexical order.
+func Introspect(c *CLI) *CLISpec {
+	spec := &CLISpec{
+		Name:        c.Name,
+		Version:     c.Version,
+		Annotations: c.Annotations,
+		Commands:    []CommandSpec{},
+	}
+
+	commands := c.resolvedCommands()
+	for _, name := range SortedCommandNames(commands) {
+		spec.Commands = append(spec.Commands, commandSpec(c, name, name, commands[name]))
+	}
+
+	return spec
+}
+
+// commandSpec describes command, which has the specified name and path, and
+// its subcommands.
+func commandSpec(c *CLI, name, path string, command *Command) CommandSpec {
+	spec := CommandSpec{
+		Name:         name,
+		Summary:      command.Summary,
+		Description:  command.Description,
+		Version:      command.Version,
+		Hidden:       command.Hidden,
+		HelpOnly:     command.HelpOnly,
+		Experimental: command.Experimental,
+		Args:         command.Args,
+		RequiresEnv:  command.RequiresEnv,
+		Platforms:    command.Platforms,
+		ExitCodes:    command.ExitCodes,
+		Annotations:  command.Annotations,
+	}
+	if !command.HelpOnly {
+		spec.Synopsis = CommandUsage(c, path)
+	}
+
+	if command.Flags != nil {
+		required := map[string]bool{}
+		for _, flagName := range command.RequiredFlags {
+			required[flagName] = true
+		}
+		sensitive := map[string]bool{}
+		for _, flagName := range command.SensitiveFlags {
+			sensitive[flagName] = true
+		}
+
+		command.Flags.VisitAll(func(f *flag.Flag) {
+			typeName, _ := flag.UnquoteUsage(f)
+			flagSpec := FlagSpec{
+				Name:      f.Name,
+				Type:      typeName,
+				Usage:     f.Usage,
+				Required:  required[f.Name],
+				Sensitive: sensitive[f.Name],
+				Env:       command.FlagEnv[f.Name],
+			}
+			// Don't leak default credentials into tooling
+			if !flagSpec.Sensitive {
+				flagSpec.Default = f.DefValue
+			}
+			spec.Flags = append(spec.Flags, flagSpec)
+		})
+	}
+
+	for _, subname := range SortedCommandNames(command.Commands) {
+		spec.Commands = append(spec.Commands, commandSpec(c, subname, path+" "+subname, command.Commands[subname]))
+	}
+	return spec
+}
+
+// IntrospectJSON returns the result of Introspect encoded as indented JSON.
+func IntrospectJSON(c *CLI) (string, error) {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(Introspect(c)); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
@@ -0,0 +1,119 @@
+package cli_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestIntrospectJSON(t *testing.T) {
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	flags.String("env", "staging", "environment to deploy to")
+	flags.String("token", "hunter2", "API token")
+
+	app := &cli.CLI{
+		Name:    "shipit",
+		Version: "0.3.0",
+		Annotations: map[string]string{
+			"team": "platform",
+		},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				Summary:        "deploy the application",
+				Flags:          flags,
+				RequiredFlags:  []string{"token"},
+				SensitiveFlags: []string{"token"},
+				Args: []cli.ArgSpec{
+					{Name: "app", Required: true},
+				},
+				Annotations: map[string]string{
+					"stability": "beta",
+				},
+			},
+			"install": {
+				Help:     "Put the binary in your PATH.",
+				HelpOnly: true,
+			},
+			"db": {
+				Summary: "manage the database",
+				Commands: map[string]*cli.Command{
+					"migrate": {
+						Summary: "run database migrations",
+						Args: []cli.ArgSpec{
+							{Name: "version"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedOutput := `{
+  "name": "shipit",
+  "version": "0.3.0",
+  "annotations": {
+    "team": "platform"
+  },
+  "commands": [
+    {
+      "name": "db",
+      "summary": "manage the database",
+      "synopsis": "shipit db <command> [<args>]",
+      "commands": [
+        {
+          "name": "migrate",
+          "summary": "run database migrations",
+          "synopsis": "shipit db migrate [<version>]",
+          "args": [
+            {
+              "name": "version"
+            }
+          ]
+        }
+      ]
+    },
+    {
+      "name": "deploy",
+      "summary": "deploy the application",
+      "synopsis": "shipit deploy [flags] <app>",
+      "flags": [
+        {
+          "name": "env",
+          "type": "string",
+          "usage": "environment to deploy to",
+          "default": "staging"
+        },
+        {
+          "name": "token",
+          "type": "string",
+          "usage": "API token",
+          "required": true,
+          "sensitive": true
+        }
+      ],
+      "args": [
+        {
+          "name": "app",
+          "required": true
+        }
+      ],
+      "annotations": {
+        "stability": "beta"
+      }
+    },
+    {
+      "name": "install",
+      "help_only": true
+    }
+  ]
+}`
+
+	output, err := cli.IntrospectJSON(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+	}
+}
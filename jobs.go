@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Files in each job's directory.
+const (
+	jobFile       = "job.json"
+	jobOutputFile = "output.log"
+	jobExitFile   = "exit"
+)
+
+// Job is a command started in the background with --detach. Jobs are tracked
+// in the StateDir and managed with the built-in jobs command.
+type Job struct {
+	// ID identifies the job in "program jobs logs <id>".
+	ID string `json:"id"`
+
+	// Command is the command line the job runs, after the program name.
+	Command []string `json:"command"`
+
+	// PID is the process ID of the job.
+	PID int `json:"pid"`
+
+	// Started is when the job was started.
+	Started time.Time `json:"started"`
+}
+
+// hasDetachable reports whether any command may be run with --detach.
+func (c *CLI) hasDetachable() bool {
+	for _, command := range c.commands() {
+		if command.Detachable {
+			return true
+		}
+	}
+	return false
+}
+
+// jobsDir returns the directory jobs are recorded in.
+func (c *CLI) jobsDir() (string, error) {
+	dir, err := c.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jobs"), nil
+}
+
+// jobFlags returns the global flags the current run was given, so a job
+// behaves as the command would have in the foreground. --width and --no-color
+// are passed on by childEnv instead.
+func (c *CLI) jobFlags() []string {
+	var flags []string
+	if c.experimental {
+		flags = append(flags, "--enable-experimental")
+	}
+	if c.offline {
+		flags = append(flags, "--offline")
+	}
+	if c.channel != "" {
+		flags = append(flags, "--channel="+c.channel)
+	}
+	if c.Printer.ASCII {
+		flags = append(flags, "--no-emoji")
+	}
+	if c.Printer.Accessible {
+		flags = append(flags, "--accessible")
+	}
+	if c.Printer.ProgressJSON {
+		flags = append(flags, "--progress=json")
+	}
+	return flags
+}
+
+// startJob runs commandName with args in the background and records it as a
+// job. The job's output is written to a log file in its directory.
+func (c *CLI) startJob(commandName string, args []string) error {
+	dir, err := c.jobsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Job IDs are sequential. Mkdir fails if another process takes the same
+	// ID first, in which case we try the next one.
+	id := 1
+	for _, job := range c.jobs() {
+		if n, err := strconv.Atoi(job.ID); err == nil && n >= id {
+			id = n + 1
+		}
+	}
+	for ; ; id++ {
+		err := os.Mkdir(filepath.Join(dir, strconv.Itoa(id)), 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+	}
+	job := &Job{ID: strconv.Itoa(id), Command: append(strings.Fields(commandName), args...)}
+	jobDir := filepath.Join(dir, job.ID)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	attr, err := detachAttr()
+	if err != nil {
+		return err
+	}
+	output, err := os.Create(filepath.Join(jobDir, jobOutputFile))
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	cmd := exec.Command(executable, append(c.jobFlags(), job.Command...)...)
+	// Run the job under the program's name rather than the executable's, so
+	// a Link doesn't add its command again and Multiplex selects this program
+	cmd.Args[0] = c.Name
+	cmd.Env = c.childEnv(c.envVar("JOB") + "=" + job.ID)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = attr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	job.PID = cmd.Process.Pid
+	job.Started = time.Now()
+	_ = cmd.Process.Release()
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(jobDir, jobFile), append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	c.Printer.Errf("Started job %s (pid %d). Run '%s jobs logs %s' to see its output.\n", job.ID, job.PID, c.Name, job.ID)
+	return nil
+}
+
+// finishJob records the exit status of the command if this process is a job,
+// and returns err.
+func (c *CLI) finishJob(err error) error {
+	id := os.Getenv(c.envVar("JOB"))
+	if id == "" {
+		return err
+	}
+	if dir, dirErr := c.jobsDir(); dirErr == nil {
+		status := strconv.Itoa(ExitCode(err)) + "\n"
+		_ = ioutil.WriteFile(filepath.Join(dir, id, jobExitFile), []byte(status), 0644)
+	}
+	return err
+}
+
+// jobs returns the recorded jobs, oldest first.
+func (c *CLI) jobs() []*Job {
+	dir, err := c.jobsDir()
+	if err != nil {
+		return nil
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var jobs []*Job
+	for _, entry := range entries {
+		if job, err := c.job(entry.Name()); err == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		a, _ := strconv.Atoi(jobs[i].ID)
+		b, _ := strconv.Atoi(jobs[j].ID)
+		return a < b
+	})
+	return jobs
+}
+
+// job returns the job with the specified ID.
+func (c *CLI) job(id string) (*Job, error) {
+	dir, err := c.jobsDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, filepath.Base(id), jobFile))
+	if os.IsNotExist(err) {
+		return nil, c.Strings.errorf("job '%s' does not exist", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// jobStatus describes the state of job, such as "running" or "exited 1".
+func (c *CLI) jobStatus(job *Job) string {
+	dir, err := c.jobsDir()
+	if err != nil {
+		return "unknown"
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, job.ID, jobExitFile))
+	if err == nil {
+		return "exited " + strings.TrimSpace(string(data))
+	}
+	if processRunning(job.PID) {
+		return "running"
+	}
+	return "stopped"
+}
+
+// jobsCommand implements the built-in jobs command.
+func (c *CLI) jobsCommand(args []string) error {
+	usage := c.Strings.errorf("usage: %s jobs [list | logs <id> | stop <id>]", c.Name)
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return usage
+		}
+		jobs := c.jobs()
+		if len(jobs) == 0 {
+			c.Printer.Errf("No jobs. Run a command with '%s --detach <command>' to start one.\n", c.Name)
+			return nil
+		}
+		table := &Table{Columns: []Column{{Name: "ID"}, {Name: "Status"}, {Name: "Started"}, {Name: "Command", MaxWidth: 50}}}
+		for _, job := range jobs {
+			table.Rows = append(table.Rows, []string{job.ID, c.jobStatus(job), job.Started.Format("2006-01-02 15:04:05"), strings.Join(job.Command, " ")})
+		}
+		return table.Render(c.Printer.StdoutWriter(), &TableOptions{Width: c.Printer.OutputWidth(), Strings: c.Strings})
+	case "logs":
+		if len(args) != 2 {
+			return usage
+		}
+		job, err := c.job(args[1])
+		if err != nil {
+			return err
+		}
+		dir, err := c.jobsDir()
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, job.ID, jobOutputFile))
+		if err != nil {
+			return err
+		}
+		c.Printer.Out(string(data))
+		return nil
+	case "stop":
+		if len(args) != 2 {
+			return usage
+		}
+		job, err := c.job(args[1])
+		if err != nil {
+			return err
+		}
+		if c.jobStatus(job) != "running" {
+			return c.Strings.errorf("job '%s' is not running", job.ID)
+		}
+		if err := stopProcess(job.PID); err != nil {
+			return err
+		}
+		c.Printer.Errf("Stopped job %s (pid %d)\n", job.ID, job.PID)
+		return nil
+	}
+	return usage
+}
@@ -0,0 +1,113 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestJobs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("SHIP_STATE_DIR", dir)
+	defer os.Unsetenv("SHIP_STATE_DIR")
+
+	// Record a finished job and one that is still running (this process)
+	jobs := map[string]string{
+		"1": `{"id": "1", "command": ["sync", "--all"], "pid": 999999999, "started": "2024-05-01T10:00:00Z"}`,
+		"2": fmt.Sprintf(`{"id": "2", "command": ["build"], "pid": %d, "started": "2024-05-01T11:30:00Z"}`, os.Getpid()),
+	}
+	for id, record := range jobs {
+		jobDir := filepath.Join(dir, "jobs", id)
+		if err := os.MkdirAll(jobDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(jobDir, "job.json"), []byte(record), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(jobDir, "output.log"), []byte("output of job "+id+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "jobs", "1", "exit"), []byte("0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"build":  {Summary: "Build the site", Detachable: true, Run: func(args []string) error { return nil }},
+			"status": {Summary: "Show status", Run: func(args []string) error { return nil }},
+		},
+	}
+
+	if !strings.Contains(cli.CommandHelp(app), "ship jobs     List and manage background jobs\n") {
+		t.Errorf("Expected jobs in the command list, found:\n%s", cli.CommandHelp(app))
+	}
+
+	type TestCase struct {
+		Args     []string
+		Expected string
+		Error    string
+	}
+
+	cases := []TestCase{
+		{
+			Args: []string{"jobs"},
+			Expected: "ID   STATUS     STARTED               COMMAND\n" +
+				"1    exited 0   2024-05-01 10:00:00   sync --all\n" +
+				"2    running    2024-05-01 11:30:00   build\n",
+		},
+		{
+			// Tables fit in --width
+			Args: []string{"--width", "30", "jobs"},
+			Expected: "ID   STATUS     STARTED    ...\n" +
+				"1    exited 0   2024-05-01 ...\n" +
+				"2    running    2024-05-01 ...\n",
+		},
+		{
+			Args:     []string{"jobs", "logs", "1"},
+			Expected: "output of job 1\n",
+		},
+		{
+			Args:  []string{"jobs", "logs", "3"},
+			Error: "job '3' does not exist",
+		},
+		{
+			Args:  []string{"jobs", "stop", "1"},
+			Error: "job '1' is not running",
+		},
+		{
+			Args:  []string{"--detach", "status"},
+			Error: "'status' can't be run in the background",
+		},
+	}
+
+	for _, testCase := range cases {
+		stdout.Reset()
+		err := app.RunArgs(testCase.Args)
+		if testCase.Error != "" {
+			if err == nil || err.Error() != testCase.Error {
+				t.Errorf("Expected error %q, found %v", testCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %v: %s", testCase.Args, err)
+		}
+		if stdout.String() != testCase.Expected {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", testCase.Expected, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// License records the license of a third-party module that is compiled into
+// the program. Most open source licenses require that their notice be
+// distributed with binaries, which CLI.Licenses makes available to users via
+// the built-in licenses and about commands.
+//
+// The cli-licenses tool generates a list of Licenses from go.mod and the
+// module cache.
+type License struct {
+	// Module is the import path of the module, such as golang.org/x/sys.
+	Module string
+
+	// Version of the module that the program was built with.
+	Version string
+
+	// License is the SPDX identifier of the license, such as MIT or
+	// Apache-2.0, if it is known.
+	License string
+
+	// Text is the full license text, including the copyright notice.
+	Text string
+}
+
+// LicenseNotices returns the license texts for "program licenses". If module
+// is empty the notices for every module are returned, otherwise only the
+// notice for that module.
+func LicenseNotices(c *CLI, module string) (output string, err error) {
+	found := false
+	for _, license := range c.Licenses {
+		if module != "" && license.Module != module {
+			continue
+		}
+		if found {
+			output += "\n"
+		}
+		found = true
+
+		output += strings.TrimSpace(license.Module+" "+license.Version) + "\n"
+		if license.License != "" {
+			output += "License: " + license.License + "\n"
+		}
+		if text := strings.TrimSpace(license.Text); text != "" {
+			output += "\n" + text + "\n"
+		}
+	}
+
+	if module != "" && !found {
+		return "", c.Strings.errorf("no license notice for module '%s'", module)
+	}
+	return
+}
+
+// About returns the output of "program about": the program version and a
+// summary of the third-party modules it includes and their licenses.
+func About(c *CLI) string {
+	output := Version(c) + "\n"
+	if len(c.Licenses) == 0 {
+		return output
+	}
+
+	moduleWidth, versionWidth := 0, 0
+	for _, license := range c.Licenses {
+		if len(license.Module) > moduleWidth {
+			moduleWidth = len(license.Module)
+		}
+		if len(license.Version) > versionWidth {
+			versionWidth = len(license.Version)
+		}
+	}
+
+	layout := c.layout()
+	indent := strings.Repeat(" ", layout.Indent)
+	gap := strings.Repeat(" ", layout.ColumnGap)
+
+	output += "\nThis program includes the following third-party software:\n\n"
+	for _, license := range c.Licenses {
+		spdx := license.License
+		if spdx == "" {
+			spdx = "see license text"
+		}
+		output += fmt.Sprintf("%s%s%s%s%s%s\n", indent, PadRight(license.Module, moduleWidth), gap, PadRight(license.Version, versionWidth), gap, spdx)
+	}
+	output += fmt.Sprintf("\nRun '%s licenses' to see the full license texts.\n", c.Name)
+	return output
+}
+
+// licensesCommand implements the built-in licenses command.
+func (c *CLI) licensesCommand(args []string) error {
+	if len(args) > 1 {
+		return c.Strings.errorf("usage: %s licenses [<module>]", c.Name)
+	}
+	module := ""
+	if len(args) == 1 {
+		module = args[0]
+	}
+
+	output, err := LicenseNotices(c, module)
+	if err != nil {
+		return err
+	}
+	c.Printer.Out(output)
+	return nil
+}
@@ -0,0 +1,94 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestLicenses(t *testing.T) {
+	app := &cli.CLI{
+		Name:    "ferry",
+		Version: "1.4.0",
+		Licenses: []cli.License{
+			{
+				Module:  "github.com/BurntSushi/toml",
+				Version: "v1.2.1",
+				License: "MIT",
+				Text:    "MIT License\n\nCopyright (c) 2013 TOML authors\n",
+			},
+			{
+				Module:  "golang.org/x/sys",
+				Version: "v0.5.0",
+				Text:    "Copyright (c) 2009 The Go Authors.",
+			},
+		},
+	}
+
+	t.Run("about", func(tt *testing.T) {
+		expectedOutput := `ferry version 1.4.0
+
+This program includes the following third-party software:
+
+  github.com/BurntSushi/toml   v1.2.1   MIT
+  golang.org/x/sys             v0.5.0   see license text
+
+Run 'ferry licenses' to see the full license texts.
+`
+		if output := cli.About(app); output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+
+	t.Run("all notices", func(tt *testing.T) {
+		expectedOutput := `github.com/BurntSushi/toml v1.2.1
+License: MIT
+
+MIT License
+
+Copyright (c) 2013 TOML authors
+
+golang.org/x/sys v0.5.0
+
+Copyright (c) 2009 The Go Authors.
+`
+		output, err := cli.LicenseNotices(app, "")
+		if err != nil {
+			tt.Fatal(err)
+		}
+		if output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+
+	t.Run("one module", func(tt *testing.T) {
+		output, err := cli.LicenseNotices(app, "golang.org/x/sys")
+		if err != nil {
+			tt.Fatal(err)
+		}
+		expected := "golang.org/x/sys v0.5.0\n\nCopyright (c) 2009 The Go Authors.\n"
+		if output != expected {
+			tt.Errorf("Expected %q, found %q", expected, output)
+		}
+
+		_, err = cli.LicenseNotices(app, "golang.org/x/net")
+		expected = "no license notice for module 'golang.org/x/net'"
+		if err == nil || err.Error() != expected {
+			tt.Errorf("Expected %q, found %v", expected, err)
+		}
+	})
+
+	t.Run("command list", func(tt *testing.T) {
+		expectedOutput := `usage: ferry [--version] [--help] <command> [<args>]
+
+Commands
+
+  ferry about      Show version and third-party software information
+  ferry licenses   Show third-party license notices
+  ferry help       List help topics
+`
+		if output := cli.CommandHelp(app); output != expectedOutput {
+			tt.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// errLocked is returned by tryLock when another process holds the lock.
+var errLocked = errors.New("locked")
+
+// Lock ensures that only one copy of a command runs at a time, for commands
+// such as migrations and updaters that must not run concurrently. It takes an
+// exclusive lock on a file named after name in the StateDir, and returns an
+// error such as "already running (pid 1234)" if another process holds it:
+//
+//	unlock, err := cli.Lock(ctx.CLI, "migrate")
+//	if err != nil {
+//		return err
+//	}
+//	defer unlock()
+//
+// The operating system releases the lock if the process exits without
+// calling unlock, so a crash never leaves the command locked. Platforms
+// without advisory file locks use a lock file created exclusively instead,
+// which is replaced once the process that created it has exited, as far as
+// the platform can tell.
+func Lock(c *CLI, name string) (unlock func() error, err error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, c.Strings.errorf("invalid lock name %q", name)
+	}
+	dir, err := c.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, name+".lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tryLock(f); err != nil {
+		_ = f.Close()
+		if err != errLocked {
+			return nil, err
+		}
+		data, _ := ioutil.ReadFile(path)
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			return nil, c.Strings.errorf("already running (pid %d)", pid)
+		}
+		return nil, c.Strings.errorf("already running")
+	}
+
+	// Record our process ID for the error shown to other processes
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return func() error {
+		_ = f.Truncate(0)
+		if err := unlockFile(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}, nil
+}
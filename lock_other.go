@@ -0,0 +1,48 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// tryLock takes the lock on f by creating f's name plus ".pid" exclusively,
+// because this platform has no advisory file locks. The file records our
+// process ID, so one left behind by a process that has exited is replaced.
+func tryLock(f *os.File) error {
+	path := f.Name() + ".pid"
+	for {
+		lock, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(lock, "%d\n", os.Getpid())
+			if closeErr := lock.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && processRunning(pid) {
+			return errLocked
+		}
+		// Stale, so remove it and try again
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+}
+
+// unlockFile releases the lock taken by tryLock.
+func unlockFile(f *os.File) error {
+	return os.Remove(f.Name() + ".pid")
+}
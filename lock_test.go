@@ -0,0 +1,55 @@
+package cli_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("SHIP_STATE_DIR", dir)
+	defer os.Unsetenv("SHIP_STATE_DIR")
+
+	app := &cli.CLI{Name: "ship"}
+
+	unlock, err := cli.Lock(app, "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf("already running (pid %d)", os.Getpid())
+	if _, err := cli.Lock(app, "migrate"); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+
+	// Other names are locked separately
+	unlockOther, err := cli.Lock(app, "update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unlockOther(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err = cli.Lock(app, "migrate")
+	if err != nil {
+		t.Errorf("Expected the lock to be available after unlock, found %s", err)
+	} else {
+		unlock()
+	}
+
+	if _, err := cli.Lock(app, "../migrate"); err == nil {
+		t.Error("Expected an error for a lock name with a path separator")
+	}
+}
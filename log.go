@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// logTimeFormat is the timestamp at the start of each line in a log file.
+const logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// logWriter copies output to a log file, removing ANSI escape sequences and
+// adding a timestamp to the start of each line. Stdout and stderr share a
+// logWriter, so the log shows their output interleaved the same way the
+// terminal does.
+type logWriter struct {
+	w  io.Writer
+	mu sync.Mutex
+
+	// midLine is set when the last write did not end with a newline.
+	midLine bool
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	text := []byte(StripANSI(string(p)))
+	output := make([]byte, 0, len(text)+len(logTimeFormat)+1)
+	for _, b := range text {
+		if !l.midLine {
+			output = append(output, time.Now().Format(logTimeFormat)...)
+			output = append(output, ' ')
+			l.midLine = true
+		}
+		output = append(output, b)
+		if b == '\n' {
+			l.midLine = false
+		}
+	}
+
+	if _, err := l.w.Write(output); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// openLogFile starts copying the Printer's output to the file at path, which
+// is created if necessary and appended to otherwise. The file stays open until
+// the program exits so errors reported after the command returns, such as by
+// HandleError, are included.
+func (c *CLI) openLogFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return c.Strings.errorf("unable to open log file: %s", err)
+	}
+	if c.logFile != nil {
+		_ = c.logFile.Close()
+	}
+	c.logFile = f
+	c.Printer.Log = f
+	return nil
+}
@@ -0,0 +1,60 @@
+package cli_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestLogFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-log-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.log")
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "testapp",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: stderr},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				RunContext: func(ctx *cli.Context) error {
+					ctx.Printer.Err("deploying ")
+					ctx.Printer.Out("\x1b[32mweb\x1b[0m\n")
+					ctx.Printer.Err("done\n")
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"--log-file", path, "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Output is still written to the terminal as usual
+	if stdout.String() != "\x1b[32mweb\x1b[0m\n" {
+		t.Errorf("Expected %q, found %q", "\x1b[32mweb\x1b[0m\n", stdout.String())
+	}
+	if stderr.String() != "deploying done\n" {
+		t.Errorf("Expected %q, found %q", "deploying done\n", stderr.String())
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := `\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}\S+ `
+	expected := regexp.MustCompile(`^` + timestamp + `deploying web\n` + timestamp + `done\n$`)
+	if !expected.Match(data) {
+		t.Errorf("Expected log matching %q, found %q", expected, string(data))
+	}
+}
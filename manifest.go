@@ -0,0 +1,434 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// wingetManifestVersion is the winget manifest schema WingetManifest writes.
+const wingetManifestVersion = "1.6.0"
+
+// sha256Pattern matches a hex-encoded SHA-256 checksum.
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// archiveExtensions are the file extensions of downloads that are unpacked by
+// package managers rather than installed as the binary itself.
+var archiveExtensions = []string{".zip", ".tar.gz", ".tgz", ".tar.xz", ".txz", ".tar.bz2", ".tbz2", ".tar"}
+
+// Release describes a published version of the program, for generating
+// package manager manifests with HomebrewFormula, ScoopManifest, and
+// WingetManifest. The JSON tags allow a release process to write it to a file
+// for the program, or a small build tool, to read.
+type Release struct {
+	// Version being released. If it is empty CLI.Version is used. A leading
+	// "v" is removed.
+	Version string `json:"version,omitempty"`
+
+	// Description is a one-line description of the program, such as "Deploy
+	// applications to the cloud".
+	Description string `json:"description"`
+
+	// Homepage is the URL of the program's website or repository.
+	Homepage string `json:"homepage"`
+
+	// License is the SPDX identifier of the program's license, such as MIT.
+	License string `json:"license,omitempty"`
+
+	// Publisher is the person or organization that publishes the program. It
+	// is required by winget.
+	Publisher string `json:"publisher,omitempty"`
+
+	// PackageIdentifier is the winget package identifier, such as
+	// "Example.Ship". It defaults to the Publisher, without spaces, and the
+	// program Name joined by a dot.
+	PackageIdentifier string `json:"package_identifier,omitempty"`
+
+	// Artifacts are the downloads for each platform. Each manifest only
+	// includes the platforms its package manager supports: Homebrew uses
+	// darwin and linux, while Scoop and winget use windows.
+	Artifacts []ReleaseArtifact `json:"artifacts"`
+}
+
+// ReleaseArtifact is a download for a single platform.
+type ReleaseArtifact struct {
+	// Platform is the GOOS/GOARCH the artifact was built for, such as
+	// darwin/arm64.
+	Platform string `json:"platform"`
+
+	// URL of the download. It may be an archive (.zip, .tar.gz, and so on)
+	// or the executable itself.
+	URL string `json:"url"`
+
+	// SHA256 is the hex-encoded checksum of the download.
+	SHA256 string `json:"sha256"`
+
+	// Binary is the path of the executable inside an archive. It defaults to
+	// the program Name, with .exe on Windows. For downloads that are not
+	// archives it is ignored.
+	Binary string `json:"binary,omitempty"`
+}
+
+// goos returns the operating system the artifact was built for.
+func (artifact ReleaseArtifact) goos() string {
+	return strings.SplitN(artifact.Platform, "/", 2)[0]
+}
+
+// goarch returns the architecture the artifact was built for.
+func (artifact ReleaseArtifact) goarch() string {
+	if i := strings.Index(artifact.Platform, "/"); i >= 0 {
+		return artifact.Platform[i+1:]
+	}
+	return ""
+}
+
+// archive reports whether the download is an archive that contains the
+// executable.
+func (artifact ReleaseArtifact) archive() bool {
+	name := artifact.filename()
+	for _, extension := range archiveExtensions {
+		if strings.HasSuffix(strings.ToLower(name), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// filename returns the last element of the artifact's URL.
+func (artifact ReleaseArtifact) filename() string {
+	if u, err := url.Parse(artifact.URL); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(artifact.URL)
+}
+
+// binary returns the path of the executable as it is unpacked by a package
+// manager: the Binary inside an archive, or the downloaded file itself.
+func (artifact ReleaseArtifact) binary(name string) string {
+	if !artifact.archive() {
+		return artifact.filename()
+	}
+	if artifact.Binary != "" {
+		return artifact.Binary
+	}
+	if artifact.goos() == "windows" {
+		return name + ".exe"
+	}
+	return name
+}
+
+// version returns the version being released.
+func (release Release) version(c *CLI) string {
+	if release.Version != "" {
+		return strings.TrimPrefix(release.Version, "v")
+	}
+	return strings.TrimPrefix(c.Version, "v")
+}
+
+// artifacts validates release and returns its artifacts for the operating
+// systems in goos, sorted by platform. manager names the package manager in
+// errors.
+func (release Release) artifacts(c *CLI, manager string, goos ...string) ([]ReleaseArtifact, error) {
+	if release.version(c) == "" {
+		return nil, fmt.Errorf("%s: release has no version", manager)
+	}
+	if release.Description == "" {
+		return nil, fmt.Errorf("%s: release has no description", manager)
+	}
+	if release.Homepage == "" {
+		return nil, fmt.Errorf("%s: release has no homepage", manager)
+	}
+
+	var artifacts []ReleaseArtifact
+	seen := map[string]bool{}
+	for _, artifact := range release.Artifacts {
+		if !sha256Pattern.MatchString(artifact.SHA256) {
+			return nil, fmt.Errorf("%s: artifact %q has an invalid SHA256 checksum %q", manager, artifact.Platform, artifact.SHA256)
+		}
+		if artifact.URL == "" {
+			return nil, fmt.Errorf("%s: artifact %q has no URL", manager, artifact.Platform)
+		}
+		included := false
+		for _, name := range goos {
+			included = included || artifact.goos() == name
+		}
+		if !included {
+			continue
+		}
+		if seen[artifact.Platform] {
+			return nil, fmt.Errorf("%s: more than one artifact for %s", manager, artifact.Platform)
+		}
+		seen[artifact.Platform] = true
+		artifacts = append(artifacts, artifact)
+	}
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("%s: release has no artifacts for %s", manager, strings.Join(goos, " or "))
+	}
+
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Platform < artifacts[j].Platform
+	})
+	return artifacts, nil
+}
+
+// HomebrewFormula returns a Homebrew formula that installs the darwin and
+// linux artifacts of release. The formula is named after the program and
+// installs its executable into bin, and its test checks the output of
+// "program --version".
+//
+// Homebrew installs the same executable on every platform, so the artifacts
+// must all unpack to the same Binary path.
+func HomebrewFormula(c *CLI, release Release) (string, error) {
+	artifacts, err := release.artifacts(c, "homebrew", "darwin", "linux")
+	if err != nil {
+		return "", err
+	}
+
+	binary := artifacts[0].binary(c.Name)
+	for _, artifact := range artifacts[1:] {
+		if artifact.binary(c.Name) != binary {
+			return "", fmt.Errorf("homebrew: artifacts must contain the same binary, found %q and %q", binary, artifact.binary(c.Name))
+		}
+	}
+
+	output := fmt.Sprintf("class %s < Formula\n", formulaClass(c.Name))
+	output += fmt.Sprintf("  desc %s\n", rubyString(strings.TrimSuffix(release.Description, ".")))
+	output += fmt.Sprintf("  homepage %s\n", rubyString(release.Homepage))
+	output += fmt.Sprintf("  version %s\n", rubyString(release.version(c)))
+	if release.License != "" {
+		output += fmt.Sprintf("  license %s\n", rubyString(release.License))
+	}
+
+	for _, goos := range []string{"darwin", "linux"} {
+		block := ""
+		for _, artifact := range artifacts {
+			if artifact.goos() != goos {
+				continue
+			}
+			var cpu string
+			switch artifact.goarch() {
+			case "arm64":
+				cpu = "on_arm"
+			case "amd64":
+				cpu = "on_intel"
+			default:
+				return "", fmt.Errorf("homebrew: unsupported platform %q", artifact.Platform)
+			}
+			block += fmt.Sprintf("    %s do\n", cpu)
+			block += fmt.Sprintf("      url %s\n", rubyString(artifact.URL))
+			block += fmt.Sprintf("      sha256 %s\n", rubyString(strings.ToLower(artifact.SHA256)))
+			block += "    end\n"
+		}
+		if block == "" {
+			continue
+		}
+		if goos == "darwin" {
+			output += "\n  on_macos do\n"
+		} else {
+			output += "\n  on_linux do\n"
+		}
+		output += block + "  end\n"
+	}
+
+	output += "\n  def install\n"
+	if binary == c.Name {
+		output += fmt.Sprintf("    bin.install %s\n", rubyString(binary))
+	} else {
+		output += fmt.Sprintf("    bin.install %s => %s\n", rubyString(binary), rubyString(c.Name))
+	}
+	output += "  end\n"
+
+	output += "\n  test do\n"
+	output += fmt.Sprintf("    assert_match version.to_s, shell_output(\"#{bin}/%s --version\")\n", c.Name)
+	output += "  end\n"
+	output += "end\n"
+	return output, nil
+}
+
+// formulaClass returns the Ruby class name Homebrew expects for a formula
+// named name, such as ShipIt for ship-it.
+func formulaClass(name string) string {
+	class := ""
+	upper := true
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '-' || r == '_' || r == '.' || r == ' ':
+			upper = true
+		case r == '+':
+			class += "x"
+		case upper:
+			class += strings.ToUpper(string(r))
+			upper = false
+		default:
+			class += string(r)
+		}
+	}
+	return class
+}
+
+// rubyString returns s as a double-quoted Ruby string literal.
+func rubyString(s string) string {
+	return strings.Replace(strconv.Quote(s), "#{", `\#{`, -1)
+}
+
+// scoopArchitectures maps GOARCH to Scoop's architecture names.
+var scoopArchitectures = map[string]string{
+	"386":   "32bit",
+	"amd64": "64bit",
+	"arm64": "arm64",
+}
+
+// scoopManifest is the JSON structure of a Scoop app manifest.
+type scoopManifest struct {
+	Version      string                       `json:"version"`
+	Description  string                       `json:"description"`
+	Homepage     string                       `json:"homepage"`
+	License      string                       `json:"license,omitempty"`
+	Architecture map[string]scoopArchitecture `json:"architecture"`
+}
+
+type scoopArchitecture struct {
+	URL  string      `json:"url"`
+	Hash string      `json:"hash"`
+	Bin  interface{} `json:"bin"`
+}
+
+// ScoopManifest returns a Scoop app manifest, in JSON, that installs the
+// windows artifacts of release. Executables that are not named after the
+// program are given an alias so the program can be run by its Name.
+func ScoopManifest(c *CLI, release Release) (string, error) {
+	artifacts, err := release.artifacts(c, "scoop", "windows")
+	if err != nil {
+		return "", err
+	}
+
+	manifest := scoopManifest{
+		Version:      release.version(c),
+		Description:  release.Description,
+		Homepage:     release.Homepage,
+		License:      release.License,
+		Architecture: map[string]scoopArchitecture{},
+	}
+	for _, artifact := range artifacts {
+		arch, ok := scoopArchitectures[artifact.goarch()]
+		if !ok {
+			return "", fmt.Errorf("scoop: unsupported platform %q", artifact.Platform)
+		}
+		var bin interface{} = artifact.binary(c.Name)
+		if path.Base(artifact.binary(c.Name)) != c.Name+".exe" {
+			bin = [][]string{{artifact.binary(c.Name), c.Name}}
+		}
+		manifest.Architecture[arch] = scoopArchitecture{
+			URL:  artifact.URL,
+			Hash: strings.ToLower(artifact.SHA256),
+			Bin:  bin,
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// wingetArchitectures maps GOARCH to winget's architecture names.
+var wingetArchitectures = map[string]string{
+	"386":   "x86",
+	"amd64": "x64",
+	"arm":   "arm",
+	"arm64": "arm64",
+}
+
+// WingetManifest returns the multi-file winget manifest that installs the
+// windows artifacts of release, keyed by filename: the version manifest, the
+// installer manifest, and the en-US default locale manifest. Artifacts must
+// be .zip archives or the executable itself, which is installed as a portable
+// command named after the program.
+func WingetManifest(c *CLI, release Release) (map[string]string, error) {
+	artifacts, err := release.artifacts(c, "winget", "windows")
+	if err != nil {
+		return nil, err
+	}
+	if release.Publisher == "" {
+		return nil, fmt.Errorf("winget: release has no publisher")
+	}
+	if release.License == "" {
+		return nil, fmt.Errorf("winget: release has no license")
+	}
+
+	id := release.PackageIdentifier
+	if id == "" {
+		id = strings.Replace(release.Publisher, " ", "", -1) + "." + c.Name
+	}
+	header := "PackageIdentifier: " + yamlString(id) + "\n"
+	header += "PackageVersion: " + yamlString(release.version(c)) + "\n"
+	footer := "ManifestVersion: " + wingetManifestVersion + "\n"
+
+	installer := header + "Installers:\n"
+	for _, artifact := range artifacts {
+		arch, ok := wingetArchitectures[artifact.goarch()]
+		if !ok {
+			return nil, fmt.Errorf("winget: unsupported platform %q", artifact.Platform)
+		}
+		installer += "- Architecture: " + arch + "\n"
+		switch {
+		case strings.HasSuffix(strings.ToLower(artifact.filename()), ".zip"):
+			installer += "  InstallerType: zip\n"
+			installer += "  NestedInstallerType: portable\n"
+			installer += "  NestedInstallerFiles:\n"
+			installer += "  - RelativeFilePath: " + yamlString(strings.Replace(artifact.binary(c.Name), "/", `\`, -1)) + "\n"
+			installer += "    PortableCommandAlias: " + yamlString(c.Name) + "\n"
+		case artifact.archive():
+			return nil, fmt.Errorf("winget: artifact %q must be a .zip archive or an executable", artifact.Platform)
+		default:
+			installer += "  InstallerType: portable\n"
+			installer += "  Commands:\n"
+			installer += "  - " + yamlString(c.Name) + "\n"
+		}
+		installer += "  InstallerUrl: " + yamlString(artifact.URL) + "\n"
+		installer += "  InstallerSha256: " + yamlString(strings.ToUpper(artifact.SHA256)) + "\n"
+	}
+	installer += "ManifestType: installer\n" + footer
+
+	locale := header + "PackageLocale: en-US\n"
+	locale += "Publisher: " + yamlString(release.Publisher) + "\n"
+	locale += "PackageName: " + yamlString(c.Name) + "\n"
+	locale += "PackageUrl: " + yamlString(release.Homepage) + "\n"
+	locale += "License: " + yamlString(release.License) + "\n"
+	locale += "ShortDescription: " + yamlString(release.Description) + "\n"
+	locale += "Moniker: " + yamlString(c.Name) + "\n"
+	locale += "ManifestType: defaultLocale\n" + footer
+
+	version := header + "DefaultLocale: en-US\n"
+	version += "ManifestType: version\n" + footer
+
+	return map[string]string{
+		id + ".yaml":              version,
+		id + ".installer.yaml":    installer,
+		id + ".locale.en-US.yaml": locale,
+	}, nil
+}
+
+// yamlPlain matches strings that can be written in YAML without quotes.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z0-9_/][A-Za-z0-9 _./()+-]*$`)
+
+// yamlString returns s as a YAML scalar, quoting it if it contains special
+// characters or would otherwise be read as a number or boolean.
+func yamlString(s string) string {
+	if yamlPlain.MatchString(s) && !strings.HasSuffix(s, " ") {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			switch strings.ToLower(s) {
+			case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+			default:
+				return s
+			}
+		}
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,252 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+var (
+	sumA = strings.Repeat("a", 64)
+	sumB = strings.Repeat("B", 64)
+	sumC = strings.Repeat("c", 64)
+)
+
+func testRelease() cli.Release {
+	return cli.Release{
+		Description: "Deploy applications to the cloud.",
+		Homepage:    "https://example.com/ship",
+		License:     "MIT",
+		Publisher:   "Example Corp",
+		Artifacts: []cli.ReleaseArtifact{
+			{Platform: "linux/amd64", URL: "https://example.com/ship_1.2.0_linux_amd64.tar.gz", SHA256: sumA},
+			{Platform: "darwin/arm64", URL: "https://example.com/ship_1.2.0_darwin_arm64.tar.gz", SHA256: sumB},
+			{Platform: "windows/amd64", URL: "https://example.com/ship_1.2.0_windows_amd64.zip", SHA256: sumC},
+			{Platform: "windows/arm64", URL: "https://example.com/ship-windows-arm64.exe", SHA256: sumA},
+		},
+	}
+}
+
+func TestHomebrewFormula(t *testing.T) {
+	app := &cli.CLI{Name: "ship", Version: "v1.2.0"}
+
+	expected := `class Ship < Formula
+  desc "Deploy applications to the cloud"
+  homepage "https://example.com/ship"
+  version "1.2.0"
+  license "MIT"
+
+  on_macos do
+    on_arm do
+      url "https://example.com/ship_1.2.0_darwin_arm64.tar.gz"
+      sha256 "` + strings.ToLower(sumB) + `"
+    end
+  end
+
+  on_linux do
+    on_intel do
+      url "https://example.com/ship_1.2.0_linux_amd64.tar.gz"
+      sha256 "` + sumA + `"
+    end
+  end
+
+  def install
+    bin.install "ship"
+  end
+
+  test do
+    assert_match version.to_s, shell_output("#{bin}/ship --version")
+  end
+end
+`
+	output, err := cli.HomebrewFormula(app, testRelease())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Found ---\n%s\n", expected, output)
+	}
+
+	// Executables that are not named after the program are renamed
+	release := testRelease()
+	release.Artifacts = release.Artifacts[:1]
+	release.Artifacts[0].Binary = "ship_1.2.0/ship"
+	output, err = cli.HomebrewFormula(&cli.CLI{Name: "ship-it", Version: "1.2.0"}, release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"class ShipIt < Formula", `bin.install "ship_1.2.0/ship" => "ship-it"`} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected %q in formula, found:\n%s", line, output)
+		}
+	}
+}
+
+func TestScoopManifest(t *testing.T) {
+	app := &cli.CLI{Name: "ship", Version: "1.2.0"}
+
+	expected := `{
+    "version": "1.2.0",
+    "description": "Deploy applications to the cloud.",
+    "homepage": "https://example.com/ship",
+    "license": "MIT",
+    "architecture": {
+        "64bit": {
+            "url": "https://example.com/ship_1.2.0_windows_amd64.zip",
+            "hash": "` + sumC + `",
+            "bin": "ship.exe"
+        },
+        "arm64": {
+            "url": "https://example.com/ship-windows-arm64.exe",
+            "hash": "` + sumA + `",
+            "bin": [
+                [
+                    "ship-windows-arm64.exe",
+                    "ship"
+                ]
+            ]
+        }
+    }
+}
+`
+	output, err := cli.ScoopManifest(app, testRelease())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Found ---\n%s\n", expected, output)
+	}
+}
+
+func TestWingetManifest(t *testing.T) {
+	app := &cli.CLI{Name: "ship", Version: "1.10"}
+
+	manifests, err := cli.WingetManifest(app, testRelease())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"ExampleCorp.ship.yaml": `PackageIdentifier: ExampleCorp.ship
+PackageVersion: "1.10"
+DefaultLocale: en-US
+ManifestType: version
+ManifestVersion: 1.6.0
+`,
+		"ExampleCorp.ship.installer.yaml": `PackageIdentifier: ExampleCorp.ship
+PackageVersion: "1.10"
+Installers:
+- Architecture: x64
+  InstallerType: zip
+  NestedInstallerType: portable
+  NestedInstallerFiles:
+  - RelativeFilePath: ship.exe
+    PortableCommandAlias: ship
+  InstallerUrl: "https://example.com/ship_1.2.0_windows_amd64.zip"
+  InstallerSha256: ` + strings.ToUpper(sumC) + `
+- Architecture: arm64
+  InstallerType: portable
+  Commands:
+  - ship
+  InstallerUrl: "https://example.com/ship-windows-arm64.exe"
+  InstallerSha256: ` + strings.ToUpper(sumA) + `
+ManifestType: installer
+ManifestVersion: 1.6.0
+`,
+		"ExampleCorp.ship.locale.en-US.yaml": `PackageIdentifier: ExampleCorp.ship
+PackageVersion: "1.10"
+PackageLocale: en-US
+Publisher: Example Corp
+PackageName: ship
+PackageUrl: "https://example.com/ship"
+License: MIT
+ShortDescription: Deploy applications to the cloud.
+Moniker: ship
+ManifestType: defaultLocale
+ManifestVersion: 1.6.0
+`,
+	}
+
+	if len(manifests) != len(expected) {
+		t.Errorf("Expected %d manifests, found %d", len(expected), len(manifests))
+	}
+	for name, content := range expected {
+		if manifests[name] != content {
+			t.Errorf("--- Expected %s ---\n%s\n--- Found ---\n%s\n", name, content, manifests[name])
+		}
+	}
+}
+
+func TestManifestErrors(t *testing.T) {
+	app := &cli.CLI{Name: "ship"}
+
+	type TestCase struct {
+		Name     string
+		Modify   func(release *cli.Release)
+		Expected string
+	}
+
+	cases := []TestCase{
+		{
+			"no version",
+			func(release *cli.Release) {},
+			"homebrew: release has no version",
+		},
+		{
+			"bad checksum",
+			func(release *cli.Release) {
+				release.Version = "1.2.0"
+				release.Artifacts[0].SHA256 = "abc"
+			},
+			`homebrew: artifact "linux/amd64" has an invalid SHA256 checksum "abc"`,
+		},
+		{
+			"no platforms",
+			func(release *cli.Release) {
+				release.Version = "1.2.0"
+				release.Artifacts = release.Artifacts[2:]
+			},
+			"homebrew: release has no artifacts for darwin or linux",
+		},
+		{
+			"unsupported architecture",
+			func(release *cli.Release) {
+				release.Version = "1.2.0"
+				release.Artifacts[0].Platform = "linux/386"
+			},
+			`homebrew: unsupported platform "linux/386"`,
+		},
+		{
+			"different binaries",
+			func(release *cli.Release) {
+				release.Version = "1.2.0"
+				release.Artifacts[0].Binary = "bin/ship"
+			},
+			`homebrew: artifacts must contain the same binary, found "ship" and "bin/ship"`,
+		},
+	}
+
+	for _, c := range cases {
+		release := testRelease()
+		c.Modify(&release)
+		if _, err := cli.HomebrewFormula(app, release); err == nil || err.Error() != c.Expected {
+			t.Errorf("%s: Expected %q, found %v", c.Name, c.Expected, err)
+		}
+	}
+
+	release := testRelease()
+	release.Version = "1.2.0"
+	release.Publisher = ""
+	if _, err := cli.WingetManifest(app, release); err == nil || err.Error() != "winget: release has no publisher" {
+		t.Errorf("Expected missing publisher error, found %v", err)
+	}
+
+	release = testRelease()
+	release.Version = "1.2.0"
+	release.Artifacts[2].URL = "https://example.com/ship_windows_amd64.tar.gz"
+	expected := `winget: artifact "windows/amd64" must be a .zip archive or an executable`
+	if _, err := cli.WingetManifest(app, release); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Multiplex runs one of several programs from a single binary, busybox-style.
+// The program is chosen by the name the binary was invoked as, so the binary
+// can be installed under several names, or linked to them, each presenting a
+// different set of commands:
+//
+//	func main() {
+//		err := cli.Multiplex(map[string]*cli.CLI{
+//			"ship":  shipApp,
+//			"shipd":  daemonApp,
+//		})
+//		...
+//	}
+//
+// The name is taken from os.Args[0] without its directory or any .exe
+// extension. If it doesn't match any program, the first argument is used
+// instead, so "multi ship deploy" runs the deploy command of ship. A program
+// with an empty Name is given the name it was selected by.
+func Multiplex(apps map[string]*CLI) error {
+	name, args := programName(os.Args[0]), os.Args[1:]
+	app, ok := apps[name]
+	if !ok && len(args) > 0 {
+		if app, ok = apps[args[0]]; ok {
+			name, args = args[0], args[1:]
+		}
+	}
+	if !ok {
+		names := make([]string, 0, len(apps))
+		for appName := range apps {
+			names = append(names, appName)
+		}
+		sort.Strings(names)
+		// No program was selected, so translate the error with the first
+		// one that has Strings
+		var messages Strings
+		for _, appName := range names {
+			if messages = apps[appName].Strings; messages != nil {
+				break
+			}
+		}
+		return messages.errorf("usage: %s <program> [<args>]\navailable programs: %s", programName(os.Args[0]), strings.Join(names, ", "))
+	}
+
+	if app.Name == "" {
+		app.Name = name
+	}
+	return app.RunArgs(args)
+}
@@ -0,0 +1,66 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestMultiplex(t *testing.T) {
+	ogArgs := os.Args
+	defer func() { os.Args = ogArgs }()
+
+	var ran string
+	newApp := func(name string) *cli.CLI {
+		return &cli.CLI{
+			Printer: &cli.Printer{Stdout: &bytes.Buffer{}},
+			Commands: map[string]*cli.Command{
+				"status": {
+					Run: func(args []string) error {
+						ran = name + " status " + strings.Join(args, " ")
+						return nil
+					},
+				},
+			},
+		}
+	}
+	apps := map[string]*cli.CLI{
+		"ship":  newApp("ship"),
+		"shipd": newApp("shipd"),
+	}
+
+	type TestCase struct {
+		Args     []string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{[]string{"/usr/local/bin/ship", "status", "web"}, "ship status web"},
+		{[]string{"shipd.exe", "status"}, "shipd status "},
+		{[]string{"multi", "ship", "status", "api"}, "ship status api"},
+	}
+
+	for _, c := range cases {
+		ran = ""
+		os.Args = c.Args
+		if err := cli.Multiplex(apps); err != nil {
+			t.Fatal(err)
+		}
+		if ran != c.Expected {
+			t.Errorf("Expected %q, found %q", c.Expected, ran)
+		}
+	}
+
+	if apps["shipd"].Name != "shipd" {
+		t.Errorf("Expected the selected name to be used, found %q", apps["shipd"].Name)
+	}
+
+	os.Args = []string{"multi", "dock"}
+	expected := "usage: multi <program> [<args>]\navailable programs: ship, shipd"
+	if err := cli.Multiplex(apps); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+}
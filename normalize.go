@@ -0,0 +1,115 @@
+package cli
+
+import "strings"
+
+// compositions maps a combining mark to pairs of a base letter and the
+// precomposed letter it forms with the mark. It covers the Latin letters that
+// text editors and keyboards commonly produce in decomposed form; the standard
+// library has no full Unicode normalization tables.
+var compositions = map[rune]string{
+	0x0300: "AÀEÈIÌNǸOÒUÙWẀYỲaàeèiìnǹoòuùwẁyỳ",                                               // grave
+	0x0301: "AÁCĆEÉGǴIÍKḰLĹMḾNŃOÓPṔRŔSŚUÚWẂYÝZŹaácćeégǵiíkḱlĺmḿnńoópṕrŕsśuúwẃyýzź",           // acute
+	0x0302: "AÂCĈEÊGĜHĤIÎJĴOÔSŜUÛWŴYŶZẐaâcĉeêgĝhĥiîjĵoôsŝuûwŵyŷzẑ",                           // circumflex
+	0x0303: "AÃEẼIĨNÑOÕUŨVṼYỸaãeẽiĩnñoõuũvṽyỹ",                                               // tilde
+	0x0304: "AĀEĒGḠIĪOŌUŪYȲaāeēgḡiīoōuūyȳ",                                                   // macron
+	0x0306: "AĂEĔGĞIĬOŎUŬaăeĕgğiĭoŏuŭ",                                                       // breve
+	0x0307: "AȦBḂCĊDḊEĖFḞGĠHḢIİMṀNṄOȮPṖRṘSṠTṪWẆXẊYẎZŻaȧbḃcċdḋeėfḟgġhḣmṁnṅoȯpṗrṙsṡtṫwẇxẋyẏzż", // dot above
+	0x0308: "AÄEËHḦIÏOÖUÜWẄXẌYŸaäeëhḧiïoötẗuüwẅxẍyÿ",                                         // diaeresis
+	0x030A: "AÅUŮaåuůwẘyẙ",                                                                   // ring above
+	0x030B: "OŐUŰoőuű",                                                                       // double acute
+	0x030C: "AǍCČDĎEĚGǦHȞIǏKǨLĽNŇOǑRŘSŠTŤUǓZŽaǎcčdďeěgǧhȟiǐjǰkǩlľnňoǒrřsštťuǔzž",             // caron
+	0x0327: "CÇDḐEȨGĢHḨKĶLĻNŅRŖSŞTŢcçdḑeȩgģhḩkķlļnņrŗsştţ",                                   // cedilla
+	0x0328: "AĄEĘIĮOǪUŲaąeęiįoǫuų",                                                           // ogonek
+}
+
+// invisibleRunes are removed from arguments. They are often pasted along with
+// text copied from documents and web pages, and make arguments that look
+// correct fail to match. Zero width joiners are kept because they are part of
+// emoji sequences and of words in some scripts.
+var invisibleRunes = map[rune]bool{
+	0xFEFF: true, // byte order mark
+	0x200B: true, // zero width space
+	0x2060: true, // word joiner
+}
+
+// smartQuotes converts typographic quotes, which word processors substitute
+// for the quotes the user typed, to ASCII quotes.
+var smartQuotes = strings.NewReplacer("“", `"`, "”", `"`, "„", `"`, "‟", `"`, "‘", "'", "’", "'", "‚", "'", "‛", "'")
+
+// NormalizeArgs cleans up arguments that were copied and pasted from
+// documents, so they match what the user meant to type:
+//
+//   - Byte order marks and zero width spaces are removed
+//   - Letters followed by combining accents are composed into a single
+//     character, as in Unicode NFC, so "é" typed either way is the same
+//   - In flag values, such as --message=“hello”, smart quotes around the
+//     whole value are removed and other smart quotes become ASCII quotes
+//
+// Run calls NormalizeArgs unless CLI.RawArgs is set.
+func NormalizeArgs(args []string) []string {
+	normalized := make([]string, len(args))
+	terminated := false
+	for i, arg := range args {
+		arg = composeMarks(stripInvisible(arg))
+		if arg == "--" {
+			terminated = true
+		}
+		if !terminated && strings.HasPrefix(arg, "-") {
+			if idx := strings.Index(arg, "="); idx > 0 {
+				arg = arg[:idx+1] + unquoteSmart(arg[idx+1:])
+			}
+		}
+		normalized[i] = arg
+	}
+	return normalized
+}
+
+// stripInvisible removes invisibleRunes from s.
+func stripInvisible(s string) string {
+	return strings.Map(func(r rune) rune {
+		if invisibleRunes[r] {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// composeMarks replaces letters followed by combining marks with their
+// precomposed forms, where there is one.
+func composeMarks(s string) string {
+	output := make([]rune, 0, len(s))
+	for _, r := range s {
+		if table, ok := compositions[r]; ok && len(output) > 0 {
+			if composed, ok := compose(table, output[len(output)-1]); ok {
+				output[len(output)-1] = composed
+				continue
+			}
+		}
+		output = append(output, r)
+	}
+	return string(output)
+}
+
+// compose looks up base in a table from compositions.
+func compose(table string, base rune) (rune, bool) {
+	pairs := []rune(table)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] == base {
+			return pairs[i+1], true
+		}
+	}
+	return 0, false
+}
+
+// unquoteSmart removes smart quotes surrounding value and converts any others
+// to ASCII quotes.
+func unquoteSmart(value string) string {
+	runes := []rune(value)
+	if len(runes) >= 2 {
+		first, last := runes[0], runes[len(runes)-1]
+		if (first == '“' || first == '„') && last == '”' || first == '‘' && last == '’' {
+			value = string(runes[1 : len(runes)-1])
+		}
+	}
+	return smartQuotes.Replace(value)
+}
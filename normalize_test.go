@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestNormalizeArgs(t *testing.T) {
+	type TestCase struct {
+		Input    []string
+		Expected []string
+	}
+
+	cases := []TestCase{
+		{
+			Input:    []string{"\ufeffdeploy", "web\u200b"},
+			Expected: []string{"deploy", "web"},
+		},
+		{
+			// e followed by a combining acute accent
+			Input:    []string{"cafe\u0301", "--name=Zoe\u0308"},
+			Expected: []string{"café", "--name=Zoë"},
+		},
+		{
+			Input:    []string{"--message=“fix the build”", "--note=it’s done", "-m=‘quoted’"},
+			Expected: []string{"--message=fix the build", "--note=it's done", "-m=quoted"},
+		},
+		{
+			// Positional arguments and arguments after -- keep their quotes
+			Input:    []string{"“title”", "--", "--message=“text”"},
+			Expected: []string{"“title”", "--", "--message=“text”"},
+		},
+		{
+			// Emoji sequences use zero width joiners
+			Input:    []string{"👩\u200d💻"},
+			Expected: []string{"👩\u200d💻"},
+		},
+	}
+
+	for _, testCase := range cases {
+		if actual := cli.NormalizeArgs(testCase.Input); !reflect.DeepEqual(actual, testCase.Expected) {
+			t.Errorf("Expected %q, found %q", testCase.Expected, actual)
+		}
+	}
+}
+
+func TestRawArgs(t *testing.T) {
+	var received []string
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"echo": {Run: func(args []string) error {
+				received = args
+				return nil
+			}},
+		},
+	}
+
+	if err := app.RunArgs([]string{"echo", "\ufeffa"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(received, []string{"a"}) {
+		t.Errorf("Expected %q, found %q", []string{"a"}, received)
+	}
+
+	app.RawArgs = true
+	if err := app.RunArgs([]string{"echo", "\ufeffa"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(received, []string{"\ufeffa"}) {
+		t.Errorf("Expected %q, found %q", []string{"\ufeffa"}, received)
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"net"
+	"time"
+)
+
+// offlineProbeTimeout limits how long Offline waits to connect to
+// CLI.OfflineProbe.
+const offlineProbeTimeout = 2 * time.Second
+
+// Offline reports whether commands should avoid using the network, so they
+// can skip update checks, telemetry, and other remote calls consistently. It
+// is true if the user passed --offline before the command name, set
+// PROG_OFFLINE=1 in the environment (where PROG is the upper-cased program
+// name), or if the network appears to be unreachable. See CLI.OfflineProbe.
+func Offline(ctx *Context) bool {
+	if ctx == nil || ctx.CLI == nil {
+		return false
+	}
+	return ctx.CLI.isOffline()
+}
+
+// isOffline implements Offline. Detecting an unreachable network is done once
+// per run, because a probe may take a while.
+func (c *CLI) isOffline() bool {
+	if c.offlineRequested() {
+		return true
+	}
+
+	c.offlineMu.Lock()
+	defer c.offlineMu.Unlock()
+	if c.offlineDetected == nil {
+		detected := !c.networkReachable()
+		c.offlineDetected = &detected
+	}
+	return *c.offlineDetected
+}
+
+// offlineRequested reports whether the user asked for offline mode with
+// --offline or PROG_OFFLINE.
+func (c *CLI) offlineRequested() bool {
+	return c.offline || envEnabled(c.envVar("OFFLINE"))
+}
+
+// networkReachable reports whether OfflineProbe accepts a connection or, if
+// it is not set, whether any network interface other than loopback is up
+// with an address.
+func (c *CLI) networkReachable() bool {
+	if c.OfflineProbe != "" {
+		conn, err := net.DialTimeout("tcp", c.OfflineProbe, offlineProbeTimeout)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		// Don't stop commands from trying if we can't tell
+		return true
+	}
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ip, ok := addr.(*net.IPNet); ok && ip.IP.IsGlobalUnicast() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package cli_test
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestOffline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	var offline bool
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"sync": {
+				RunContext: func(ctx *cli.Context) error {
+					offline = cli.Offline(ctx)
+					return nil
+				},
+			},
+		},
+	}
+
+	type TestCase struct {
+		Args     []string
+		Env      string
+		Probe    string
+		Expected bool
+	}
+
+	cases := []TestCase{
+		{[]string{"sync"}, "", listener.Addr().String(), false},
+		{[]string{"--offline", "sync"}, "", listener.Addr().String(), true},
+		{[]string{"sync"}, "1", listener.Addr().String(), true},
+		{[]string{"sync"}, "", closed.Addr().String(), true},
+	}
+
+	for _, c := range cases {
+		os.Setenv("SHIP_OFFLINE", c.Env)
+		app.OfflineProbe = c.Probe
+		if err := app.RunArgs(c.Args); err != nil {
+			t.Fatal(err)
+		}
+		if offline != c.Expected {
+			t.Errorf("Expected offline to be %v with %q, SHIP_OFFLINE=%q, and probe %s", c.Expected, c.Args, c.Env, c.Probe)
+		}
+	}
+	os.Unsetenv("SHIP_OFFLINE")
+
+	if cli.Offline(nil) {
+		t.Error("Expected a nil Context not to be offline")
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONLinesEncoder writes values as JSON Lines (https://jsonlines.org), one
+// JSON object per line. Each value is flushed as soon as it is encoded so list
+// and watch commands can stream results to another program while they are
+// still running, instead of buffering everything until the end.
+type JSONLinesEncoder struct {
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+// NewJSONLinesEncoder returns an encoder that writes to w. If w has a Flush
+// method, such as a *bufio.Writer, it is called after each value is written.
+func NewJSONLinesEncoder(w io.Writer) *JSONLinesEncoder {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &JSONLinesEncoder{
+		w:       w,
+		encoder: encoder,
+	}
+}
+
+// Encode writes v as a single line of JSON followed by a newline, and flushes
+// the underlying writer.
+func (e *JSONLinesEncoder) Encode(v interface{}) error {
+	if err := e.encoder.Encode(v); err != nil {
+		return err
+	}
+
+	switch w := e.w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Flush() }:
+		w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestJSONLinesEncoder(t *testing.T) {
+	type Result struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := bufio.NewWriter(buffer)
+	encoder := cli.NewJSONLinesEncoder(writer)
+
+	if err := encoder.Encode(Result{Name: "web", Status: "<running>"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first result should be visible before we encode the second one
+	expectedFirst := `{"name":"web","status":"<running>"}` + "\n"
+	if buffer.String() != expectedFirst {
+		t.Errorf("Expected %q, found %q", expectedFirst, buffer.String())
+	}
+
+	if err := encoder.Encode(Result{Name: "db", Status: "stopped"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := expectedFirst + `{"name":"db","status":"stopped"}` + "\n"
+	if buffer.String() != expected {
+		t.Errorf("Expected %q, found %q", expected, buffer.String())
+	}
+}
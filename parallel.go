@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Task is a unit of work run by Parallel, such as deploying to one host.
+type Task struct {
+	// Label identifies the task's output, such as a host name.
+	Label string
+
+	// Run performs the task. Output should be written with ctx.Printer,
+	// ctx.Stdout, or ctx.Stderr so it is labeled.
+	Run func(ctx *Context) error
+}
+
+// Parallel runs tasks concurrently, with at most limit running at once, and
+// waits for them to finish. If limit is less than one, all tasks run at once.
+//
+// Each line a task writes is prefixed with its label, so the output of tasks
+// running at the same time can be told apart:
+//
+//	web1 | restarting
+//	db   | migrating
+//	web1 | done
+//
+// Every task is run even if others fail, unless ctx is canceled, in which case
+// tasks that have not started are skipped. If any task fails, the returned
+// error lists the labels and errors of the failed tasks.
+func Parallel(ctx *Context, limit int, tasks ...Task) error {
+	width := 0
+	for _, task := range tasks {
+		if len(task.Label) > width {
+			width = len(task.Label)
+		}
+	}
+
+	var mu sync.Mutex
+	parentStdout := ctx.Printer.StdoutWriter()
+	parentStderr := ctx.Printer.StderrWriter()
+	return runTasks(ctx, limit, tasks, func(task Task, run func(*Printer) error) error {
+		prefix := PadRight(task.Label, width) + " | "
+		stdout := &prefixWriter{w: parentStdout, prefix: prefix, mu: &mu}
+		stderr := &prefixWriter{w: parentStderr, prefix: prefix, mu: &mu}
+		err := run(ctx.Printer.child(stdout, stderr))
+		stdout.flush()
+		stderr.flush()
+		return err
+	})
+}
+
+// ParallelBuffered is like Parallel, but instead of prefixing each line it
+// holds each task's output until the task finishes and then writes it all at
+// once, below a line with the task's label and whether it succeeded. This
+// keeps related lines together, which is easier to read in CI logs, at the
+// cost of not showing output while tasks run.
+func ParallelBuffered(ctx *Context, limit int, tasks ...Task) error {
+	symbols := ctx.Printer.Symbols()
+
+	var mu sync.Mutex
+	parentStdout := ctx.Printer.StdoutWriter()
+	parentStderr := ctx.Printer.StderrWriter()
+	return runTasks(ctx, limit, tasks, func(task Task, run func(*Printer) error) error {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		err := run(ctx.Printer.child(stdout, stderr))
+
+		mu.Lock()
+		defer mu.Unlock()
+		symbol := symbols.Success
+		if err != nil {
+			symbol = symbols.Failure
+		}
+		_, _ = fmt.Fprintf(parentStderr, "%s %s\n", symbol, task.Label)
+		_, _ = parentStderr.Write(stderr.Bytes())
+		_, _ = parentStdout.Write(stdout.Bytes())
+		return err
+	})
+}
+
+// runTasks runs tasks with at most limit at once. Each task is passed to
+// wrap, which calls run with the Printer the task should use.
+func runTasks(ctx *Context, limit int, tasks []Task, wrap func(task Task, run func(*Printer) error) error) error {
+	if limit < 1 || limit > len(tasks) {
+		limit = len(tasks)
+	}
+
+	errs := make([]error, len(tasks))
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, task Task) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = wrap(task, func(printer *Printer) error {
+				if task.Run == nil {
+					return nil
+				}
+				taskCtx := *ctx
+				taskCtx.Printer = printer
+				taskCtx.Stdout = printer.StdoutWriter()
+				taskCtx.Stderr = printer.StderrWriter()
+				return task.Run(&taskCtx)
+			})
+		}(i, task)
+	}
+	wg.Wait()
+
+	var messages Strings
+	if ctx.CLI != nil {
+		messages = ctx.CLI.Strings
+	}
+	var failures []string
+	var failed error
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, tasks[i].Label+": "+err.Error())
+			failed = fmt.Errorf("%s: %w", tasks[i].Label, err)
+		}
+	}
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return failed
+	}
+	return messages.errorf("%d of %d tasks failed: %s", len(failures), len(tasks), strings.Join(failures, "; "))
+}
+
+// child returns a Printer for a task that writes to stdout and stderr, with
+// the same settings as p. Filtering and logging are left to p, which the
+// writers lead to.
+func (p *Printer) child(stdout, stderr io.Writer) *Printer {
+	child := &Printer{Stdout: stdout, Stderr: stderr}
+	if p != nil {
+		child.ASCII = p.ASCII
+		child.Accessible = p.Accessible
+		child.NoColor = p.NoColor
+		child.ProgressJSON = p.ProgressJSON
+		child.Width = p.Width
+	}
+	return child
+}
+
+// prefixWriter writes each line written to it to w with a prefix. Partial
+// lines are held until they are complete, so lines from writers that share
+// mu are not mixed together.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	mu     *sync.Mutex
+	line   []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.line = append(p.line, b...)
+	i := bytes.LastIndexByte(p.line, '\n')
+	if i < 0 {
+		return len(b), nil
+	}
+
+	complete := p.line[:i+1]
+	var output []byte
+	for _, line := range bytes.SplitAfter(complete, []byte("\n")) {
+		if len(line) > 0 {
+			output = append(output, p.prefix...)
+			output = append(output, line...)
+		}
+	}
+	p.line = append([]byte{}, p.line[i+1:]...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.w.Write(output); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// flush writes any partial line that remains, ending it with a newline.
+func (p *prefixWriter) flush() {
+	if len(p.line) > 0 {
+		_, _ = p.Write([]byte("\n"))
+	}
+}
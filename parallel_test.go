@@ -0,0 +1,89 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+// runParallel runs run as the RunContext of a command and returns its output.
+func runParallel(run func(ctx *cli.Context) error) (stdout, stderr string, err error) {
+	outBuffer := &bytes.Buffer{}
+	errBuffer := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: outBuffer, Stderr: errBuffer, ASCII: true},
+		Commands: map[string]*cli.Command{
+			"deploy": {RunContext: run},
+		},
+	}
+	err = app.RunArgs([]string{"deploy"})
+	return outBuffer.String(), errBuffer.String(), err
+}
+
+func TestParallel(t *testing.T) {
+	var running, most int32
+	task := func(label string, err error) cli.Task {
+		return cli.Task{Label: label, Run: func(ctx *cli.Context) error {
+			if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&most) {
+				atomic.StoreInt32(&most, n)
+			}
+			defer atomic.AddInt32(&running, -1)
+			ctx.Printer.Out("restarting\n")
+			ctx.Printer.Out("done")
+			ctx.Printer.Err("warning\n")
+			return err
+		}}
+	}
+
+	stdout, stderr, err := runParallel(func(ctx *cli.Context) error {
+		return cli.Parallel(ctx, 2, task("web1", nil), task("db", errors.New("timed out")), task("web2", nil))
+	})
+
+	if err == nil || err.Error() != "db: timed out" {
+		t.Errorf("Expected %q, found %v", "db: timed out", err)
+	}
+	if most > 2 {
+		t.Errorf("Expected at most 2 tasks at once, found %d", most)
+	}
+
+	// Tasks finish in any order, but each line should be intact
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	sort.Strings(lines)
+	expected := "db   | done\ndb   | restarting\nweb1 | done\nweb1 | restarting\nweb2 | done\nweb2 | restarting"
+	if strings.Join(lines, "\n") != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout)
+	}
+	if strings.Count(stderr, " | warning\n") != 3 {
+		t.Errorf("Expected a warning from each task, found %q", stderr)
+	}
+}
+
+func TestParallelBuffered(t *testing.T) {
+	task := func(label string, err error) cli.Task {
+		return cli.Task{Label: label, Run: func(ctx *cli.Context) error {
+			ctx.Printer.Errf("deploying %s\n", label)
+			return err
+		}}
+	}
+
+	_, stderr, err := runParallel(func(ctx *cli.Context) error {
+		return cli.ParallelBuffered(ctx, 1, task("web1", errors.New("refused")), task("web2", errors.New("refused")))
+	})
+
+	expectedError := "2 of 2 tasks failed: web1: refused; web2: refused"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Expected %q, found %v", expectedError, err)
+	}
+
+	// With a limit of one, tasks run in order
+	expected := "X web1\ndeploying web1\nX web2\ndeploying web2\n"
+	if stderr != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stderr)
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// knownOS lists the values of GOOS that Platforms may name.
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "illumos": true, "ios": true, "js": true, "linux": true,
+	"netbsd": true, "openbsd": true, "plan9": true, "solaris": true,
+	"wasip1": true, "windows": true,
+}
+
+// supported reports whether command can run on this platform. See
+// Command.Platforms.
+func (command *Command) supported() bool {
+	return supportedOn(command.Platforms, runtime.GOOS, runtime.GOARCH)
+}
+
+// supportedOn reports whether platforms includes goos or goos/goarch. An empty
+// list supports every platform.
+func supportedOn(platforms []string, goos, goarch string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, platform := range platforms {
+		if platform == goos || platform == goos+"/"+goarch {
+			return true
+		}
+	}
+	return false
+}
+
+// unsupportedPlatform returns the error for running command, which has the
+// specified name or path, on a platform it does not support. The architecture
+// is included if the command is limited to certain architectures.
+func (c *CLI) unsupportedPlatform(name string, command *Command) error {
+	platform := runtime.GOOS
+	for _, supported := range command.Platforms {
+		if strings.Contains(supported, "/") {
+			platform += "/" + runtime.GOARCH
+			break
+		}
+	}
+	return c.Strings.errorf("'%s' is not supported on %s", name, platform)
+}
+
+// platformErrors returns the problems Validate reports for the Platforms of
+// command, which has the specified name or path.
+func platformErrors(name string, command *Command) (errs []error) {
+	for _, platform := range command.Platforms {
+		goos := strings.SplitN(platform, "/", 2)[0]
+		if !knownOS[goos] || strings.HasSuffix(platform, "/") || strings.Count(platform, "/") > 1 {
+			errs = append(errs, fmt.Errorf("command %q: platform %q is not GOOS or GOOS/GOARCH", name, platform))
+		}
+	}
+	return
+}
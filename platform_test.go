@@ -0,0 +1,51 @@
+package cli_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestPlatforms(t *testing.T) {
+	other := "plan9"
+	if runtime.GOOS == other {
+		other = "linux"
+	}
+
+	run := func(args []string) error { return nil }
+	app := &cli.CLI{
+		Name: "ship",
+		Commands: map[string]*cli.Command{
+			"deploy":  {Summary: "deploy the app", Run: run, Platforms: []string{runtime.GOOS}},
+			"service": {Summary: "manage the service", Run: run, Platforms: []string{other, other + "/amd64"}},
+			"status":  {Summary: "show status", Run: run, Platforms: []string{runtime.GOOS + "/" + runtime.GOARCH}},
+		},
+	}
+
+	output := cli.CommandHelp(app)
+	if !strings.Contains(output, "ship deploy") || !strings.Contains(output, "ship status") {
+		t.Errorf("Expected supported commands to be listed, found %q", output)
+	}
+	if strings.Contains(output, "ship service") {
+		t.Errorf("Expected unsupported commands not to be listed, found %q", output)
+	}
+
+	if err := app.RunArgs([]string{"deploy"}); err != nil {
+		t.Error(err)
+	}
+	expected := "'service' is not supported on " + runtime.GOOS + "/" + runtime.GOARCH
+	if err := app.RunArgs([]string{"service"}); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+
+	for _, err := range app.Validate() {
+		t.Error(err)
+	}
+	app.Commands["status"].Platforms = []string{"macos"}
+	expected = `command "status": platform "macos" is not GOOS or GOOS/GOARCH`
+	if errs := app.Validate(); len(errs) != 1 || errs[0].Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, errs)
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// pluginCompletionTimeout limits how long completion waits for a plugin, so a
+// broken plugin can't hang the user's shell.
+const pluginCompletionTimeout = 2 * time.Second
+
+// pluginDir returns the directory InstallPlugin installs plugins into, inside
+// the StateDir.
+func (c *CLI) pluginDir() (string, error) {
+	dir, err := c.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugins"), nil
+}
+
+// InstallPlugin downloads the plugin executable for the named command from url
+// and installs it, replacing any earlier version, where Run finds it before
+// plugins in PATH. It returns the path of the plugin. The plugin is fetched
+// with Download and must be signed by one of options.PublicKeys, which are
+// required, so plugins are only installed when their signature matches a key
+// built into the program. See CLI.Plugins.
+func InstallPlugin(ctx *Context, name, url string, options *DownloadOptions) (string, error) {
+	c := ctx.CLI
+	if c == nil {
+		return "", errors.New("InstallPlugin requires a Context from a CLI")
+	}
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return "", c.Strings.errorf("invalid plugin name %q", name)
+	}
+	dir, err := c.pluginDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, c.Name+"-"+name)
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	if err := installExecutable(ctx, url, path, options); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// pluginPath returns the path of the plugin executable for the named command,
+// preferring plugins installed by InstallPlugin to those in PATH.
+func (c *CLI) pluginPath(name string) (string, bool) {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	if dir, err := c.pluginDir(); err == nil {
+		if path, err := exec.LookPath(filepath.Join(dir, c.Name+"-"+name)); err == nil {
+			return path, true
+		}
+	}
+	path, err := exec.LookPath(c.Name + "-" + name)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// runPlugin runs a plugin executable with args, connected to the program's
+// stdin, stdout, and stderr.
+func (c *CLI) runPlugin(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Env = c.childEnv()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = c.Printer.StdoutWriter()
+	cmd.Stderr = c.Printer.StderrWriter()
+	return cmd.Run()
+}
+
+// pluginNames returns the names of the installed plugin commands and those
+// found in PATH.
+func (c *CLI) pluginNames() []string {
+	prefix := c.Name + "-"
+	seen := map[string]bool{}
+	names := []string{}
+
+	dirs := filepath.SplitList(os.Getenv("PATH"))
+	if dir, err := c.pluginDir(); err == nil {
+		dirs = append([]string{dir}, dirs...)
+	}
+	for _, dir := range dirs {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, prefix) || entry.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				ext := strings.ToLower(filepath.Ext(name))
+				if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+					continue
+				}
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			} else if entry.Mode()&0111 == 0 {
+				continue
+			}
+			name = strings.TrimPrefix(name, prefix)
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// pluginCompletions asks a plugin to complete words by running
+// "plugin __complete words...". The plugin must respond using the same
+// protocol as the built-in __complete command; see CLI.Plugins.
+func pluginCompletions(path string, words []string) *CompletionResult {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCompletionTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, append([]string{"__complete"}, words...)...).Output()
+	if err != nil {
+		return &CompletionResult{Kind: CompleteNone}
+	}
+	return parseCompletion(output)
+}
+
+// parseCompletion parses output in the __complete protocol, which is produced
+// by CompletionResult.String.
+func parseCompletion(output []byte) *CompletionResult {
+	lines := strings.Split(strings.TrimRight(string(bytes.Replace(output, []byte("\r\n"), []byte("\n"), -1)), "\n"), "\n")
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, ":") {
+		return &CompletionResult{Kind: CompleteNone}
+	}
+
+	fields := strings.Fields(last[1:])
+	result := &CompletionResult{Kind: CompleteNone}
+	if len(fields) > 0 {
+		for kind, directive := range completionDirectives {
+			if directive == fields[0] {
+				result.Kind = kind
+			}
+		}
+		if result.Kind == CompleteFiles {
+			result.Extensions = fields[1:]
+		}
+	}
+	if result.Kind == CompleteValues {
+		for _, line := range lines[:len(lines)-1] {
+			candidate, description := line, ""
+			if i := strings.Index(line, "\t"); i >= 0 {
+				candidate, description = line[:i], line[i+1:]
+			}
+			result.Candidates = append(result.Candidates, candidate)
+			if description != "" {
+				if result.Descriptions == nil {
+					result.Descriptions = map[string]string{}
+				}
+				result.Descriptions[candidate] = description
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+package cli_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+const testPlugin = `#!/bin/sh
+if [ "$1" = "__complete" ]; then
+	printf 'alpha\nbeta\n:values\n'
+	exit 0
+fi
+echo "hello $*"
+`
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test plugin is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "cli-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "ship-hello"), []byte(testPlugin), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "ship-fail"), []byte("#!/bin/sh\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Not executable, so not a plugin
+	if err := ioutil.WriteFile(filepath.Join(dir, "ship-readme"), []byte("text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ogPath := os.Getenv("PATH")
+	defer os.Setenv("PATH", ogPath)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+ogPath)
+
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:       "ship",
+		Completion: true,
+		Plugins:    true,
+		Printer:    &cli.Printer{Stdout: stdout},
+		Commands: map[string]*cli.Command{
+			"deploy": {},
+		},
+	}
+
+	if err := app.RunArgs([]string{"hello", "big", "world"}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello big world\n" {
+		t.Errorf("Expected %q, found %q", "hello big world\n", stdout.String())
+	}
+
+	if err := app.RunArgs([]string{"fail"}); err == nil || err.Error() != "exit status 3" {
+		t.Errorf("Expected %q, found %v", "exit status 3", err)
+	}
+
+	if err := app.RunArgs([]string{"missing"}); err == nil {
+		t.Error("Expected error for missing plugin")
+	}
+
+	type TestCase struct {
+		Words    []string
+		Expected []string
+	}
+
+	cases := []TestCase{
+		{Words: []string{""}, Expected: []string{"completion", "deploy", "fail", "hello", "help"}},
+		{Words: []string{"hello", ""}, Expected: []string{"alpha", "beta"}},
+		{Words: []string{"hello", "b"}, Expected: []string{"beta"}},
+	}
+
+	for _, testCase := range cases {
+		result := cli.Complete(app, testCase.Words)
+		if !reflect.DeepEqual(result.Candidates, testCase.Expected) {
+			t.Errorf("Expected %#v, found %#v with input %q", testCase.Expected, result.Candidates, testCase.Words)
+		}
+	}
+}
+
+func TestInstallPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test plugin is a shell script")
+	}
+
+	publicKey, sign := minisignKey(t, "12345678")
+	key, err := cli.ParsePublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte(testPlugin)
+	signature := sign(content, "file:ship-hello")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ship-hello":
+			w.Write(content)
+		case "/ship-hello.minisig":
+			w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "install-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("SHIP_STATE_DIR", dir)
+	defer os.Unsetenv("SHIP_STATE_DIR")
+
+	var keys []cli.PublicKey
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Plugins: true,
+		Printer: &cli.Printer{Stdout: stdout, Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"install": {RunContext: func(ctx *cli.Context) error {
+				_, err := cli.InstallPlugin(ctx, "hello", server.URL+"/ship-hello", &cli.DownloadOptions{
+					PublicKeys: keys,
+					Retry:      cli.RetryPolicy{Attempts: 1},
+				})
+				return err
+			}},
+		},
+	}
+	path := filepath.Join(dir, "plugins", "ship-hello")
+
+	// Plugins are never installed without checking their signature
+	if err := app.RunArgs([]string{"install"}); err == nil || !strings.Contains(err.Error(), "no public keys") {
+		t.Errorf("Expected an error without public keys, found %v", err)
+	}
+
+	keys = []cli.PublicKey{key}
+	signature = sign([]byte("something else"), "file:ship-hello")
+	if err := app.RunArgs([]string{"install"}); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected a signature error, found %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be installed when the signature doesn't match", path)
+	}
+
+	signature = sign(content, "file:ship-hello")
+	if err := app.RunArgs([]string{"install"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RunArgs([]string{"hello", "world"}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello world\n" {
+		t.Errorf("Expected %q, found %q", "hello world\n", stdout.String())
+	}
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"time"
+)
+
+// prerequisiteTimeout limits how long a version probe may run.
+const prerequisiteTimeout = 10 * time.Second
+
+// versionPattern matches the first version number in a program's version
+// output, such as 20.10.7 in "Docker version 20.10.7, build f0df350".
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// Prerequisite is an external program that a command needs in order to run.
+// See Command.Requires.
+type Prerequisite struct {
+	// Binary is the name of the program, which is looked up in the PATH, such
+	// as "docker".
+	Binary string
+
+	// MinVersion is the oldest version of the program the command works with,
+	// such as "24" or "2.39.1". If it is empty, any version is accepted and
+	// the program is not run.
+	MinVersion string
+
+	// VersionArgs are the arguments that make the program print its version.
+	// The first version number in its output is compared to MinVersion.
+	// Defaults to --version.
+	VersionArgs []string
+
+	// Hint tells the user how to install or upgrade the program, such as
+	// "See https://docs.docker.com/get-docker/". It is added to the error
+	// when the prerequisite is not met.
+	Hint string
+}
+
+// checkPrerequisites returns an error for the first prerequisite in
+// command.Requires that is not met.
+func (c *CLI) checkPrerequisites(command *Command) error {
+	for _, prerequisite := range command.Requires {
+		if err := c.checkPrerequisite(prerequisite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPrerequisite checks that prerequisite is installed and, if it has a
+// MinVersion, that it is new enough.
+func (c *CLI) checkPrerequisite(prerequisite Prerequisite) error {
+	name := prerequisite.Binary
+	hint := ""
+	if prerequisite.Hint != "" {
+		hint = ". " + prerequisite.Hint
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		if prerequisite.MinVersion != "" {
+			return c.Strings.errorf("requires %s >= %s, which was not found in your PATH%s", name, prerequisite.MinVersion, hint)
+		}
+		return c.Strings.errorf("requires %s, which was not found in your PATH%s", name, hint)
+	}
+	if prerequisite.MinVersion == "" {
+		return nil
+	}
+
+	args := prerequisite.VersionArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), prerequisiteTimeout)
+	defer cancel()
+	output, _ := exec.CommandContext(ctx, path, args...).CombinedOutput()
+
+	found := versionPattern.FindString(string(output))
+	if found == "" {
+		return c.Strings.errorf("requires %s >= %s, unable to determine the installed version%s", name, prerequisite.MinVersion, hint)
+	}
+	if compareVersions(found, prerequisite.MinVersion) < 0 {
+		return c.Strings.errorf("requires %s >= %s, found %s%s", name, prerequisite.MinVersion, found, hint)
+	}
+	return nil
+}
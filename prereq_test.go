@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestRequires(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test prerequisite is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "cli-requires")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := "#!/bin/sh\necho \"Docker version 20.10.7, build f0df350\"\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ogPath := os.Getenv("PATH")
+	defer os.Setenv("PATH", ogPath)
+	os.Setenv("PATH", dir)
+
+	type TestCase struct {
+		Prerequisite cli.Prerequisite
+		Expected     string
+	}
+
+	cases := []TestCase{
+		{cli.Prerequisite{Binary: "docker"}, ""},
+		{cli.Prerequisite{Binary: "docker", MinVersion: "20.10"}, ""},
+		{
+			cli.Prerequisite{Binary: "docker", MinVersion: "24"},
+			"requires docker >= 24, found 20.10.7",
+		},
+		{
+			cli.Prerequisite{Binary: "kubectl", Hint: "See https://kubernetes.io/docs/tasks/tools/"},
+			"requires kubectl, which was not found in your PATH. See https://kubernetes.io/docs/tasks/tools/",
+		},
+	}
+
+	for _, c := range cases {
+		ran := false
+		app := &cli.CLI{
+			Name: "ship",
+			Commands: map[string]*cli.Command{
+				"deploy": {
+					Requires: []cli.Prerequisite{c.Prerequisite},
+					Run: func(args []string) error {
+						ran = true
+						return nil
+					},
+				},
+			},
+		}
+
+		err := app.RunArgs([]string{"deploy"})
+		if c.Expected == "" {
+			if err != nil || !ran {
+				t.Errorf("Expected deploy to run, found %v", err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != c.Expected {
+			t.Errorf("Expected %q, found %v", c.Expected, err)
+		}
+		if ran {
+			t.Errorf("Expected deploy not to run when %q", c.Expected)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Printer separates a program's data output from its diagnostic output. Data
+// (the result of a command) is written to stdout using Out, while status
+// messages, warnings, and other diagnostics are written to stderr using Err.
+// Following this convention keeps stdout machine-readable when it is piped to
+// another program or redirected to a file.
+//
+// The zero value writes to os.Stdout and os.Stderr, and it is safe to call
+// Printer methods on a nil *Printer.
+type Printer struct {
+	// Stdout receives data output. Defaults to os.Stdout.
+	Stdout io.Writer
+
+	// Stderr receives diagnostic output. Defaults to os.Stderr.
+	Stderr io.Writer
+
+	// ASCII replaces emoji and other non-ASCII symbols with plain ASCII
+	// equivalents. It is set when the user passes --no-emoji. See Symbols.
+	ASCII bool
+
+	// Accessible makes output friendly to screen readers and other assistive
+	// technology. Color and other ANSI escape sequences are removed from
+	// output, ASCII symbols are used, and components such as spinners and
+	// progress bars print plain status lines instead of redrawing the screen.
+	// It is set when the user passes --accessible or sets PROG_ACCESSIBLE=1.
+	Accessible bool
+
+	// NoColor removes color and other ANSI escape sequences from output, so
+	// commands can always write styled text. It is set when the user passes
+	// --no-color. Output to a file or pipe, rather than a terminal, is always
+	// stripped.
+	NoColor bool
+
+	// ProgressJSON reports progress as JSON events instead of drawing a
+	// progress bar. It is set when the user passes --progress=json. See
+	// Progress.
+	ProgressJSON bool
+
+	// Width overrides the width of the output in columns, which is otherwise
+	// detected by TerminalWidth. It is set when the user passes --width. See
+	// OutputWidth.
+	Width int
+
+	// Log receives a copy of everything written to stdout and stderr, with
+	// ANSI escape sequences removed and a timestamp at the start of each line.
+	// It is set when the user passes --log-file, which is useful for capturing
+	// long interactive sessions for support.
+	Log io.Writer
+
+	// mu guards the writers below, which are created on first use and may be
+	// requested concurrently, such as by a Spinner and Parallel tasks.
+	mu sync.Mutex
+
+	// Filters are kept between writes so escape sequences that are split
+	// across multiple writes are still handled correctly.
+	stdoutFilter *ansiStripper
+	stderrFilter *ansiStripper
+
+	// log is kept between writes to track partial lines.
+	log *logWriter
+
+	// session records output for --record.
+	session *sessionRecorder
+
+	// capture receives a copy of stdout while a command's output is being
+	// cached. See Command.CacheTTL.
+	capture io.Writer
+}
+
+// OutputWidth returns the width of the output in columns: Width if it is set,
+// otherwise TerminalWidth. Pass it to TableOptions.Width so tables honor
+// --width.
+func (p *Printer) OutputWidth() int {
+	if p != nil && p.Width > 0 {
+		return p.Width
+	}
+	return TerminalWidth()
+}
+
+// StdoutWriter returns the writer used for data output.
+func (p *Printer) StdoutWriter() io.Writer {
+	if p == nil {
+		return os.Stdout
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tee(p.filter(p.stdout(), &p.stdoutFilter), "out")
+}
+
+// StderrWriter returns the writer used for diagnostic output.
+func (p *Printer) StderrWriter() io.Writer {
+	if p == nil {
+		return os.Stderr
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tee(p.filter(p.stderr(), &p.stderrFilter), "err")
+}
+
+// stdout returns the destination for data output, without any filter.
+func (p *Printer) stdout() io.Writer {
+	if p != nil && p.Stdout != nil {
+		return p.Stdout
+	}
+	return os.Stdout
+}
+
+// stderr returns the destination for diagnostic output, without any filter.
+func (p *Printer) stderr() io.Writer {
+	if p != nil && p.Stderr != nil {
+		return p.Stderr
+	}
+	return os.Stderr
+}
+
+// styled reports whether the framework should add styles to output written to
+// w: w must be a file that supports color, and NoColor and Accessible must not
+// be set.
+func (p *Printer) styled(w io.Writer) bool {
+	if p != nil && (p.Accessible || p.NoColor) {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && colorEnabled(f)
+}
+
+// filter wraps w to remove styling from output when necessary. p.mu must be
+// held.
+func (p *Printer) filter(w io.Writer, cached **ansiStripper) io.Writer {
+	strip := p.Accessible || p.NoColor
+	if f, ok := w.(*os.File); ok && !colorEnabled(f) {
+		strip = true
+	}
+	if !strip {
+		return w
+	}
+	if *cached == nil || (*cached).w != w {
+		*cached = &ansiStripper{w: w}
+	}
+	return *cached
+}
+
+// tee copies output written to w to the Log, the session recording, and the
+// result cache, if there are any. stream names the output in the recording.
+// p.mu must be held.
+func (p *Printer) tee(w io.Writer, stream string) io.Writer {
+	writers := []io.Writer{w}
+	if p.Log != nil {
+		if p.log == nil || p.log.w != p.Log {
+			p.log = &logWriter{w: p.Log}
+		}
+		writers = append(writers, p.log)
+	}
+	if p.session != nil {
+		writers = append(writers, p.session.writer(stream))
+	}
+	if p.capture != nil && stream == "out" {
+		writers = append(writers, p.capture)
+	}
+	if len(writers) == 1 {
+		return w
+	}
+	return io.MultiWriter(writers...)
+}
+
+// Out writes data to stdout, formatting its operands like fmt.Print.
+func (p *Printer) Out(a ...interface{}) {
+	_, _ = fmt.Fprint(p.StdoutWriter(), a...)
+}
+
+// Outf writes data to stdout, formatting its operands like fmt.Printf.
+func (p *Printer) Outf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(p.StdoutWriter(), format, a...)
+}
+
+// Err writes diagnostics to stderr, formatting its operands like fmt.Print.
+func (p *Printer) Err(a ...interface{}) {
+	_, _ = fmt.Fprint(p.StderrWriter(), a...)
+}
+
+// Errf writes diagnostics to stderr, formatting its operands like fmt.Printf.
+func (p *Printer) Errf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(p.StderrWriter(), format, a...)
+}
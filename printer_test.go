@@ -0,0 +1,215 @@
+package cli_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestPrinter(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	printer := &cli.Printer{
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+
+	printer.Out("name", "\n")
+	printer.Outf("%s=%d\n", "count", 3)
+	printer.Err("working...", "\n")
+	printer.Errf("done in %ds\n", 2)
+
+	expectedStdout := "name\ncount=3\n"
+	if stdout.String() != expectedStdout {
+		t.Errorf("Expected %q, found %q", expectedStdout, stdout.String())
+	}
+
+	expectedStderr := "working...\ndone in 2s\n"
+	if stderr.String() != expectedStderr {
+		t.Errorf("Expected %q, found %q", expectedStderr, stderr.String())
+	}
+}
+
+func TestNilPrinter(t *testing.T) {
+	cleanup, stdout := redirectIO()
+	defer cleanup()
+
+	var printer *cli.Printer
+	printer.Outf("%s\n", "defaults to stdout")
+
+	cleanup() // Cleanup to flush stdout/err to disk
+	output, err := ioutil.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(output) != "defaults to stdout\n" {
+		t.Errorf("Expected %q, found %q", "defaults to stdout\n", string(output))
+	}
+
+	if printer.StderrWriter() != os.Stderr {
+		t.Error("Expected nil printer to default to os.Stderr")
+	}
+}
+
+func TestAccessiblePrinter(t *testing.T) {
+	stdout := &bytes.Buffer{}
+
+	printer := &cli.Printer{
+		Stdout:     stdout,
+		Accessible: true,
+	}
+
+	// Escape sequences split across writes should still be removed
+	printer.Out("\x1b[3")
+	printer.Out("2mdone\x1b[0m\n")
+
+	if stdout.String() != "done\n" {
+		t.Errorf("Expected %q, found %q", "done\n", stdout.String())
+	}
+
+	if printer.Symbols().Success != cli.ASCIISymbols.Success {
+		t.Errorf("Expected ASCII symbols in accessible mode")
+	}
+}
+
+func TestNoColorFlag(t *testing.T) {
+	if value, ok := os.LookupEnv("NO_COLOR"); ok {
+		defer os.Setenv("NO_COLOR", value)
+	} else {
+		defer os.Unsetenv("NO_COLOR")
+	}
+
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "testapp",
+		Printer: &cli.Printer{Stdout: stdout},
+		Commands: map[string]*cli.Command{
+			"status": {
+				RunContext: func(ctx *cli.Context) error {
+					_, err := ctx.Stdout.Write([]byte("\x1b[32mok\x1b[0m\n"))
+					return err
+				},
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"--no-color", "status"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout.String() != "ok\n" {
+		t.Errorf("Expected %q, found %q", "ok\n", stdout.String())
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || app.Printer.NoColor {
+		t.Error("Expected --no-color to only apply to one run")
+	}
+}
+
+func TestNoColorChildEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test hooks are shell scripts")
+	}
+	if value, ok := os.LookupEnv("NO_COLOR"); ok {
+		defer os.Setenv("NO_COLOR", value)
+		os.Unsetenv("NO_COLOR")
+	}
+
+	dir, err := ioutil.TempDir("", "cli-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := filepath.Join(dir, "log")
+	writeHook(t, dir, "pre-status", `echo "NO_COLOR=$NO_COLOR" >> `+log+"\n")
+
+	app := &cli.CLI{
+		Name:    "testapp",
+		Printer: &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}},
+		Hooks:   &cli.Hooks{Dir: dir},
+		Commands: map[string]*cli.Command{
+			"status": {Run: func(args []string) error { return nil }},
+		},
+	}
+	for _, args := range [][]string{{"--no-color", "status"}, {"status"}} {
+		if err := app.RunArgs(args); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := "NO_COLOR=1\nNO_COLOR=\n"
+	if data, err := ioutil.ReadFile(log); err != nil || string(data) != expected {
+		t.Errorf("Expected %q, found %q (%v)", expected, data, err)
+	}
+}
+
+func TestRedirectedPrinter(t *testing.T) {
+	cleanup, stdout := redirectIO()
+	defer cleanup()
+
+	// Output to a file is not a terminal, so styles are always removed
+	printer := &cli.Printer{}
+	printer.Out("\x1b[1mbold\x1b[0m\n")
+
+	cleanup()
+	output, err := ioutil.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(output) != "bold\n" {
+		t.Errorf("Expected %q, found %q", "bold\n", string(output))
+	}
+}
+
+func TestPrinterConcurrentWrites(t *testing.T) {
+	stdout := &lockedBuffer{}
+	log := &lockedBuffer{}
+	printer := &cli.Printer{Stdout: stdout, Stderr: stdout, NoColor: true, Log: log}
+
+	// Spinners and Parallel tasks write from several goroutines at once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			printer.Out("\x1b[1mdone\x1b[0m\n")
+			printer.Err("warning\n")
+		}()
+	}
+	wg.Wait()
+
+	if output := stdout.String(); strings.Count(output, "done\n") != 10 || strings.Contains(output, "\x1b") {
+		t.Errorf("Expected 10 plain lines of output, found %q", output)
+	}
+	if lines := strings.Count(log.String(), "\n"); lines != 20 {
+		t.Errorf("Expected 20 lines in the log, found %d", lines)
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.String()
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// progressBarWidth is the number of cells in a progress bar.
+const progressBarWidth = 20
+
+// ProgressEvent is written to stderr as a line of JSON for each update to a
+// Progress when the user passes --progress=json, so wrapping tools and editor
+// integrations can show progress without parsing a redrawn terminal bar.
+type ProgressEvent struct {
+	// Event is "progress" for an update and "done" when the work is
+	// finished.
+	Event string `json:"event"`
+
+	// Step is the number of steps completed, out of Total.
+	Step  int `json:"step"`
+	Total int `json:"total"`
+
+	// Percent is Step as a percentage of Total, from 0 to 100.
+	Percent int `json:"percent"`
+
+	// Message describes the current step.
+	Message string `json:"message,omitempty"`
+}
+
+// Progress reports the progress of a task with a known number of steps on
+// stderr. In a terminal a progress bar is redrawn in place; when stderr is
+// redirected or the Printer is Accessible a plain line is printed for each
+// update instead, and with --progress=json each update is a ProgressEvent.
+type Progress struct {
+	printer *Printer
+	total   int
+	step    int
+}
+
+// Progress starts reporting the progress of a task with total steps.
+func (p *Printer) Progress(total int) *Progress {
+	return &Progress{printer: p, total: total}
+}
+
+// Update reports that step steps are complete, and describes the current step
+// with message.
+func (p *Progress) Update(step int, message string) {
+	p.step = step
+	p.report("progress", message)
+}
+
+// Done reports that the task is finished. The progress bar, if any, is
+// replaced by message, or cleared if message is empty.
+func (p *Progress) Done(message string) {
+	p.step = p.total
+	p.report("done", message)
+}
+
+// percent returns the completed fraction of the task as a percentage.
+func (p *Progress) percent() int {
+	if p.total <= 0 {
+		return 0
+	}
+	percent := p.step * 100 / p.total
+	if percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+func (p *Progress) report(event, message string) {
+	stderr := p.printer.StderrWriter()
+	percent := p.percent()
+
+	if p.printer != nil && p.printer.ProgressJSON {
+		_ = NewJSONLinesEncoder(stderr).Encode(ProgressEvent{
+			Event:   event,
+			Step:    p.step,
+			Total:   p.total,
+			Percent: percent,
+			Message: message,
+		})
+		return
+	}
+
+	if !p.redraw() {
+		if event == "done" {
+			if message != "" {
+				fmt.Fprintf(stderr, "%s\n", message)
+			}
+			return
+		}
+		fmt.Fprintf(stderr, "[%d/%d] %s\n", p.step, p.total, message)
+		return
+	}
+
+	// Erase the line, then draw the bar unless we're done
+	fmt.Fprint(stderr, "\r\x1b[2K")
+	if event == "done" {
+		if message != "" {
+			fmt.Fprintf(stderr, "%s\n", message)
+		}
+		return
+	}
+	filled := percent * progressBarWidth / 100
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+	fmt.Fprintf(stderr, "[%s] %3d%% %s", bar, percent, message)
+}
+
+// redraw reports whether the progress bar can be drawn in place.
+func (p *Progress) redraw() bool {
+	return p.printer.animate()
+}
+
+// animate reports whether progress bars and spinners can be redrawn in place
+// on stderr, which requires a terminal and is disabled by Accessible.
+func (p *Printer) animate() bool {
+	if p != nil && p.Accessible {
+		return false
+	}
+	f, ok := p.stderr().(*os.File)
+	return ok && isTerminal(f)
+}
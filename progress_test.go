@@ -0,0 +1,57 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestProgress(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	printer := &cli.Printer{Stderr: stderr}
+
+	// stderr is not a terminal, so a line is printed for each update
+	progress := printer.Progress(4)
+	progress.Update(1, "building")
+	progress.Update(3, "uploading")
+	progress.Done("deployed")
+
+	expected := "[1/4] building\n[3/4] uploading\ndeployed\n"
+	if stderr.String() != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stderr.String())
+	}
+}
+
+func TestProgressJSON(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: stderr},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				RunContext: func(ctx *cli.Context) error {
+					progress := ctx.Printer.Progress(3)
+					progress.Update(1, "building")
+					progress.Done("")
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"--progress=json", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"event":"progress","step":1,"total":3,"percent":33,"message":"building"}
+{"event":"done","step":3,"total":3,"percent":100}
+`
+	if stderr.String() != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stderr.String())
+	}
+
+	if err := app.RunArgs([]string{"--progress", "xml", "deploy"}); err == nil {
+		t.Error("Expected an error for an invalid --progress value")
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ArgSpec describes a positional argument accepted by a Command. Positional
+// arguments are matched to ArgSpecs in order.
+type ArgSpec struct {
+	// Name of the argument, shown in prompts and error messages.
+	Name string `json:"name"`
+
+	// Required arguments must be supplied by the user. If a required argument
+	// is missing and CLI.Prompt is enabled in an interactive session, the user
+	// will be asked for it. Otherwise the command fails with an error.
+	Required bool `json:"required,omitempty"`
+
+	// Sensitive arguments, such as passwords or tokens, are not echoed to the
+	// terminal when prompting.
+	Sensitive bool `json:"sensitive,omitempty"`
+
+	// Complete describes how the argument should be completed when the user
+	// presses tab, for programs that enable CLI.Completion.
+	Complete Completion `json:"-"`
+
+	// Glob expands wildcard patterns such as *.txt in the argument to the
+	// matching file names on Windows, where the shell passes them to the
+	// program unexpanded, so file arguments behave the same on every
+	// platform. If the last ArgSpec sets Glob, it also applies to any
+	// additional arguments. See ExpandGlobs.
+	Glob bool `json:"glob,omitempty"`
+}
+
+// Prompt writes label to stderr and reads a single line of input from stdin.
+// The trailing newline is not included in the result.
+func Prompt(label string) (string, error) {
+	return prompt(label, nil)
+}
+
+// prompt implements Prompt, translating its errors with messages.
+func prompt(label string, messages Strings) (string, error) {
+	_, _ = os.Stderr.WriteString(label)
+	return readLine(os.Stdin, messages)
+}
+
+// PromptSecret behaves like Prompt but does not echo the user's input. This is
+// suitable for passwords, tokens, and other sensitive values. PromptSecret
+// returns an error if stdin is not a terminal.
+func PromptSecret(label string) (string, error) {
+	return promptSecret(label, nil)
+}
+
+// promptSecret implements PromptSecret, translating its errors with messages.
+func promptSecret(label string, messages Strings) (string, error) {
+	restore, err := disableEcho(os.Stdin)
+	if err != nil {
+		return "", messages.errorf("unable to read %s without echo: %s", strings.TrimSpace(label), err)
+	}
+
+	_, _ = os.Stderr.WriteString(label)
+	value, err := readLine(os.Stdin, messages)
+
+	// The user's newline was not echoed, so write one to keep the output tidy.
+	_, _ = os.Stderr.WriteString("\n")
+	if restoreErr := restore(); err == nil {
+		err = restoreErr
+	}
+
+	return value, err
+}
+
+// readLine reads from r until a newline is found. We read one byte at a time
+// so we do not consume input intended for a subsequent prompt. Errors are
+// translated with messages.
+func readLine(r io.Reader, messages Strings) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF {
+			if len(line) == 0 {
+				return "", messages.errorf("no input")
+			}
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
+
+// promptValue asks the user for a missing value, masking the input if needed.
+func (c *CLI) promptValue(label string, sensitive bool) (string, error) {
+	if sensitive {
+		return promptSecret(label+": ", c.Strings)
+	}
+	return prompt(label+": ", c.Strings)
+}
+
+// resolveRequired checks that all of the command's required flags and
+// arguments were supplied. Missing values are prompted for when c.Prompt is
+// set and the session is interactive. It returns the updated list of
+// positional arguments.
+func (c *CLI) resolveRequired(command *Command, args []string) ([]string, error) {
+	canPrompt := c.Prompt && interactive()
+
+	if len(command.RequiredFlags) > 0 {
+		if command.Flags == nil {
+			panic("RequiredFlags are specified but Flags is nil")
+		}
+
+		set := map[string]bool{}
+		command.Flags.Visit(func(f *flag.Flag) {
+			set[f.Name] = true
+		})
+
+		sensitive := map[string]bool{}
+		for _, name := range command.SensitiveFlags {
+			sensitive[name] = true
+		}
+
+		for _, name := range command.RequiredFlags {
+			if command.Flags.Lookup(name) == nil {
+				// This is a programmer error so we'll panic, like we do for
+				// invalid command names.
+				panic(fmt.Sprintf("required flag (%q) is not defined", name))
+			}
+			if set[name] {
+				continue
+			}
+			if !canPrompt {
+				return nil, c.Strings.errorf("missing required flag --%s", name)
+			}
+
+			value, err := c.promptValue("--"+name, sensitive[name])
+			if err != nil {
+				return nil, err
+			}
+			if err := command.Flags.Set(name, value); err != nil {
+				return nil, c.Strings.errorf("invalid value %q for flag --%s: %s", value, name, err)
+			}
+		}
+	}
+
+	for idx, spec := range command.Args {
+		if idx < len(args) || !spec.Required {
+			continue
+		}
+		if !canPrompt {
+			return nil, c.Strings.errorf("missing required argument <%s>", spec.Name)
+		}
+
+		value, err := c.promptValue(spec.Name, spec.Sensitive)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	return args, nil
+}
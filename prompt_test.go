@@ -0,0 +1,95 @@
+package cli_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestPrompt(t *testing.T) {
+	ogStdin := os.Stdin
+	defer func() { os.Stdin = ogStdin }()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	os.Stdin = reader
+
+	if _, err := writer.WriteString("chocolate\r\nvanilla\n"); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	for _, expected := range []string{"chocolate", "vanilla"} {
+		value, err := cli.Prompt("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Errorf("Expected %q, found %q", expected, value)
+		}
+	}
+
+	if _, err := cli.Prompt(""); err == nil {
+		t.Error("Expected error when input is exhausted")
+	}
+}
+
+func TestRequiredInputs(t *testing.T) {
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	flags.String("token", "", "API token")
+
+	app := &cli.CLI{
+		Name:   "testapp",
+		Prompt: true,
+		Commands: map[string]*cli.Command{
+			"login": {
+				Flags:          flags,
+				RequiredFlags:  []string{"token"},
+				SensitiveFlags: []string{"token"},
+				Args: []cli.ArgSpec{
+					{Name: "server", Required: true},
+				},
+				Run: func(args []string) error { return nil },
+			},
+		},
+	}
+
+	// Tests do not run in an interactive session, so we should never prompt.
+	type TestCase struct {
+		Args          []string
+		ExpectedError string
+	}
+
+	cases := []TestCase{
+		{
+			Args:          []string{"login", "example.com"},
+			ExpectedError: "missing required flag --token",
+		},
+		{
+			Args:          []string{"login", "--token", "abc123"},
+			ExpectedError: "missing required argument <server>",
+		},
+		{
+			Args: []string{"login", "--token", "abc123", "example.com"},
+		},
+	}
+
+	for _, testCase := range cases {
+
+		err := app.RunArgs(testCase.Args)
+		if testCase.ExpectedError == "" {
+			if err != nil {
+				t.Errorf("Unexpected error %q with input %q", err, testCase.Args)
+			}
+			continue
+		}
+		if err == nil || err.Error() != testCase.ExpectedError {
+			t.Errorf("Expected error %q, found %v with input %q", testCase.ExpectedError, err, testCase.Args)
+		}
+	}
+}
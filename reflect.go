@@ -0,0 +1,404 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FromStruct builds a CLI from a struct whose fields are annotated with tags,
+// for those who prefer declarative definitions over map literals. For example:
+//
+//	type Deploy struct {
+//		Force bool   `flag:"" help:"Skip confirmation"`
+//		Env   string `flag:"env" default:"staging" help:"Environment to deploy to"`
+//		App   string `arg:"app" required:"true"`
+//	}
+//
+//	func (d *Deploy) Run(ctx *cli.Context) error {
+//		...
+//	}
+//
+//	type App struct {
+//		Deploy Deploy `cmd:"" help:"Deploy the application"`
+//	}
+//
+//	app, err := cli.FromStruct(&App{})
+//
+// v must be a pointer to a struct. Each field tagged with cmd becomes a
+// command named by the tag value, or by the field name in kebab-case if the
+// tag is empty. Command fields must be structs, and they are run by calling
+// their Run(*cli.Context) error method with values from the command line
+// stored in their fields. Within a command, the following tags are supported:
+//
+//	flag:"name"       the field is a flag, named after the field if empty
+//	arg:"name"        the field is a positional argument, in field order
+//	help:"text"       usage text for flags, or the summary for a command
+//	default:"value"   the default value for a flag
+//	required:"true"   the flag or argument must be supplied by the user
+//	sensitive:"true"  the flag or argument should be masked
+//
+// Flags and arguments may be strings, bools, numbers, time.Duration, or
+// []string (comma-separated for flags). A []string argument must be the last
+// argument, and collects all remaining positional arguments. A struct field
+// tagged with flag is a group of flags whose names are prefixed with the tag
+// value and a dash, such as --db-host and --db-port. Fields of a command that
+// are tagged with cmd become its subcommands.
+//
+// The returned CLI may be further customized, such as by setting Name and
+// Version, before calling Run.
+func FromStruct(v interface{}) (*CLI, error) {
+	root := reflect.ValueOf(v)
+	if root.Kind() != reflect.Ptr || root.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("FromStruct requires a pointer to a struct, found %T", v)
+	}
+
+	commands, err := commandsFromStruct(root.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return &CLI{Commands: commands}, nil
+}
+
+// contextRunner is implemented by command structs used with FromStruct.
+type contextRunner interface {
+	Run(ctx *Context) error
+}
+
+// commandsFromStruct builds a Command for each field tagged with cmd.
+func commandsFromStruct(parent reflect.Value) (map[string]*Command, error) {
+	commands := map[string]*Command{}
+
+	for i := 0; i < parent.NumField(); i++ {
+		field := parent.Type().Field(i)
+		name, ok := field.Tag.Lookup("cmd")
+		if !ok {
+			continue
+		}
+		if name == "" {
+			name = kebabCase(field.Name)
+		}
+
+		value := parent.Field(i)
+		if value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.Struct {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("command %q (field %s) must be a struct", name, field.Name)
+		}
+
+		command, err := commandFromStruct(name, value)
+		if err != nil {
+			return nil, err
+		}
+		command.Summary = field.Tag.Get("help")
+		commands[name] = command
+	}
+
+	return commands, nil
+}
+
+// commandFromStruct builds a Command from the fields of a command struct.
+func commandFromStruct(name string, value reflect.Value) (*Command, error) {
+	command := &Command{
+		Flags: flag.NewFlagSet(name, flag.ContinueOnError),
+	}
+
+	if err := defineFlags(command, value, ""); err != nil {
+		return nil, fmt.Errorf("command %q: %s", name, err)
+	}
+
+	// Collect positional arguments in field order
+	var args []reflect.Value
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if _, ok := field.Tag.Lookup("cmd"); ok {
+			continue
+		}
+
+		argName, ok := field.Tag.Lookup("arg")
+		if !ok {
+			continue
+		}
+		if argName == "" {
+			argName = kebabCase(field.Name)
+		}
+		if len(args) > 0 && args[len(args)-1].Kind() == reflect.Slice {
+			return nil, fmt.Errorf("command %q: argument %q follows a variadic argument", name, argName)
+		}
+		if !settable(value.Field(i)) {
+			return nil, fmt.Errorf("command %q: argument %q has unsupported type %s", name, argName, field.Type)
+		}
+
+		args = append(args, value.Field(i))
+		command.Args = append(command.Args, ArgSpec{
+			Name:      argName,
+			Required:  field.Tag.Get("required") == "true",
+			Sensitive: field.Tag.Get("sensitive") == "true",
+		})
+	}
+
+	subcommands, err := commandsFromStruct(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(subcommands) > 0 {
+		command.Commands = subcommands
+	}
+
+	runner, ok := value.Addr().Interface().(contextRunner)
+	if !ok {
+		return command, nil
+	}
+
+	command.RunContext = func(ctx *Context) error {
+		var messages Strings
+		if ctx.CLI != nil {
+			messages = ctx.CLI.Strings
+		}
+		for idx, arg := range args {
+			if idx >= len(ctx.Args) {
+				break
+			}
+			if arg.Kind() == reflect.Slice {
+				arg.Set(reflect.ValueOf(append([]string{}, ctx.Args[idx:]...)))
+				break
+			}
+			if err := setField(arg, ctx.Args[idx]); err != nil {
+				return messages.errorf("invalid value %q for argument <%s>: %s", ctx.Args[idx], command.Args[idx].Name, err)
+			}
+		}
+		return runner.Run(ctx)
+	}
+
+	return command, nil
+}
+
+// defineFlags defines a flag on command.Flags for each field in value tagged
+// with flag, recursing into flag groups.
+func defineFlags(command *Command, value reflect.Value, prefix string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, ok := field.Tag.Lookup("flag")
+		if !ok {
+			continue
+		}
+		if name == "" {
+			name = kebabCase(field.Name)
+		}
+		name = prefix + name
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			if err := defineFlags(command, fieldValue, name+"-"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !settable(fieldValue) {
+			return fmt.Errorf("flag --%s has unsupported type %s", name, field.Type)
+		}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := setField(fieldValue, def); err != nil {
+				return fmt.Errorf("invalid default %q for flag --%s: %s", def, name, err)
+			}
+		}
+
+		command.Flags.Var(&reflectValue{fieldValue}, name, field.Tag.Get("help"))
+		if field.Tag.Get("required") == "true" {
+			command.RequiredFlags = append(command.RequiredFlags, name)
+		}
+		if field.Tag.Get("sensitive") == "true" {
+			command.SensitiveFlags = append(command.SensitiveFlags, name)
+		}
+	}
+
+	return nil
+}
+
+// reflectValue is a flag.Value that stores its value in a struct field.
+type reflectValue struct {
+	v reflect.Value
+}
+
+func (r *reflectValue) String() string {
+	// The flag package calls String on a zero reflectValue to determine
+	// whether a default value is worth displaying.
+	if r == nil || !r.v.IsValid() {
+		return ""
+	}
+	if r.v.Kind() == reflect.Slice {
+		return strings.Join(r.v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(r.v.Interface())
+}
+
+func (r *reflectValue) Set(value string) error {
+	return setField(r.v, value)
+}
+
+func (r *reflectValue) IsBoolFlag() bool {
+	return r.v.IsValid() && r.v.Kind() == reflect.Bool
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// settable reports whether setField supports the type of v.
+func settable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.CanSet()
+	case reflect.Slice:
+		return v.CanSet() && v.Type().Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// setField parses value according to the type of v and stores the result in v.
+func setField(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var list commaList
+		if err := list.Set(value); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf([]string(list)))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// kebabCase converts a Go field name such as DryRun or APIKey to a command-line
+// name such as dry-run or api-key.
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var output []rune
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at the beginning of a capitalized word, or at
+			// the end of an acronym (the K in APIKey)
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				output = append(output, '-')
+			}
+			r = unicode.ToLower(r)
+		}
+		output = append(output, r)
+	}
+	return string(output)
+}
+
+// Bind copies the values of parsed flags into the fields of the struct pointed
+// to by v, so command bodies don't need to look up each flag individually.
+// Fields are matched to flags by their flag tag, or by the field name in
+// kebab-case (DryRun matches --dry-run). Fields of struct type are flag groups
+// whose flags are prefixed with the group name and a dash, so a DB field
+// containing Host matches --db-host. Fields without a matching flag are left
+// unchanged.
+func Bind(set *flag.FlagSet, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Bind requires a pointer to a struct, found %T", v)
+	}
+	return bindFlags(set, value.Elem(), "")
+}
+
+// Bind copies the values of the command's parsed flags into v. See Bind.
+func (ctx *Context) Bind(v interface{}) error {
+	if ctx.Flags == nil {
+		return fmt.Errorf("command %q has no flags to bind", ctx.Name)
+	}
+	return Bind(ctx.Flags, v)
+}
+
+func bindFlags(set *flag.FlagSet, value reflect.Value, prefix string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		name, ok := field.Tag.Lookup("flag")
+		if name == "-" {
+			continue
+		}
+		if !ok || name == "" {
+			name = kebabCase(field.Name)
+		}
+		name = prefix + name
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			if err := bindFlags(set, fieldValue, name+"-"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f := set.Lookup(name)
+		if f == nil {
+			continue
+		}
+
+		// Prefer the typed value when the flag provides one
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if got := reflect.ValueOf(getter.Get()); got.IsValid() && got.Type().AssignableTo(fieldValue.Type()) {
+				fieldValue.Set(got)
+				continue
+			}
+		}
+
+		if !settable(fieldValue) {
+			return fmt.Errorf("flag --%s cannot be stored in field %s of type %s", name, field.Name, field.Type)
+		}
+		if err := setField(fieldValue, f.Value.String()); err != nil {
+			return fmt.Errorf("flag --%s cannot be stored in field %s: %s", name, field.Name, err)
+		}
+	}
+	return nil
+}
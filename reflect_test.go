@@ -0,0 +1,183 @@
+package cli_test
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+type deployCommand struct {
+	DryRun  bool          `flag:"" help:"Show what would happen"`
+	Env     string        `flag:"env" default:"staging" help:"Environment to deploy to"`
+	Timeout time.Duration `flag:"" default:"30s"`
+	Tags    []string      `flag:"tag"`
+	DB      struct {
+		Host string `flag:"" default:"localhost"`
+		Port int    `flag:"" default:"5432"`
+	} `flag:"db"`
+
+	App     string   `arg:"" required:"true"`
+	Targets []string `arg:"targets"`
+
+	ran bool
+}
+
+func (d *deployCommand) Run(ctx *cli.Context) error {
+	d.ran = true
+	return nil
+}
+
+type statusCommand struct{}
+
+type testStructApp struct {
+	Deploy deployCommand  `cmd:"" help:"Deploy the application"`
+	Status *statusCommand `cmd:"status" help:"Show status"`
+	Ignore string
+}
+
+func TestFromStruct(t *testing.T) {
+	definition := &testStructApp{}
+	app, err := cli.FromStruct(definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Name = "shipit"
+
+	if len(app.Commands) != 2 {
+		t.Fatalf("Expected 2 commands, found %d", len(app.Commands))
+	}
+	if app.Commands["deploy"].Summary != "Deploy the application" {
+		t.Errorf("Expected summary from help tag, found %q", app.Commands["deploy"].Summary)
+	}
+
+	usage := cli.CommandUsage(app, "deploy")
+	if usage != "shipit deploy [flags] <app> [<targets>]" {
+		t.Errorf("Unexpected usage %q", usage)
+	}
+
+	if err := app.RunArgs([]string{"deploy", "--dry-run", "--db-port", "6543", "--tag", "a,b", "web", "east", "west"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := definition.Deploy
+	if !deploy.ran {
+		t.Fatal("Expected Run to be called")
+	}
+	if !deploy.DryRun || deploy.Env != "staging" || deploy.Timeout != 30*time.Second {
+		t.Errorf("Unexpected flag values: %+v", deploy)
+	}
+	if deploy.DB.Host != "localhost" || deploy.DB.Port != 6543 {
+		t.Errorf("Unexpected flag group values: %+v", deploy.DB)
+	}
+	if !reflect.DeepEqual(deploy.Tags, []string{"a", "b"}) {
+		t.Errorf("Expected %#v, found %#v", []string{"a", "b"}, deploy.Tags)
+	}
+	if deploy.App != "web" || !reflect.DeepEqual(deploy.Targets, []string{"east", "west"}) {
+		t.Errorf("Unexpected argument values: %q %#v", deploy.App, deploy.Targets)
+	}
+
+	if err := app.RunArgs([]string{"status"}); err != cli.ErrNotImplemented {
+		t.Errorf("Expected %q, found %v", cli.ErrNotImplemented, err)
+	}
+}
+
+type releaseCreateCommand struct {
+	Version string `arg:"" required:"true"`
+}
+
+func (r *releaseCreateCommand) Run(ctx *cli.Context) error {
+	return nil
+}
+
+func TestFromStructSubcommands(t *testing.T) {
+	definition := &struct {
+		Release struct {
+			Create releaseCreateCommand `cmd:"" help:"Create a release"`
+		} `cmd:"" help:"Manage releases"`
+	}{}
+	app, err := cli.FromStruct(definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Name = "shipit"
+
+	create := app.Commands["release"].Commands["create"]
+	if create == nil || create.Summary != "Create a release" {
+		t.Fatalf("Expected release create subcommand, found %+v", create)
+	}
+
+	if err := app.RunArgs([]string{"release", "create", "1.2.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if definition.Release.Create.Version != "1.2.0" {
+		t.Errorf("Expected %q, found %q", "1.2.0", definition.Release.Create.Version)
+	}
+}
+
+func TestFromStructErrors(t *testing.T) {
+	if _, err := cli.FromStruct(testStructApp{}); err == nil {
+		t.Error("Expected error for non-pointer")
+	}
+
+	type badFlag struct {
+		Handler func() `flag:""`
+	}
+	type badApp struct {
+		Bad badFlag `cmd:""`
+	}
+	if _, err := cli.FromStruct(&badApp{}); err == nil {
+		t.Error("Expected error for unsupported flag type")
+	}
+}
+
+func TestBind(t *testing.T) {
+	set := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	set.Bool("dry-run", false, "")
+	set.String("environment", "staging", "")
+	set.Duration("timeout", time.Second, "")
+	set.String("columns", "", "")
+	set.String("db-host", "localhost", "")
+	set.Uint("db-port", 5432, "")
+	if err := set.Parse([]string{"-dry-run", "-timeout", "5s", "-columns", "a,b", "-db-port", "6543"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var opts struct {
+		DryRun  bool
+		Env     string `flag:"environment"`
+		Timeout time.Duration
+		Columns []string
+		Skipped string `flag:"-"`
+		Missing int
+		DB      struct {
+			Host string
+			Port int
+		}
+	}
+	if err := cli.Bind(set, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if !opts.DryRun || opts.Env != "staging" || opts.Timeout != 5*time.Second {
+		t.Errorf("Unexpected values: %+v", opts)
+	}
+	if !reflect.DeepEqual(opts.Columns, []string{"a", "b"}) {
+		t.Errorf("Expected %#v, found %#v", []string{"a", "b"}, opts.Columns)
+	}
+	if opts.DB.Host != "localhost" || opts.DB.Port != 6543 {
+		t.Errorf("Unexpected group values: %+v", opts.DB)
+	}
+
+	var wrong struct {
+		DryRun func()
+	}
+	if err := cli.Bind(set, &wrong); err == nil {
+		t.Error("Expected error for unsupported field type")
+	}
+	if err := cli.Bind(set, opts); err == nil {
+		t.Error("Expected error for non-pointer")
+	}
+}
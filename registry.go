@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AddCommand registers a command under name. Unlike modifying Commands
+// directly, AddCommand is safe to call at any time, including from another
+// goroutine while Run is in progress, which is useful when embedding a CLI in a
+// long-lived program such as a REPL or server.
+//
+// The command is validated immediately: name must be non-empty, must not
+// contain spaces, and must not already be registered.
+//
+// AddCommand fails after Compile has been called.
+func (c *CLI) AddCommand(name string, command *Command) error {
+	if name == "" {
+		return fmt.Errorf("command name must not be empty")
+	}
+	if strings.ContainsAny(name, " \n\t") {
+		return fmt.Errorf("command names (%q) must not contain spaces", name)
+	}
+	if command == nil {
+		return fmt.Errorf("command %q must not be nil", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.compiled != nil {
+		return fmt.Errorf("cannot add command %q to a compiled CLI", name)
+	}
+	if _, ok := c.Commands[name]; ok {
+		return fmt.Errorf("command %q is already defined", name)
+	}
+	if c.Commands == nil {
+		c.Commands = map[string]*Command{}
+	}
+	c.Commands[name] = command
+	c.listing = nil
+	return nil
+}
+
+// RemoveCommand unregisters the named command, returning false if it was not
+// registered or the CLI has been compiled. It is safe to call concurrently with
+// Run and AddCommand.
+func (c *CLI) RemoveCommand(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.compiled != nil {
+		return false
+	}
+	if _, ok := c.Commands[name]; !ok {
+		return false
+	}
+	delete(c.Commands, name)
+	c.listing = nil
+	return true
+}
+
+// AddLazyCommand registers a command that is constructed by factory only when
+// it is needed: when the command is invoked, or when its help page, version, or
+// other details are displayed. This keeps startup fast for programs with many
+// commands that are expensive to construct, such as those that pull in heavy
+// dependencies.
+//
+// summary is shown in command help so the command list can be displayed
+// without calling factory. If the Command returned by factory has no Summary,
+// summary is used. factory is called at most once.
+func (c *CLI) AddLazyCommand(name, summary string, factory func() *Command) error {
+	if factory == nil {
+		return fmt.Errorf("command %q must not have a nil factory", name)
+	}
+	return c.AddCommand(name, &Command{
+		Summary: summary,
+		lazy:    &lazyCommand{factory: factory},
+	})
+}
+
+// lazyCommand holds the factory for a command registered by AddLazyCommand.
+type lazyCommand struct {
+	once    sync.Once
+	factory func() *Command
+	command *Command
+}
+
+// resolve returns the command constructed by factory, calling it if needed, and
+// replaces the placeholder in Commands so later lookups skip the factory.
+func (c *CLI) resolve(name string, placeholder *Command) *Command {
+	if placeholder == nil || placeholder.lazy == nil {
+		return placeholder
+	}
+
+	lazy := placeholder.lazy
+	lazy.once.Do(func() {
+		lazy.command = lazy.factory()
+		if lazy.command == nil {
+			lazy.command = &Command{}
+		}
+		if lazy.command.Summary == "" {
+			lazy.command.Summary = placeholder.Summary
+		}
+	})
+
+	c.mu.Lock()
+	if c.Commands[name] == placeholder {
+		c.Commands[name] = lazy.command
+		c.listing = nil
+	}
+	c.mu.Unlock()
+
+	return lazy.command
+}
+
+// lookup returns the named command, constructing it if it was registered by
+// AddLazyCommand.
+func (c *CLI) lookup(name string) (*Command, bool) {
+	c.mu.RLock()
+	if c.compiled != nil {
+		defer c.mu.RUnlock()
+		command, ok := c.compiled.commands[name]
+		return command, ok
+	}
+	command, ok := c.Commands[name]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return c.resolve(name, command), true
+}
+
+// commands returns a copy of Commands that is safe to iterate while other
+// goroutines add or remove commands. The result must not be modified.
+func (c *CLI) commands() map[string]*Command {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// The compiled tree is never modified so it does not need to be copied
+	if c.compiled != nil {
+		return c.compiled.commands
+	}
+
+	commands := make(map[string]*Command, len(c.Commands))
+	for name, command := range c.Commands {
+		commands[name] = command
+	}
+	return commands
+}
+
+// commandListing caches the sorted command names and column widths used to
+// render command help and complete command names, so they are computed once
+// rather than each time. The cache is cleared whenever commands are added or
+// removed.
+type commandListing struct {
+	names []string
+	order SortOrder
+
+	// width is the length of the longest command name shown in command help,
+	// and experimentalWidth is the same when experimental commands are shown.
+	width             int
+	experimentalWidth int
+}
+
+// commandListing returns the cached listing, computing it if needed.
+func (c *CLI) commandListing() *commandListing {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	commands := c.Commands
+	if c.compiled != nil {
+		commands = c.compiled.commands
+	}
+
+	// Also catch commands added or removed by modifying Commands directly
+	if c.listing != nil && len(c.listing.names) == len(commands) && c.listing.order == c.SortOrder {
+		return c.listing
+	}
+
+	listing := &commandListing{names: c.sortedCommandNames(commands), order: c.SortOrder}
+	for _, name := range listing.names {
+		command := commands[name]
+		if command == nil || command.Hidden || command.HelpOnly {
+			continue
+		}
+		if len(name) > listing.experimentalWidth {
+			listing.experimentalWidth = len(name)
+		}
+		if !command.Experimental && len(name) > listing.width {
+			listing.width = len(name)
+		}
+	}
+
+	c.listing = listing
+	return listing
+}
+
+// nameWidth returns the length of the longest command name shown in command
+// help.
+func (c *CLI) nameWidth() int {
+	if c.ExperimentalEnabled() {
+		return c.commandListing().experimentalWidth
+	}
+	return c.commandListing().width
+}
+
+// resolvedCommands is like commands, but constructs any lazy commands first.
+// It is used where every command's details are needed, such as for the list
+// of help topics.
+func (c *CLI) resolvedCommands() map[string]*Command {
+	commands := c.commands()
+	if c.tree() != nil {
+		return commands
+	}
+	for name, command := range commands {
+		commands[name] = c.resolve(name, command)
+	}
+	return commands
+}
@@ -0,0 +1,157 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestAddCommand(t *testing.T) {
+	app := &cli.CLI{Name: "prog"}
+
+	if err := app.AddCommand("status", &cli.Command{Summary: "Show status"}); err != nil {
+		t.Fatal(err)
+	}
+	if app.Commands["status"] == nil {
+		t.Fatal("Expected status command to be registered")
+	}
+
+	type TestCase struct {
+		Name    string
+		Command *cli.Command
+		Error   string
+	}
+
+	cases := []TestCase{
+		{Name: "", Command: &cli.Command{}, Error: "command name must not be empty"},
+		{Name: "two words", Command: &cli.Command{}, Error: `command names ("two words") must not contain spaces`},
+		{Name: "nil", Command: nil, Error: `command "nil" must not be nil`},
+		{Name: "status", Command: &cli.Command{}, Error: `command "status" is already defined`},
+	}
+
+	for _, testCase := range cases {
+		err := app.AddCommand(testCase.Name, testCase.Command)
+		if err == nil || err.Error() != testCase.Error {
+			t.Errorf("Expected %q, found %v", testCase.Error, err)
+		}
+	}
+
+	if !app.RemoveCommand("status") {
+		t.Error("Expected status to be removed")
+	}
+	if app.RemoveCommand("status") {
+		t.Error("Expected second removal to report false")
+	}
+}
+
+func TestAddCommandConcurrent(t *testing.T) {
+	cleanup, _ := redirectIO()
+	defer cleanup()
+
+	app := &cli.CLI{Name: "prog"}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("command-%d", i)
+			if err := app.AddCommand(name, &cli.Command{}); err != nil {
+				t.Error(err)
+			}
+			app.RemoveCommand(name)
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		if err := app.RunArgs([]string{"--help"}); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Wait()
+
+	if len(app.Commands) != 0 {
+		t.Errorf("Expected all commands to be removed, found %d", len(app.Commands))
+	}
+}
+
+func TestAddLazyCommand(t *testing.T) {
+	cleanup, _ := redirectIO()
+	defer cleanup()
+
+	calls := 0
+	ran := false
+	app := &cli.CLI{Name: "prog"}
+	err := app.AddLazyCommand("heavy", "Does heavy things", func() *cli.Command {
+		calls++
+		return &cli.Command{
+			Run: func(args []string) error {
+				ran = true
+				return nil
+			},
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.RunArgs([]string{"--help"}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected command help not to construct the command, found %d calls", calls)
+	}
+	if output := cli.CommandHelp(app); !strings.Contains(output, "prog heavy   Does heavy things") {
+		t.Errorf("Expected summary in command help, found:\n%s", output)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := app.RunArgs([]string{"heavy"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !ran || calls != 1 {
+		t.Errorf("Expected command to be constructed once and run, found %d calls", calls)
+	}
+	if app.Commands["heavy"].Summary != "Does heavy things" {
+		t.Errorf("Expected summary to carry over, found %q", app.Commands["heavy"].Summary)
+	}
+
+	if err := app.AddLazyCommand("nil", "", nil); err == nil {
+		t.Error("Expected error for nil factory")
+	}
+}
+
+func TestCommandListingCache(t *testing.T) {
+	app := &cli.CLI{
+		Name: "prog",
+		Commands: map[string]*cli.Command{
+			"status": {Summary: "Show status"},
+		},
+	}
+
+	if output := cli.CommandHelp(app); !strings.Contains(output, "prog status   Show status") {
+		t.Errorf("Expected status in command help, found:\n%s", output)
+	}
+
+	// Adding a longer command invalidates the cached names and widths
+	if err := app.AddCommand("deploy-all", &cli.Command{Summary: "Deploy everything"}); err != nil {
+		t.Fatal(err)
+	}
+	output := cli.CommandHelp(app)
+	if !strings.Contains(output, "prog deploy-all   Deploy everything\n  prog status       Show status") {
+		t.Errorf("Expected updated command help, found:\n%s", output)
+	}
+
+	app.RemoveCommand("deploy-all")
+	if output := cli.CommandHelp(app); !strings.Contains(output, "prog status   Show status") {
+		t.Errorf("Expected original command help, found:\n%s", output)
+	}
+
+	completion := cli.Complete(app, []string{""}).Candidates
+	if len(completion) != 2 || completion[0] != "help" || completion[1] != "status" {
+		t.Errorf("Unexpected completion candidates %q", completion)
+	}
+}
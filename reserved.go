@@ -0,0 +1,150 @@
+package cli
+
+import "fmt"
+
+// ReservedNames renames or gives up the names the framework reserves for its
+// built-in commands and flags, for programs that need them for something
+// else. See CLI.Reserved.
+type ReservedNames struct {
+	// Help is the name of the built-in help command, such as "hilfe" in a
+	// German program. Defaults to "help".
+	Help string
+
+	// HelpAliases are additional names for the help command. They invoke it
+	// just like Help but are not listed in the command list.
+	HelpAliases []string
+
+	// HelpFlag is the name of the flag, without dashes, that shows the
+	// command list or a command's help page. Defaults to "help".
+	HelpFlag string
+
+	// VersionFlag is the name of the flag, without dashes, that shows the
+	// program's version. Defaults to "version".
+	VersionFlag string
+
+	// Reclaim lists the names of built-in commands, such as "changelog", that
+	// the program's own commands take precedence over. The built-in command
+	// is still available if the program does not define a command with that
+	// name.
+	Reclaim []string
+}
+
+// builtinCommands are the names of the built-in commands before they are
+// renamed by ReservedNames.
+var builtinCommands = []string{"help", "completion", "__complete", "alias", "shell-init", "changelog", "licenses", "about", "examples", "tutorial", "jobs"}
+
+// reserved returns c.Reserved with defaults filled in.
+func (c *CLI) reserved() ReservedNames {
+	names := ReservedNames{Help: "help", HelpFlag: "help", VersionFlag: "version"}
+	if c.Reserved == nil {
+		return names
+	}
+	if c.Reserved.Help != "" {
+		names.Help = c.Reserved.Help
+	}
+	if c.Reserved.HelpFlag != "" {
+		names.HelpFlag = c.Reserved.HelpFlag
+	}
+	if c.Reserved.VersionFlag != "" {
+		names.VersionFlag = c.Reserved.VersionFlag
+	}
+	names.HelpAliases = c.Reserved.HelpAliases
+	names.Reclaim = c.Reserved.Reclaim
+	return names
+}
+
+// builtinKey returns the original name of the built-in command or flag that
+// name invokes, such as "help" or "--version", or an empty string if name
+// does not refer to a built-in. It takes renamed and reclaimed names into
+// account, as well as DisableHelp and DisableVersion, but not whether other
+// built-in commands are enabled.
+func (c *CLI) builtinKey(name string) string {
+	names := c.reserved()
+	for _, reclaimed := range names.Reclaim {
+		if name == reclaimed {
+			if _, ok := c.lookup(name); ok {
+				return ""
+			}
+		}
+	}
+
+	switch name {
+	case "":
+		return ""
+	case names.Help, "--" + names.HelpFlag:
+		if c.DisableHelp {
+			return ""
+		}
+		if name == names.Help {
+			return "help"
+		}
+		return "--help"
+	case "--" + names.VersionFlag:
+		if c.DisableVersion {
+			return ""
+		}
+		return "--version"
+	case "help", "--help", "--version":
+		// These have been renamed, but "help" may still be an alias
+		if name != "help" || !contains(names.HelpAliases, name) {
+			return ""
+		}
+	}
+	if contains(names.HelpAliases, name) && !c.DisableHelp {
+		return "help"
+	}
+	for _, builtin := range builtinCommands {
+		if name == builtin {
+			return builtin
+		}
+	}
+	return ""
+}
+
+// contains reports whether list includes s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateReserved returns the problems with c.Reserved for Validate.
+func (c *CLI) validateReserved() (errs []error) {
+	if c.Reserved == nil {
+		return nil
+	}
+	names := c.reserved()
+
+	if names.Help != "help" {
+		for _, builtin := range builtinCommands {
+			if names.Help == builtin {
+				errs = append(errs, fmt.Errorf("reserved name %q for the help command is already used by the built-in %s command", names.Help, builtin))
+			}
+		}
+	}
+	for _, alias := range names.HelpAliases {
+		for _, builtin := range builtinCommands {
+			if alias == builtin && alias != "help" {
+				errs = append(errs, fmt.Errorf("help alias %q is already used by the built-in %s command", alias, builtin))
+			}
+		}
+	}
+	if names.HelpFlag == names.VersionFlag {
+		errs = append(errs, fmt.Errorf("the help and version flags must have different names, found --%s for both", names.HelpFlag))
+	}
+	for _, reclaimed := range names.Reclaim {
+		found := reclaimed == names.Help || contains(names.HelpAliases, reclaimed)
+		for _, builtin := range builtinCommands {
+			if reclaimed == builtin && reclaimed != "help" {
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%q can't be reclaimed because it is not the name of a built-in command", reclaimed))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,224 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestReservedNames(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	var received []string
+	app := &cli.CLI{
+		Name:      "ship",
+		Version:   "1.0.0",
+		Changelog: "## 1.0.0\n\n- First release\n",
+		Printer:   &cli.Printer{Stdout: stdout, Stderr: &bytes.Buffer{}},
+		Reserved: &cli.ReservedNames{
+			Help:        "manual",
+			VersionFlag: "ver",
+			Reclaim:     []string{"changelog"},
+		},
+		Commands: map[string]*cli.Command{
+			"help": {
+				Summary: "Contact support",
+				Run: func(args []string) error {
+					received = append([]string{"help"}, args...)
+					return nil
+				},
+			},
+			"changelog": {
+				Summary: "Show the deployment log",
+				Help:    "Lists recent deployments.",
+				Run: func(args []string) error {
+					received = append([]string{"changelog"}, args...)
+					return nil
+				},
+			},
+		},
+	}
+
+	type TestCase struct {
+		Args     []string
+		Received []string
+		Output   string
+	}
+
+	cases := []TestCase{
+		{[]string{"help", "now"}, []string{"help", "now"}, ""},
+		{[]string{"changelog"}, []string{"changelog"}, ""},
+		{[]string{"--ver"}, nil, "ship version 1.0.0\n"},
+		{[]string{"manual", "changelog"}, nil, "changelog Command Help\n\nLists recent deployments.\n"},
+	}
+
+	for _, c := range cases {
+		received = nil
+		stdout.Reset()
+		if err := app.RunArgs(c.Args); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(received, " ") != strings.Join(c.Received, " ") {
+			t.Errorf("Expected %q, found %q", c.Received, received)
+		}
+		if c.Output != "" && stdout.String() != c.Output {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", c.Output, stdout.String())
+		}
+	}
+
+	if err := app.RunArgs([]string{"--version"}); err == nil || err.Error() != "'--version' is not a ship command. See 'ship --help'." {
+		t.Errorf("Expected --version to be released, found %v", err)
+	}
+
+	stdout.Reset()
+	if err := app.RunArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := `usage: ship [--ver] [--help] <command> [<args>]
+
+Commands
+
+  ship changelog   Show the deployment log
+  ship help        Contact support
+  ship manual      List help topics
+`
+	if stdout.String() != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout.String())
+	}
+
+	if errs := app.Validate(); len(errs) != 0 {
+		t.Errorf("Expected no errors, found %v", errs)
+	}
+
+	app.Reserved = &cli.ReservedNames{
+		Help:        "changelog",
+		HelpFlag:    "v",
+		VersionFlag: "v",
+		Reclaim:     []string{"help", "deploy"},
+	}
+	var found []string
+	for _, err := range app.Validate() {
+		found = append(found, err.Error())
+	}
+	expectedErrs := []string{
+		`reserved name "changelog" for the help command is already used by the built-in changelog command`,
+		`the help and version flags must have different names, found --v for both`,
+		`"help" can't be reclaimed because it is not the name of a built-in command`,
+		`"deploy" can't be reclaimed because it is not the name of a built-in command`,
+		`command "changelog" is shadowed by the built-in help command, rename it or add it to Reserved.Reclaim`,
+	}
+	if strings.Join(found, "\n") != strings.Join(expectedErrs, "\n") {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", strings.Join(expectedErrs, "\n"), strings.Join(found, "\n"))
+	}
+}
+
+func TestDisableHelpAndVersion(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	var received []string
+	app := &cli.CLI{
+		Name:           "wrap",
+		Version:        "1.0.0",
+		DisableHelp:    true,
+		DisableVersion: true,
+		Printer:        &cli.Printer{Stdout: stdout, Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"tool": {
+				Summary: "Run the wrapped tool",
+				Version: "2.0.0",
+				Run: func(args []string) error {
+					received = args
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"tool", "--help", "--version"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(received, " ") != "--help --version" {
+		t.Errorf("Expected the flags to be forwarded, found %q", received)
+	}
+
+	for _, name := range []string{"help", "--help", "--version"} {
+		expected := "'" + name + "' is not a wrap command."
+		if err := app.RunArgs([]string{name}); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q, found %v", expected, err)
+		}
+	}
+
+	if err := app.RunArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := `usage: wrap <command> [<args>]
+
+Commands
+
+  wrap tool   Run the wrapped tool
+`
+	if stdout.String() != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout.String())
+	}
+}
+
+func TestRenamedHelp(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "schiff",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: &bytes.Buffer{}},
+		Reserved: &cli.ReservedNames{
+			Help:        "hilfe",
+			HelpAliases: []string{"help", "?"},
+		},
+		Commands: map[string]*cli.Command{
+			"deploy": {Summary: "Anwendung bereitstellen", Help: "Stellt die Anwendung bereit.", Run: func(args []string) error { return nil }},
+		},
+	}
+
+	expected := "deploy Command Help\n\nStellt die Anwendung bereit.\n"
+	for _, name := range []string{"hilfe", "help", "?"} {
+		stdout.Reset()
+		if err := app.RunArgs([]string{name, "deploy"}); err != nil {
+			t.Fatal(err)
+		}
+		if stdout.String() != expected {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if err := app.RunArgs([]string{"hilfe"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stdout.String(), "usage: schiff hilfe <topic>\n") {
+		t.Errorf("Expected the usage to use the new name, found %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := app.RunArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "  schiff hilfe    List help topics\n") || strings.Contains(stdout.String(), "schiff help ") {
+		t.Errorf("Expected only the new name to be listed, found\n%s", stdout.String())
+	}
+
+	result := cli.Complete(app, []string{"h"})
+	if strings.Join(result.Candidates, " ") != "hilfe" {
+		t.Errorf("Expected %q, found %q", "hilfe", result.Candidates)
+	}
+
+	app.Commands["?"] = &cli.Command{Summary: "Ask a question", Run: func(args []string) error { return nil }}
+	app.Reserved.HelpAliases = []string{"changelog", "?"}
+	var found []string
+	for _, err := range app.Validate() {
+		found = append(found, err.Error())
+	}
+	expectedErrs := []string{
+		`help alias "changelog" is already used by the built-in changelog command`,
+		`command "?" is shadowed by the built-in help command, rename it or add it to Reserved.Reclaim`,
+	}
+	if strings.Join(found, "\n") != strings.Join(expectedErrs, "\n") {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", strings.Join(expectedErrs, "\n"), strings.Join(found, "\n"))
+	}
+}
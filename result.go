@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"flag"
+	"time"
+)
+
+// RunResult describes what happened during a call to Execute, so programs
+// that embed a CLI, such as GUIs and servers, can act on the outcome without
+// parsing its output.
+type RunResult struct {
+	// Command is the path of the command that was invoked, such as "deploy"
+	// or "release create". It is empty if the invocation was handled by a
+	// built-in such as help or --version.
+	Command string
+
+	// Args are the positional arguments passed to the command after flags
+	// were parsed.
+	Args []string
+
+	// Flags holds the value of each flag that was set on the command line,
+	// keyed by flag name.
+	Flags map[string]string
+
+	// Duration is how long the invocation took, including parsing and hooks.
+	Duration time.Duration
+
+	// ExitCode is the status the program would exit with. See ExitCode.
+	ExitCode int
+}
+
+// Execute runs the program with args, which should not include the program
+// name, and reports what happened. It behaves like Run in every other way,
+// including writing output to c.Printer. The returned RunResult is never nil,
+// and the error is the same one Run would return.
+//
+// Execute must not be called concurrently on the same CLI.
+func (c *CLI) Execute(args []string) (*RunResult, error) {
+	result := &RunResult{Args: []string{}, Flags: map[string]string{}}
+	c.result = result
+	defer func() { c.result = nil }()
+
+	start := time.Now()
+	err := c.run(args)
+	result.Duration = time.Since(start)
+	result.ExitCode = ExitCode(err)
+	return result, err
+}
+
+// recordCommand notes that the command at path is being invoked, if Execute is
+// collecting a RunResult.
+func (c *CLI) recordCommand(path string) {
+	if c.result != nil {
+		c.result.Command = path
+		c.result.Args = []string{}
+		c.result.Flags = map[string]string{}
+	}
+}
+
+// recordArgs notes the parsed flags and positional arguments of the command
+// being invoked, if Execute is collecting a RunResult.
+func (c *CLI) recordArgs(command *Command, args []string) {
+	if c.result == nil {
+		return
+	}
+	c.result.Args = append([]string{}, args...)
+	if command.Flags != nil {
+		command.Flags.Visit(func(f *flag.Flag) {
+			c.result.Flags[f.Name] = f.Value.String()
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestExecute(t *testing.T) {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	flags.Bool("draft", false, "")
+	flags.String("notes", "", "")
+
+	stdout := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"release": {
+				Commands: map[string]*cli.Command{
+					"create": {
+						Flags: flags,
+						Run:   func(args []string) error { return nil },
+					},
+				},
+			},
+			"rollback": {
+				Run: func(args []string) error { return cli.WithExitCode(errors.New("nothing to roll back"), 3) },
+			},
+		},
+	}
+
+	result, err := app.Execute([]string{"release", "create", "--draft", "v1.2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Command != "release create" {
+		t.Errorf("Expected %q, found %q", "release create", result.Command)
+	}
+	if !reflect.DeepEqual(result.Args, []string{"v1.2.0"}) {
+		t.Errorf("Expected %q, found %q", []string{"v1.2.0"}, result.Args)
+	}
+	if !reflect.DeepEqual(result.Flags, map[string]string{"draft": "true"}) {
+		t.Errorf("Expected only --draft to be set, found %v", result.Flags)
+	}
+	if result.ExitCode != 0 || result.Duration < 0 {
+		t.Errorf("Expected status 0 and a duration, found %d and %s", result.ExitCode, result.Duration)
+	}
+
+	result, err = app.Execute([]string{"rollback"})
+	if err == nil || result.Command != "rollback" || result.ExitCode != 3 {
+		t.Errorf("Expected rollback to fail with status 3, found %v %q %d", err, result.Command, result.ExitCode)
+	}
+
+	result, err = app.Execute([]string{"--version"})
+	if err != nil || result.Command != "" || result.ExitCode != 0 {
+		t.Errorf("Expected built-ins to leave Command empty, found %v %q %d", err, result.Command, result.ExitCode)
+	}
+}
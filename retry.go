@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how Retry waits between attempts. Zero fields use the
+// defaults noted below.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, including the first.
+	// Defaults to 3.
+	Attempts int
+
+	// Delay is how long to wait before the second attempt. Defaults to one
+	// second.
+	Delay time.Duration
+
+	// MaxDelay limits how long to wait between attempts, including Jitter.
+	// Defaults to 30 seconds.
+	MaxDelay time.Duration
+
+	// Multiplier increases the delay after each attempt. Defaults to 2.
+	Multiplier float64
+
+	// Jitter randomly varies each delay by up to this fraction of it, such as
+	// 0.2 for plus or minus 20%, so many clients retrying at once don't all
+	// hit the service at the same moment. Zero means no jitter.
+	Jitter float64
+}
+
+// permanentError marks an error that should not be retried. See Permanent.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as an error that retrying won't fix, such as a rejected
+// password, so Retry returns it immediately. Permanent returns nil if err is
+// nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retry calls fn until it succeeds, returns a Permanent error, or has been
+// attempted policy.Attempts times, waiting longer between each attempt. It is
+// meant for commands that call flaky remote services. fn is passed the number
+// of the attempt, starting from 1.
+//
+// After each failed attempt a warning naming label, such as "upload", and the
+// error is printed to stderr along with when the next attempt will be made,
+// and a Spinner is shown while Retry waits if stderr is a terminal.
+// Retry stops waiting and returns if ctx is canceled. The error from the last
+// attempt is returned, without any Permanent mark.
+func Retry(ctx *Context, label string, policy RetryPolicy, fn func(attempt int) error) error {
+	if policy.Attempts <= 0 {
+		policy.Attempts = 3
+	}
+	if policy.Delay <= 0 {
+		policy.Delay = time.Second
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 30 * time.Second
+	}
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = 2
+	}
+
+	var messages Strings
+	if ctx.CLI != nil {
+		messages = ctx.CLI.Strings
+	}
+	symbols := ctx.Printer.Symbols()
+
+	delay := policy.Delay
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= policy.Attempts {
+			ctx.Printer.Errf("%s %s\n", symbols.Failure, messages.sprintf("%s failed after %d attempts: %s", label, attempt, err))
+			return err
+		}
+
+		wait := delay
+		if policy.Jitter > 0 {
+			wait += time.Duration((rand.Float64()*2 - 1) * policy.Jitter * float64(delay))
+		}
+		if wait > policy.MaxDelay {
+			wait = policy.MaxDelay
+		}
+		ctx.Printer.Errf("%s %s\n", symbols.Warning, messages.sprintf("%s failed (attempt %d of %d): %s, retrying in %s", label, attempt, policy.Attempts, err, wait.Round(time.Millisecond)))
+
+		// Show that we're still working during long waits in a terminal,
+		// without adding lines to logs
+		var spinner *Spinner
+		if ctx.Printer.animate() {
+			spinner = ctx.Printer.Spinner(messages.sprintf("Waiting to retry %s", label))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if spinner != nil {
+			spinner.Stop("")
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delay = time.Duration(float64(delay) * policy.Multiplier)
+		if delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
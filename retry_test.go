@@ -0,0 +1,131 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestRetry(t *testing.T) {
+	unavailable := errors.New("503 service unavailable")
+	denied := errors.New("403 forbidden")
+
+	type TestCase struct {
+		Failures []error
+		Attempts int
+		Error    error
+		Output   string
+	}
+
+	cases := []TestCase{
+		{
+			Failures: []error{unavailable},
+			Attempts: 2,
+			Output:   "! upload failed (attempt 1 of 3): 503 service unavailable, retrying in 1ms\n",
+		},
+		{
+			Failures: []error{unavailable, unavailable, unavailable},
+			Attempts: 3,
+			Error:    unavailable,
+			Output: "! upload failed (attempt 1 of 3): 503 service unavailable, retrying in 1ms\n" +
+				"! upload failed (attempt 2 of 3): 503 service unavailable, retrying in 2ms\n" +
+				"X upload failed after 3 attempts: 503 service unavailable\n",
+		},
+		{
+			Failures: []error{cli.Permanent(denied)},
+			Attempts: 1,
+			Error:    denied,
+		},
+	}
+
+	for _, testCase := range cases {
+		stderr := &bytes.Buffer{}
+		attempts := 0
+		app := &cli.CLI{
+			Name:    "ship",
+			Printer: &cli.Printer{Stderr: stderr, ASCII: true},
+			Commands: map[string]*cli.Command{
+				"upload": {RunContext: func(ctx *cli.Context) error {
+					return cli.Retry(ctx, "upload", cli.RetryPolicy{Delay: time.Millisecond}, func(attempt int) error {
+						attempts = attempt
+						if attempt <= len(testCase.Failures) {
+							return testCase.Failures[attempt-1]
+						}
+						return nil
+					})
+				}},
+			},
+		}
+
+		err := app.RunArgs([]string{"upload"})
+		if err != testCase.Error {
+			t.Errorf("Expected error %v, found %v", testCase.Error, err)
+		}
+		if attempts != testCase.Attempts {
+			t.Errorf("Expected %d attempts, found %d", testCase.Attempts, attempts)
+		}
+		if stderr.String() != testCase.Output {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", testCase.Output, stderr.String())
+		}
+	}
+}
+
+func TestRetryMaxDelay(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: stderr, ASCII: true},
+		Commands: map[string]*cli.Command{
+			"upload": {RunContext: func(ctx *cli.Context) error {
+				policy := cli.RetryPolicy{Attempts: 10, Delay: 2 * time.Millisecond, MaxDelay: 2 * time.Millisecond, Jitter: 0.5}
+				return cli.Retry(ctx, "upload", policy, func(attempt int) error {
+					return errors.New("503 service unavailable")
+				})
+			}},
+		},
+	}
+	if err := app.RunArgs([]string{"upload"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	// Jitter never makes a wait longer than MaxDelay
+	waits := regexp.MustCompile(`retrying in (\S+)\n`).FindAllStringSubmatch(stderr.String(), -1)
+	if len(waits) != 9 {
+		t.Fatalf("Expected 9 waits, found:\n%s", stderr.String())
+	}
+	for _, match := range waits {
+		if wait, err := time.ParseDuration(match[1]); err != nil || wait > 2*time.Millisecond {
+			t.Errorf("Expected waits of at most 2ms, found %s", match[1])
+		}
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"upload": {RunContext: func(ctx *cli.Context) error {
+				return cli.Retry(ctx, "upload", cli.RetryPolicy{Delay: time.Hour}, func(attempt int) error {
+					attempts = attempt
+					cancel()
+					return errors.New("connection reset")
+				})
+			}},
+		},
+	}
+
+	if err := app.RunArgsContext(ctx, []string{"upload"}); err != context.Canceled {
+		t.Errorf("Expected %v, found %v", context.Canceled, err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, found %d", attempts)
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+// Runner may be implemented by types that run commands, as an alternative to
+// the Run and RunContext function fields. This lets you structure commands as
+// types whose dependencies, such as API clients or configuration, are injected
+// via constructors:
+//
+//	type deploy struct {
+//		client *api.Client
+//	}
+//
+//	func (d *deploy) Run(ctx *cli.Context, args []string) error {
+//		return d.client.Deploy(ctx, args[0])
+//	}
+//
+//	commands := map[string]*cli.Command{
+//		"deploy": {Runner: &deploy{client: client}},
+//	}
+//
+// A Runner may optionally implement HelpProvider and Completer.
+type Runner interface {
+	Run(ctx *Context, args []string) error
+}
+
+// HelpProvider may be implemented by a Runner to supply the command's help
+// text. It is used when Command.Help and Command.Description are empty.
+type HelpProvider interface {
+	Help() string
+}
+
+// Completer may be implemented by a Runner to supply tab completions for the
+// command's arguments. args holds the arguments before the one being
+// completed. The returned candidates are filtered to those that begin with
+// the word being completed.
+type Completer interface {
+	Complete(args []string) []string
+}
@@ -0,0 +1,61 @@
+package cli_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+type greeter struct {
+	greeting string
+	greeted  []string
+}
+
+func (g *greeter) Run(ctx *cli.Context, args []string) error {
+	g.greeted = append(g.greeted, args...)
+	return nil
+}
+
+func (g *greeter) Help() string {
+	return "Say " + g.greeting + " to everyone."
+}
+
+func (g *greeter) Complete(args []string) []string {
+	return []string{"alice", "bob", "carol"}
+}
+
+func TestRunner(t *testing.T) {
+	runner := &greeter{greeting: "hello"}
+
+	app := &cli.CLI{
+		Name: "testapp",
+		Commands: map[string]*cli.Command{
+			"greet": {
+				Runner: runner,
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"greet", "alice", "bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(runner.greeted, []string{"alice", "bob"}) {
+		t.Errorf("Expected %#v, found %#v", []string{"alice", "bob"}, runner.greeted)
+	}
+
+	output, err := cli.Help(app, []string{"greet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "Say hello to everyone.") {
+		t.Errorf("Expected help from HelpProvider, found:\n%s", output)
+	}
+
+	result := cli.Complete(app, []string{"greet", "alice", "b"})
+	if !reflect.DeepEqual(result.Candidates, []string{"bob"}) {
+		t.Errorf("Expected %#v, found %#v", []string{"bob"}, result.Candidates)
+	}
+}
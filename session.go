@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionRecorder writes a transcript of a command's input and output for
+// --record. Each chunk of input or output is written on its own line with the
+// number of seconds since recording started, the stream it belongs to, and
+// the text as a quoted Go string:
+//
+//	# ship session recorded 2024-05-01T10:00:00Z
+//	# $ ship deploy --env prod
+//	0.004 err "Deploy to prod? [y/N] "
+//	1.870 in "y\n"
+//	2.312 out "\x1b[32m✓\x1b[0m deployed web\n"
+//
+// Lines starting with # are comments. See ReplaySession.
+type sessionRecorder struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+}
+
+// record writes a chunk of a stream to the transcript. Errors are ignored so
+// a failure to record never interferes with the command.
+func (s *sessionRecorder) record(stream string, p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seconds := time.Since(s.start).Seconds()
+	_, _ = fmt.Fprintf(s.w, "%.3f %s %s\n", seconds, stream, strconv.Quote(string(p)))
+}
+
+// writer returns a writer that records everything written to it as stream.
+func (s *sessionRecorder) writer(stream string) io.Writer {
+	return &recordedStream{session: s, stream: stream}
+}
+
+// recordedStream is an io.Writer for one output stream of a session.
+type recordedStream struct {
+	session *sessionRecorder
+	stream  string
+}
+
+func (r *recordedStream) Write(p []byte) (int, error) {
+	r.session.record(r.stream, p)
+	return len(p), nil
+}
+
+// recordedInput is an io.Reader that records the input a command reads.
+type recordedInput struct {
+	r       io.Reader
+	session *sessionRecorder
+}
+
+func (r *recordedInput) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.session.record("in", p[:n])
+	return n, err
+}
+
+// openRecording starts recording the session to the file at path, replacing
+// any existing file. Output written through the Printer and input read from
+// Context.Stdin are recorded. The file stays open until the program exits so
+// errors reported after the command returns are included.
+func (c *CLI) openRecording(path string, input []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return c.Strings.errorf("unable to record session: %s", err)
+	}
+	if c.Printer.session != nil {
+		if closer, ok := c.Printer.session.w.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+
+	start := time.Now()
+	_, _ = fmt.Fprintf(f, "# %s session recorded %s\n", c.Name, start.Format(time.RFC3339))
+	_, _ = fmt.Fprintf(f, "# $ %s\n", strings.Join(append([]string{c.Name}, input...), " "))
+	c.Printer.session = &sessionRecorder{w: f, start: start}
+	return nil
+}
+
+// ReplaySession writes the output in a transcript made with --record to
+// stdout and stderr. If speed is greater than zero, ReplaySession waits
+// between chunks of output to reproduce the original timing, divided by
+// speed; 2 replays the session twice as fast. Recorded input is not written
+// anywhere since it was already echoed by the terminal when it was typed.
+// Errors in the transcript are reported using c.Strings.
+func ReplaySession(c *CLI, r io.Reader, stdout, stderr io.Writer, speed float64) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	elapsed := 0.0
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(text, " ", 3)
+		if len(fields) != 3 {
+			return c.Strings.errorf("invalid transcript on line %d", line)
+		}
+		seconds, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return c.Strings.errorf("invalid time on line %d: %s", line, fields[0])
+		}
+		chunk, err := strconv.Unquote(fields[2])
+		if err != nil {
+			return c.Strings.errorf("invalid text on line %d: %s", line, err)
+		}
+
+		var w io.Writer
+		switch fields[1] {
+		case "in":
+			continue
+		case "out":
+			w = stdout
+		case "err":
+			w = stderr
+		default:
+			return c.Strings.errorf("unknown stream %q on line %d", fields[1], line)
+		}
+
+		if speed > 0 && seconds > elapsed {
+			time.Sleep(time.Duration((seconds - elapsed) / speed * float64(time.Second)))
+		}
+		elapsed = seconds
+		if _, err := io.WriteString(w, chunk); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
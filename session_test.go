@@ -0,0 +1,110 @@
+package cli_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestRecordSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.txt")
+
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"deploy": {
+				RunContext: func(ctx *cli.Context) error {
+					ctx.Printer.Err("deploying\n")
+					ctx.Printer.Out("\x1b[32mweb\x1b[0m\n")
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"--record", path, "deploy", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := regexp.MustCompile(`^# ship session recorded \S+
+# \$ ship deploy web
+\d+\.\d{3} err "deploying\\n"
+\d+\.\d{3} out "\\x1b\[32mweb\\x1b\[0m\\n"
+$`)
+	if !expected.Match(data) {
+		t.Errorf("Expected transcript matching %q, found %q", expected, string(data))
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	if err := cli.ReplaySession(app, bytes.NewReader(data), stdout, stderr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "\x1b[32mweb\x1b[0m\n" {
+		t.Errorf("Expected %q, found %q", "\x1b[32mweb\x1b[0m\n", stdout.String())
+	}
+	if stderr.String() != "deploying\n" {
+		t.Errorf("Expected %q, found %q", "deploying\n", stderr.String())
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	type TestCase struct {
+		Transcript string
+		Stdout     string
+		Error      string
+	}
+
+	cases := []TestCase{
+		{
+			Transcript: "# comment\n0.000 err \"Continue? \"\n0.500 in \"y\\n\"\n0.510 out \"done\\n\"\n",
+			Stdout:     "done\n",
+		},
+		{
+			Transcript: "0.000 out done\n",
+			Error:      "invalid text on line 1: invalid syntax",
+		},
+		{
+			Transcript: "0.000 tty \"done\"\n",
+			Error:      `unknown stream "tty" on line 1`,
+		},
+		{
+			Transcript: "\nsoon out \"done\"\n",
+			Error:      "invalid time on line 2: soon",
+		},
+	}
+
+	app := &cli.CLI{Name: "ship"}
+	for _, testCase := range cases {
+		stdout := &bytes.Buffer{}
+		err := cli.ReplaySession(app, strings.NewReader(testCase.Transcript), stdout, ioutil.Discard, 0)
+		if testCase.Error != "" {
+			if err == nil || err.Error() != testCase.Error {
+				t.Errorf("Expected error %q, found %v", testCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stdout.String() != testCase.Stdout {
+			t.Errorf("Expected %q, found %q", testCase.Stdout, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// knownShells maps executable names to the shell names returned by
+// DetectShell.
+var knownShells = map[string]string{
+	"bash":       "bash",
+	"zsh":        "zsh",
+	"fish":       "fish",
+	"pwsh":       "powershell",
+	"powershell": "powershell",
+	"cmd":        "cmd",
+}
+
+// DetectShell returns the name of the user's shell: "bash", "zsh", "fish",
+// "powershell", or "cmd", or an empty string if it can't be determined. This is
+// useful for showing shell-specific instructions, and is used by the completion
+// command when no shell is specified.
+//
+// The shell that started the program is preferred, since it is the one the user
+// is typing in. Where the parent process can't be inspected the SHELL
+// environment variable (the user's login shell) is used, and on Windows,
+// ComSpec.
+func DetectShell() string {
+	if shell := shellName(parentProcessName()); shell != "" {
+		return shell
+	}
+	if shell := shellName(os.Getenv("SHELL")); shell != "" {
+		return shell
+	}
+	return shellName(os.Getenv("ComSpec"))
+}
+
+// shellName normalizes a shell executable path like /bin/bash, -zsh (a login
+// shell), or C:\Windows\System32\cmd.exe to a name from knownShells.
+func shellName(path string) string {
+	// filepath.Base only understands the current platform's separator
+	if idx := strings.LastIndexAny(path, `/\`); idx >= 0 {
+		path = path[idx+1:]
+	}
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(path), "-"))
+	name = strings.TrimSuffix(name, ".exe")
+	return knownShells[name]
+}
+
+// parentProcessName returns the name of the parent process, or an empty string
+// if it can't be determined. This currently only works on systems with a
+// Linux-style /proc filesystem.
+func parentProcessName() string {
+	data, err := ioutil.ReadFile(filepath.Join("/proc", fmt.Sprint(os.Getppid()), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// shellIdentifier converts name into a string that is safe to use in shell
+// function and variable names, which can't include all of the characters that
+// a program name can.
+func shellIdentifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// shellQuote quotes value so it is interpreted literally by shell.
+func shellQuote(shell, value string) string {
+	if shell == "powershell" {
+		// PowerShell escapes single quotes by doubling them
+		return "'" + strings.Replace(value, "'", "''", -1) + "'"
+	}
+	if shell == "fish" {
+		// fish allows \\ and \' escapes inside single quotes
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+	}
+	// POSIX shells don't allow any escapes inside single quotes, so end the
+	// quoted string, add an escaped quote, and start a new quoted string
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// Quote returns value quoted, if necessary, so shell interprets it as a single
+// literal word. Values made only of letters, digits, and punctuation that is
+// safe in shell are returned unchanged, so printed commands stay readable.
+// shell is a name returned by DetectShell; if it is empty the user's shell is
+// detected, and POSIX sh quoting is used for unknown shells.
+func Quote(shell, value string) string {
+	if shell == "" {
+		shell = DetectShell()
+	}
+
+	safe := "_-./:=+,%@"
+	switch shell {
+	case "powershell":
+		// , and @ build arrays, and = and + are operators
+		safe = `_-./:\`
+	case "cmd":
+		safe = `_-./:\=+,@`
+	}
+	plain := value != "" && !strings.HasPrefix(value, "=") && !strings.HasPrefix(value, "@")
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(safe, r)) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return value
+	}
+
+	if shell == "cmd" {
+		// cmd has no literal quoting, but double quotes prevent most
+		// characters from being interpreted, and quotes are doubled
+		return `"` + strings.Replace(value, `"`, `""`, -1) + `"`
+	}
+	return shellQuote(shell, value)
+}
+
+// RenderCommand joins args into a command line that shell will run with
+// exactly those arguments, quoting each one as needed. Use it to show commands
+// to the user, such as when echoing what a dry run would do or suggesting what
+// to run next. shell is interpreted as in Quote.
+func RenderCommand(shell string, args []string) string {
+	if shell == "" {
+		shell = DetectShell()
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(shell, arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,82 @@
+package cli_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestDetectShell(t *testing.T) {
+	ogShell, ogComSpec := os.Getenv("SHELL"), os.Getenv("ComSpec")
+	defer func() {
+		os.Setenv("SHELL", ogShell)
+		os.Setenv("ComSpec", ogComSpec)
+	}()
+
+	os.Unsetenv("SHELL")
+	os.Unsetenv("ComSpec")
+	if parent := cli.DetectShell(); parent != "" {
+		// The tests were started directly from a shell, which takes precedence
+		t.Skipf("Parent process is %s", parent)
+	}
+
+	type TestCase struct {
+		Shell    string
+		ComSpec  string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{Shell: "/bin/bash", Expected: "bash"},
+		{Shell: "/usr/local/bin/fish", Expected: "fish"},
+		{Shell: "-zsh", Expected: "zsh"},
+		{Shell: "/usr/bin/pwsh", Expected: "powershell"},
+		{Shell: "/bin/tcsh", Expected: ""},
+		{ComSpec: `C:\Windows\System32\cmd.exe`, Expected: "cmd"},
+		{Shell: "/bin/zsh", ComSpec: `C:\Windows\System32\cmd.exe`, Expected: "zsh"},
+	}
+
+	for _, testCase := range cases {
+		os.Setenv("SHELL", testCase.Shell)
+		os.Setenv("ComSpec", testCase.ComSpec)
+		if shell := cli.DetectShell(); shell != testCase.Expected {
+			t.Errorf("Expected %q, found %q", testCase.Expected, shell)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	type TestCase struct {
+		Shell    string
+		Value    string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{Shell: "bash", Value: "deploy", Expected: "deploy"},
+		{Shell: "bash", Value: "--env=prod", Expected: "--env=prod"},
+		{Shell: "bash", Value: "", Expected: "''"},
+		{Shell: "bash", Value: "hello world", Expected: "'hello world'"},
+		{Shell: "bash", Value: "it's", Expected: `'it'\''s'`},
+		{Shell: "zsh", Value: "=ls", Expected: "'=ls'"},
+		{Shell: "bash", Value: "$HOME", Expected: "'$HOME'"},
+		{Shell: "fish", Value: `a\b`, Expected: `'a\\b'`},
+		{Shell: "powershell", Value: `C:\Users\me`, Expected: `C:\Users\me`},
+		{Shell: "powershell", Value: "a,b", Expected: "'a,b'"},
+		{Shell: "powershell", Value: "it's", Expected: "'it''s'"},
+		{Shell: "cmd", Value: `say "hi"`, Expected: `"say ""hi"""`},
+	}
+
+	for _, testCase := range cases {
+		if actual := cli.Quote(testCase.Shell, testCase.Value); actual != testCase.Expected {
+			t.Errorf("Expected %s, found %s for %q in %s", testCase.Expected, actual, testCase.Value, testCase.Shell)
+		}
+	}
+
+	args := []string{"ship", "deploy", "--message", "fix the build", "web*"}
+	expected := "ship deploy --message 'fix the build' 'web*'"
+	if actual := cli.RenderCommand("sh", args); actual != expected {
+		t.Errorf("Expected %q, found %q", expected, actual)
+	}
+}
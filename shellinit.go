@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ShellInit configures the built-in shell-init command, which prints a snippet
+// that integrates the program with the user's shell. Users enable it by adding
+// a single line to their shell's startup file:
+//
+//	eval "$(program shell-init)"        # bash and zsh
+//	program shell-init | source         # fish
+//
+// The snippet sets up tab completion if CLI.Completion is enabled, along with
+// any Aliases and PromptHook.
+type ShellInit struct {
+	// Aliases are shell aliases to define, mapping each alias name to the
+	// command line it runs, such as "dp": "program deploy --env production".
+	Aliases map[string]string
+
+	// PromptHook is a command line that is run before the shell displays each
+	// prompt, such as "program status --refresh". It is run in the user's
+	// shell, so it should be fast.
+	PromptHook string
+}
+
+// shellInitShells lists the shells supported by ShellInitScript.
+var shellInitShells = []string{"bash", "fish", "zsh"}
+
+// ShellInitScript returns the shell-init snippet for the specified shell. See
+// ShellInit.
+func ShellInitScript(c *CLI, shell string) (string, error) {
+	supported := false
+	for _, name := range shellInitShells {
+		supported = supported || name == shell
+	}
+	if !supported {
+		return "", c.Strings.errorf("unsupported shell %q, expected one of: %s", shell, strings.Join(shellInitShells, ", "))
+	}
+
+	settings := c.ShellInit
+	if settings == nil {
+		settings = &ShellInit{}
+	}
+
+	output := fmt.Sprintf("# %s integration for %s\n", shell, c.Name)
+
+	if c.Completion {
+		script, err := CompletionScript(c, shell)
+		if err != nil {
+			return "", err
+		}
+		output += "\n" + script
+	}
+
+	if len(settings.Aliases) > 0 {
+		output += "\n"
+		names := []string{}
+		for name := range settings.Aliases {
+			if name == "" || strings.ContainsAny(name, " \t\n'\"\\$`=;&|<>()") {
+				return "", c.Strings.errorf("invalid alias name %q", name)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := shellQuote(shell, settings.Aliases[name])
+			if shell == "fish" {
+				output += fmt.Sprintf("alias %s %s\n", name, value)
+			} else {
+				output += fmt.Sprintf("alias %s=%s\n", name, value)
+			}
+		}
+	}
+
+	if settings.PromptHook != "" {
+		identifier := shellIdentifier(c.Name)
+		switch shell {
+		case "bash":
+			output += fmt.Sprintf("\n_%[1]s_prompt_hook() {\n    %[2]s\n}\n", identifier, settings.PromptHook)
+			output += fmt.Sprintf("if [[ \";${PROMPT_COMMAND};\" != *\";_%[1]s_prompt_hook;\"* ]]; then\n    PROMPT_COMMAND=\"_%[1]s_prompt_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}\"\nfi\n", identifier)
+		case "zsh":
+			output += fmt.Sprintf("\n_%[1]s_prompt_hook() {\n    %[2]s\n}\n", identifier, settings.PromptHook)
+			output += fmt.Sprintf("autoload -Uz add-zsh-hook\nadd-zsh-hook precmd _%s_prompt_hook\n", identifier)
+		case "fish":
+			output += fmt.Sprintf("\nfunction __%s_prompt_hook --on-event fish_prompt\n    %s\nend\n", identifier, settings.PromptHook)
+		}
+	}
+
+	return output, nil
+}
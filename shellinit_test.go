@@ -0,0 +1,77 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestShellInitScript(t *testing.T) {
+	app := &cli.CLI{
+		Name: "ship",
+		ShellInit: &cli.ShellInit{
+			Aliases: map[string]string{
+				"sp": "ship deploy --env production",
+				"sq": "ship echo 'quoted'",
+			},
+			PromptHook: "ship status --prompt",
+		},
+	}
+
+	expectedBash := `# bash integration for ship
+
+alias sp='ship deploy --env production'
+alias sq='ship echo '\''quoted'\'''
+
+_ship_prompt_hook() {
+    ship status --prompt
+}
+if [[ ";${PROMPT_COMMAND};" != *";_ship_prompt_hook;"* ]]; then
+    PROMPT_COMMAND="_ship_prompt_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`
+	script, err := cli.ShellInitScript(app, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script != expectedBash {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedBash, script)
+	}
+
+	expectedFish := `# fish integration for ship
+
+alias sp 'ship deploy --env production'
+alias sq 'ship echo \'quoted\''
+
+function __ship_prompt_hook --on-event fish_prompt
+    ship status --prompt
+end
+`
+	script, err = cli.ShellInitScript(app, "fish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script != expectedFish {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedFish, script)
+	}
+
+	// Completion is included when enabled
+	app.Completion = true
+	script, err = cli.ShellInitScript(app, "zsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(script, "ship __complete") || !strings.Contains(script, "add-zsh-hook precmd _ship_prompt_hook") {
+		t.Errorf("Expected completion and prompt hook in zsh script:\n%s", script)
+	}
+
+	if _, err := cli.ShellInitScript(app, "tcsh"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+
+	app.ShellInit.Aliases["bad name"] = "ship"
+	if _, err := cli.ShellInitScript(app, "bash"); err == nil {
+		t.Error("Expected error for invalid alias name")
+	}
+}
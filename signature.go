@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// PublicKey is an Ed25519 public key used to verify signed files, such as
+// updates and plugins. See ParsePublicKey and VerifySignature.
+type PublicKey struct {
+	// ID identifies the key in minisign signatures. It is zero for raw
+	// Ed25519 keys.
+	ID [8]byte
+
+	Key ed25519.PublicKey
+}
+
+// ParsePublicKey parses a public key in the format written by minisign, with
+// or without its "untrusted comment" line, or a raw Ed25519 public key encoded
+// in base64. Programs usually embed their keys as constants and parse them
+// once at startup.
+func ParsePublicKey(text string) (PublicKey, error) {
+	var key PublicKey
+	data, err := base64.StdEncoding.DecodeString(lastLine(text))
+	if err != nil {
+		return key, fmt.Errorf("invalid public key: %w", err)
+	}
+	switch {
+	case len(data) == ed25519.PublicKeySize:
+		key.Key = data
+	case len(data) == 2+8+ed25519.PublicKeySize && string(data[:2]) == "Ed":
+		copy(key.ID[:], data[2:10])
+		key.Key = data[10:]
+	default:
+		return key, errors.New("invalid public key: expected a minisign or Ed25519 public key")
+	}
+	return key, nil
+}
+
+// VerifySignature checks that signature is a valid signature of the file at
+// path by one of keys. signature may be the contents of a minisign .minisig
+// file, in which case its trusted comment is verified as well, or a raw
+// Ed25519 signature, either as 64 bytes or encoded in base64. Both minisign's
+// default pre-hashed signatures and legacy signatures made with
+// "minisign -S -l" are supported.
+func VerifySignature(path string, signature []byte, keys []PublicKey) error {
+	return verifySignature(path, signature, keys, nil)
+}
+
+// verifySignature implements VerifySignature, translating its errors with
+// messages.
+func verifySignature(path string, signature []byte, keys []PublicKey, messages Strings) error {
+	message, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return messages.errorf("no public keys to verify the signature with")
+	}
+
+	if !bytes.HasPrefix(signature, []byte("untrusted comment: ")) {
+		raw := signature
+		if len(raw) != ed25519.SignatureSize {
+			if raw, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err != nil || len(raw) != ed25519.SignatureSize {
+				return messages.errorf("invalid signature")
+			}
+		}
+		for _, key := range keys {
+			if ed25519.Verify(key.Key, message, raw) {
+				return nil
+			}
+		}
+		return messages.errorf("signature of %s does not match any trusted key", path)
+	}
+
+	return verifyMinisign(path, message, string(signature), keys, messages)
+}
+
+// verifyMinisign checks a signature in the format written by minisign.
+// Errors are translated with messages.
+func verifyMinisign(path string, message []byte, signature string, keys []PublicKey, messages Strings) error {
+	lines := strings.Split(strings.TrimSpace(strings.Replace(signature, "\r\n", "\n", -1)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "untrusted comment: ") || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return messages.errorf("invalid signature: expected a minisign signature")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(data) != 2+8+ed25519.SignatureSize {
+		return messages.errorf("invalid signature: expected a minisign signature")
+	}
+	switch string(data[:2]) {
+	case "Ed":
+	case "ED":
+		// The default algorithm signs the BLAKE2b hash of the file
+		sum := blake2b512(message)
+		message = sum[:]
+	default:
+		return messages.errorf("invalid signature: unknown algorithm")
+	}
+	id, sig := data[2:10], data[10:]
+
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return messages.errorf("invalid signature: malformed trusted comment signature")
+	}
+	comment := strings.TrimPrefix(lines[2], "trusted comment: ")
+
+	for _, key := range keys {
+		if !bytes.Equal(key.ID[:], id) {
+			continue
+		}
+		if !ed25519.Verify(key.Key, message, sig) {
+			return messages.errorf("signature of %s does not match key %X", path, reverse(id))
+		}
+		if !ed25519.Verify(key.Key, append(append([]byte{}, sig...), comment...), global) {
+			return messages.errorf("invalid signature: the trusted comment has been modified")
+		}
+		return nil
+	}
+	return messages.errorf("%s was signed with key %X, which is not trusted", path, reverse(id))
+}
+
+// reverse returns a reversed copy of id, which minisign displays as a little
+// endian number.
+func reverse(id []byte) []byte {
+	reversed := make([]byte, len(id))
+	for i, b := range id {
+		reversed[len(id)-1-i] = b
+	}
+	return reversed
+}
+
+// lastLine returns the last non-empty line of text, trimmed of spaces.
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,179 @@
+package cli_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+// minisignKey returns a public key and a function that signs messages with the
+// matching private key, in the formats written by minisign.
+func minisignKey(t *testing.T, id string) (publicKey string, sign func(message []byte, comment string) []byte) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(append([]byte("Ed"+id), public...))
+	publicKey = "untrusted comment: minisign public key\n" + encoded + "\n"
+
+	sign = func(message []byte, comment string) []byte {
+		sig := ed25519.Sign(private, message)
+		global := ed25519.Sign(private, append(append([]byte{}, sig...), comment...))
+		return []byte("untrusted comment: signature from minisign secret key\n" +
+			base64.StdEncoding.EncodeToString(append([]byte("Ed"+id), sig...)) + "\n" +
+			"trusted comment: " + comment + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n")
+	}
+	return publicKey, sign
+}
+
+// prehashed changes the algorithm of a minisign signature made by minisignKey
+// to the default pre-hashed algorithm, which signs the BLAKE2b-512 hash of the
+// file. The signature must have been made from the hash.
+func prehashed(t *testing.T, signature []byte) []byte {
+	lines := strings.Split(string(signature), "\n")
+	data, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(data, "ED")
+	lines[1] = base64.StdEncoding.EncodeToString(data)
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func TestVerifySignature(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ship")
+	message := []byte("release 1.2.0")
+	if err := ioutil.WriteFile(path, message, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	trustedText, sign := minisignKey(t, "12345678")
+	otherText, signOther := minisignKey(t, "abcdefgh")
+	trusted, err := cli.ParsePublicKey(trustedText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.ParsePublicKey(otherText); err != nil {
+		t.Fatal(err)
+	}
+	raw, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawKey, err := cli.ParsePublicKey(base64.StdEncoding.EncodeToString(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// BLAKE2b-512 of message, from Python's hashlib.blake2b
+	digest, err := hex.DecodeString("87f7934ae1e50a8ea75c299d9bedde63225b93f9ee704e5cb9624a261401b5c0a02a52354a8cc10de34c9553493fed2cceabd22a035b5d0ab06944901e3bdd37")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := sign(message, "timestamp:1700000000")
+	tampered = bytes.Replace(tampered, []byte("1700000000"), []byte("1800000000"), 1)
+
+	type TestCase struct {
+		Name      string
+		Signature []byte
+		Error     string
+	}
+
+	cases := []TestCase{
+		{Name: "minisign", Signature: sign(message, "timestamp:1700000000")},
+		{Name: "prehashed", Signature: prehashed(t, sign(digest, "timestamp:1700000000"))},
+		{Name: "prehashed wrong message", Signature: prehashed(t, sign(message, "timestamp:1700000000")), Error: "does not match key"},
+		{Name: "raw", Signature: ed25519.Sign(private, message)},
+		{Name: "raw base64", Signature: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, message)) + "\n")},
+		{Name: "wrong message", Signature: sign([]byte("release 1.1.0"), "timestamp:1700000000"), Error: "does not match key"},
+		{Name: "untrusted key", Signature: signOther(message, "timestamp:1700000000"), Error: "which is not trusted"},
+		{Name: "trusted comment", Signature: tampered, Error: "trusted comment has been modified"},
+		{Name: "raw wrong message", Signature: ed25519.Sign(private, []byte("other")), Error: "does not match any trusted key"},
+	}
+
+	for _, c := range cases {
+		err := cli.VerifySignature(path, c.Signature, []cli.PublicKey{trusted, rawKey})
+		if c.Error == "" && err != nil {
+			t.Errorf("%s: %s", c.Name, err)
+		}
+		if c.Error != "" && (err == nil || !strings.Contains(err.Error(), c.Error)) {
+			t.Errorf("%s: expected an error containing %q, found %v", c.Name, c.Error, err)
+		}
+	}
+}
+
+func TestDownloadSignature(t *testing.T) {
+	content := []byte("new version of ship")
+	publicKey, sign := minisignKey(t, "12345678")
+	key, err := cli.ParsePublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature := sign(content, "file:ship")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ship":
+			http.ServeContent(w, r, "ship", time.Time{}, bytes.NewReader(content))
+		case "/ship.minisig":
+			w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "download-signature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dest := filepath.Join(dir, "ship")
+
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"update": {RunContext: func(ctx *cli.Context) error {
+				return cli.Download(ctx, server.URL+"/ship", dest, &cli.DownloadOptions{
+					PublicKeys: []cli.PublicKey{key},
+					Retry:      cli.RetryPolicy{Attempts: 1},
+				})
+			}},
+		},
+	}
+
+	if err := app.RunArgs([]string{"update"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(dest); err != nil || !bytes.Equal(data, content) {
+		t.Errorf("Expected %s to be downloaded, found %q, %v", dest, data, err)
+	}
+
+	os.Remove(dest)
+	signature = sign([]byte("something else"), "file:ship")
+	if err := app.RunArgs([]string{"update"}); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected a signature error, found %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be replaced when the signature doesn't match", dest)
+	}
+}
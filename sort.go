@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SortOrder selects how command names and help topics are ordered in
+// listings. See CLI.SortOrder.
+type SortOrder int
+
+const (
+	// SortBytes orders names by their bytes, which puts upper case letters
+	// before lower case and accented letters after z.
+	SortBytes SortOrder = iota
+
+	// SortCollated orders names alphabetically, ignoring case and accents
+	// except to break ties, so "éditer" sorts between "deploy" and "fetch".
+	// Letters from other scripts are ordered by code point, which groups
+	// each script together. This approximates the Unicode root collation;
+	// the standard library has no locale-specific collation tables.
+	SortCollated
+
+	// SortNatural orders runs of digits by their numeric value, so "file2"
+	// sorts before "file10". Other text is ordered by its bytes.
+	SortNatural
+)
+
+// accentBases maps precomposed letters to the letter without its accent. It
+// is built from compositions.
+var accentBases = map[rune]rune{}
+
+func init() {
+	for _, table := range compositions {
+		pairs := []rune(table)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			accentBases[pairs[i+1]] = pairs[i]
+		}
+	}
+}
+
+// collationKey returns s with case and accents removed, for SortCollated.
+func collationKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if _, isMark := compositions[r]; isMark {
+			return -1
+		}
+		if base, ok := accentBases[r]; ok {
+			r = base
+		}
+		return unicode.ToLower(r)
+	}, composeMarks(s))
+}
+
+// less reports whether a sorts before b in order.
+func (order SortOrder) less(a, b string) bool {
+	switch order {
+	case SortCollated:
+		if keyA, keyB := collationKey(a), collationKey(b); keyA != keyB {
+			return keyA < keyB
+		}
+	case SortNatural:
+		if result := compareNatural(a, b); result != 0 {
+			return result < 0
+		}
+	}
+	return a < b
+}
+
+// compareNatural returns -1, 0, or 1 if a sorts before, the same as, or after
+// b in SortNatural order. Numbers that differ only in leading zeros, such as
+// "7" and "007", compare the same.
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, numB := leadingDigits(a), leadingDigits(b)
+			a, b = a[len(numA):], b[len(numB):]
+			numA, numB = strings.TrimLeft(numA, "0"), strings.TrimLeft(numB, "0")
+			if len(numA) != len(numB) {
+				if len(numA) < len(numB) {
+					return -1
+				}
+				return 1
+			}
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
+
+// leadingDigits returns the run of ASCII digits at the start of s.
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// sortNames sorts names in place using c.SortOrder.
+func (c *CLI) sortNames(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		return c.SortOrder.less(names[i], names[j])
+	})
+}
+
+// sortedCommandNames returns the names of commands sorted using c.SortOrder.
+func (c *CLI) sortedCommandNames(commands map[string]*Command) []string {
+	names := SortedCommandNames(commands)
+	c.sortNames(names)
+	return names
+}
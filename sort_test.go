@@ -0,0 +1,68 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+// listedCommands returns the command names in app's help, in order.
+func listedCommands(app *cli.CLI) (names []string) {
+	for _, line := range strings.Split(cli.CommandHelp(app), "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == app.Name && fields[1] != "help" {
+			names = append(names, fields[1])
+		}
+	}
+	return names
+}
+
+func TestSortOrder(t *testing.T) {
+	app := &cli.CLI{
+		Name: "edit",
+		Commands: map[string]*cli.Command{
+			"zip":    {Summary: "compress files"},
+			"éditer": {Summary: "edit a file"},
+			"Export": {Summary: "export a file"},
+			"apply":  {Summary: "apply changes"},
+			"fetch":  {Summary: "download files"},
+		},
+	}
+
+	type TestCase struct {
+		Order    cli.SortOrder
+		Expected []string
+	}
+
+	cases := []TestCase{
+		{cli.SortBytes, []string{"Export", "apply", "fetch", "zip", "éditer"}},
+		{cli.SortCollated, []string{"apply", "éditer", "Export", "fetch", "zip"}},
+	}
+
+	for _, c := range cases {
+		app.SortOrder = c.Order
+		found := listedCommands(app)
+		if strings.Join(found, " ") != strings.Join(c.Expected, " ") {
+			t.Errorf("Expected %q, found %q", c.Expected, found)
+		}
+	}
+}
+
+func TestSortNatural(t *testing.T) {
+	app := &cli.CLI{
+		Name: "edit",
+		Commands: map[string]*cli.Command{
+			"step10": {Summary: "tenth step"},
+			"step2":  {Summary: "second step"},
+			"step1":  {Summary: "first step"},
+			"step":   {Summary: "any step"},
+		},
+		SortOrder: cli.SortNatural,
+	}
+
+	found := listedCommands(app)
+	expected := []string{"step", "step1", "step2", "step10"}
+	if strings.Join(found, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %q, found %q", expected, found)
+	}
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// spinnerInterval is how long each frame of a spinner is shown.
+const spinnerInterval = 100 * time.Millisecond
+
+// Spinner shows on stderr that a task of unknown length is in progress. In a
+// terminal the message is drawn after an animated frame from Symbols.Spinner,
+// which are ASCII with --no-emoji. When stderr is redirected or the Printer is
+// Accessible nothing is animated; each message is printed once as a plain
+// line instead, and with --progress=json each is a ProgressEvent.
+type Spinner struct {
+	printer *Printer
+	animate bool
+
+	mu      sync.Mutex
+	message string
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Spinner starts a spinner with message, which describes the task. Call Stop
+// when the task is finished.
+func (p *Printer) Spinner(message string) *Spinner {
+	s := &Spinner{printer: p, message: message, animate: p.animate()}
+	if !s.animate {
+		s.report("progress", message)
+		return s
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run()
+	return s
+}
+
+// Update replaces the spinner's message.
+func (s *Spinner) Update(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.message = message
+	if !s.animate {
+		s.report("progress", message)
+	}
+}
+
+// Stop stops the spinner and replaces it with message, or clears it if
+// message is empty. Stop may be called more than once.
+func (s *Spinner) Stop(message string) {
+	s.mu.Lock()
+	stopped := s.stopped
+	s.stopped = true
+	s.mu.Unlock()
+	if stopped {
+		return
+	}
+
+	if !s.animate {
+		s.report("done", message)
+		return
+	}
+	close(s.stop)
+	<-s.done
+
+	stderr := s.printer.StderrWriter()
+	fmt.Fprint(stderr, "\r\x1b[2K")
+	if message != "" {
+		fmt.Fprintf(stderr, "%s\n", message)
+	}
+}
+
+// run draws the next frame at each interval until the spinner is stopped.
+func (s *Spinner) run() {
+	defer close(s.done)
+
+	frames := s.printer.Symbols().Spinner
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		s.mu.Lock()
+		message := s.message
+		s.mu.Unlock()
+		fmt.Fprintf(s.printer.StderrWriter(), "\r\x1b[2K%s %s", frames[frame%len(frames)], message)
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report prints message as a plain line, or as a ProgressEvent with
+// --progress=json, for spinners that are not animated.
+func (s *Spinner) report(event, message string) {
+	stderr := s.printer.StderrWriter()
+	if s.printer != nil && s.printer.ProgressJSON {
+		_ = NewJSONLinesEncoder(stderr).Encode(ProgressEvent{Event: event, Message: message})
+		return
+	}
+	if message != "" {
+		fmt.Fprintf(stderr, "%s\n", message)
+	}
+}
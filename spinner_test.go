@@ -0,0 +1,51 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestSpinner(t *testing.T) {
+	type TestCase struct {
+		Printer  *cli.Printer
+		Expected string
+	}
+
+	cases := []TestCase{
+		{
+			// stderr is not a terminal, so each message is printed once
+			&cli.Printer{},
+			"connecting\nwaiting for build\ndeployed\n",
+		},
+		{
+			&cli.Printer{Accessible: true},
+			"connecting\nwaiting for build\ndeployed\n",
+		},
+		{
+			&cli.Printer{ProgressJSON: true},
+			`{"event":"progress","step":0,"total":0,"percent":0,"message":"connecting"}
+{"event":"progress","step":0,"total":0,"percent":0,"message":"waiting for build"}
+{"event":"done","step":0,"total":0,"percent":0,"message":"deployed"}
+`,
+		},
+	}
+
+	for _, c := range cases {
+		stderr := &bytes.Buffer{}
+		c.Printer.Stderr = stderr
+
+		spinner := c.Printer.Spinner("connecting")
+		spinner.Update("waiting for build")
+		spinner.Stop("deployed")
+
+		// Nothing is printed once the spinner is stopped
+		spinner.Update("ignored")
+		spinner.Stop("ignored")
+
+		if output := stderr.String(); output != c.Expected {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", c.Expected, output)
+		}
+	}
+}
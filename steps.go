@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"strings"
+	"time"
+)
+
+// Step is one named stage of a command run with Steps.
+type Step struct {
+	// Name identifies the step in output and for --from-step. It should be a
+	// single word, such as "build" or "upload".
+	Name string
+
+	// Run performs the step.
+	Run func(ctx *Context) error
+}
+
+// Steps runs a sequence of named steps for a command that does its work in
+// stages, such as a deploy that builds, uploads, and migrates. Steps.Run is
+// suitable for use as a Command's RunContext:
+//
+//	"deploy": {
+//		Flags:      deployFlags, // defines --from-step
+//		RunContext: cli.Steps{{"build", build}, {"upload", upload}}.Run,
+//	}
+//
+// The start, success or failure, and duration of each step are printed to
+// stderr, followed by the total time. If the command is canceled, such as by
+// Ctrl-C, no further steps are started.
+//
+// If the command defines a string flag named from-step, the user can resume a
+// failed run by passing --from-step with the name of the step to start from,
+// and errors include a hint explaining how.
+type Steps []Step
+
+// Run runs the steps in order, stopping at the first error.
+func (s Steps) Run(ctx *Context) error {
+	var messages Strings
+	if ctx.CLI != nil {
+		messages = ctx.CLI.Strings
+	}
+	symbols := ctx.Printer.Symbols()
+
+	resumable := false
+	start := 0
+	if ctx.Flags != nil {
+		if f := ctx.Flags.Lookup("from-step"); f != nil {
+			resumable = true
+			if from := f.Value.String(); from != "" {
+				start = -1
+				for i, step := range s {
+					if step.Name == from {
+						start = i
+					}
+				}
+				if start < 0 {
+					return messages.errorf("unknown step '%s', expected one of: %s", from, strings.Join(s.names(), ", "))
+				}
+			}
+		}
+	}
+
+	// resume adds a hint for resuming from the named step to err
+	resume := func(err error, name string) error {
+		if !resumable {
+			return err
+		}
+		return WithHint(err, messages.sprintf("run again with --from-step %s to resume", name))
+	}
+
+	began := time.Now()
+	for i := start; i < len(s); i++ {
+		step := s[i]
+		if err := ctx.Err(); err != nil {
+			return resume(messages.errorf("canceled before step '%s'", step.Name), step.Name)
+		}
+
+		ctx.Printer.Errf("%s %s\n", symbols.Info, step.Name)
+		stepStart := time.Now()
+		var err error
+		if step.Run != nil {
+			err = step.Run(ctx)
+		}
+		elapsed := time.Since(stepStart).Round(time.Millisecond)
+		if err != nil {
+			ctx.Printer.Errf("%s %s failed after %s\n", symbols.Failure, step.Name, elapsed)
+			return resume(err, step.Name)
+		}
+		ctx.Printer.Errf("%s %s (%s)\n", symbols.Success, step.Name, elapsed)
+	}
+
+	ctx.Printer.Errf("Completed %d step(s) in %s\n", len(s)-start, time.Since(began).Round(time.Millisecond))
+	return nil
+}
+
+// names returns the names of the steps.
+func (s Steps) names() []string {
+	names := make([]string, len(s))
+	for i, step := range s {
+		names[i] = step.Name
+	}
+	return names
+}
@@ -0,0 +1,115 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"regexp"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestSteps(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) cli.Step {
+		return cli.Step{Name: name, Run: func(ctx *cli.Context) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	stderr := &bytes.Buffer{}
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	flags.String("from-step", "", "resume from a step")
+	failUpload := errors.New("upload failed")
+	steps := cli.Steps{step("build", nil), step("upload", failUpload), step("migrate", nil)}
+
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: stderr, ASCII: true},
+		Commands: map[string]*cli.Command{
+			"deploy": {Flags: flags, RunContext: func(ctx *cli.Context) error { return steps.Run(ctx) }},
+		},
+	}
+	durations := regexp.MustCompile(`[0-9.]+[µnm]?s\b`)
+
+	type TestCase struct {
+		Args   []string
+		Ran    []string
+		Output string
+		Error  string
+		Hint   string
+	}
+
+	cases := []TestCase{
+		{
+			Args:   []string{"deploy"},
+			Ran:    []string{"build", "upload"},
+			Output: "* build\nOK build (Ns)\n* upload\nX upload failed after Ns\n",
+			Error:  "upload failed",
+			Hint:   "run again with --from-step upload to resume",
+		},
+		{
+			Args:   []string{"deploy", "--from-step", "migrate"},
+			Ran:    []string{"migrate"},
+			Output: "* migrate\nOK migrate (Ns)\nCompleted 1 step(s) in Ns\n",
+		},
+		{
+			Args:  []string{"deploy", "--from-step", "test"},
+			Error: "unknown step 'test', expected one of: build, upload, migrate",
+		},
+	}
+
+	for _, testCase := range cases {
+		ran = nil
+		stderr.Reset()
+		flags.Set("from-step", "")
+
+		err := app.RunArgs(testCase.Args)
+		if testCase.Error == "" && err != nil {
+			t.Errorf("Unexpected error for %v: %s", testCase.Args, err)
+		}
+		if testCase.Error != "" && (err == nil || err.Error() != testCase.Error) {
+			t.Errorf("Expected error %q, found %v", testCase.Error, err)
+		}
+		if testCase.Hint != "" {
+			var hinted interface{ Hint() string }
+			if !errors.As(err, &hinted) || hinted.Hint() != testCase.Hint {
+				t.Errorf("Expected hint %q with error %v", testCase.Hint, err)
+			}
+		}
+		if len(ran) != len(testCase.Ran) {
+			t.Errorf("Expected steps %v to run, found %v", testCase.Ran, ran)
+		}
+		if output := durations.ReplaceAllString(stderr.String(), "Ns"); output != testCase.Output {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", testCase.Output, output)
+		}
+	}
+}
+
+func TestStepsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran := false
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"deploy": {RunContext: cli.Steps{{Name: "build", Run: func(ctx *cli.Context) error {
+				ran = true
+				return nil
+			}}}.Run},
+		},
+	}
+
+	err := app.RunArgsContext(ctx, []string{"deploy"})
+	if err == nil || err.Error() != "canceled before step 'build'" {
+		t.Errorf("Expected %q, found %v", "canceled before step 'build'", err)
+	}
+	if ran {
+		t.Error("Expected no steps to run after the command was canceled")
+	}
+}
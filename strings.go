@@ -0,0 +1,62 @@
+package cli
+
+import "fmt"
+
+// Strings translates the messages generated by the framework, such as error
+// messages and labels, for programs that are used in languages other than
+// English. Each key is the English message or format string used by the
+// framework, such as "'%s' is not a %s command. See '%s --%s'.", and each
+// value is its translation. Translations may reorder arguments using explicit
+// argument indexes, such as %[2]s. Messages without a translation are shown
+// in English.
+//
+// Errors that indicate a mistake in the program's definition, such as those
+// returned by AddCommand or Compile, are not translated because they are
+// meant for the program's author rather than its users.
+type Strings map[string]string
+
+// get returns the translation of message, or message itself if there is no
+// translation.
+func (s Strings) get(message string) string {
+	if translated, ok := s[message]; ok && translated != "" {
+		return translated
+	}
+	return message
+}
+
+// errorf is like fmt.Errorf, but translates format first.
+func (s Strings) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf(s.get(format), a...)
+}
+
+// sprintf is like fmt.Sprintf, but translates format first.
+func (s Strings) sprintf(format string, a ...interface{}) string {
+	return fmt.Sprintf(s.get(format), a...)
+}
+
+// translate returns err with its message translated, or err itself if there
+// is no translation. The translated error still matches err with errors.Is,
+// so programs can check for errors such as ErrNotImplemented in any language.
+func (s Strings) translate(err error) error {
+	message := s.get(err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &translatedError{message: message, err: err}
+}
+
+// translatedError is an error whose message has been translated. It is not
+// unwrapped, so RenderError shows only the translation.
+type translatedError struct {
+	message string
+	err     error
+}
+
+func (e *translatedError) Error() string {
+	return e.message
+}
+
+// Is reports whether target is the error that was translated.
+func (e *translatedError) Is(target error) bool {
+	return target == e.err
+}
@@ -0,0 +1,89 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestStrings(t *testing.T) {
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	flags.String("env", "", "")
+
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"deploy": {Flags: flags, Run: func(args []string) error { return nil }},
+			"status": {},
+		},
+		Strings: cli.Strings{
+			"'%s' is not a %s command. See '%s --%s'.": "'%s' n'est pas une commande %s. Voir '%s --%s'.",
+			"unknown flag --%s%s":                      "option inconnue --%s%s",
+			", did you mean %s?":                       ", vouliez-vous dire %s ?",
+			"too many arguments":                       "trop d'arguments",
+			"error:":                                   "erreur :",
+			"unknown sort column %q":                   "colonne de tri inconnue %q",
+			"not implemented":                          "pas implémenté",
+			"invalid lock name %q":                     "nom de verrou invalide %q",
+		},
+	}
+
+	type TestCase struct {
+		Args     []string
+		Expected string
+	}
+
+	cases := []TestCase{
+		{
+			[]string{"launch"},
+			"'launch' n'est pas une commande ship. Voir 'ship --help'.",
+		},
+		{
+			[]string{"deploy", "--evn", "prod"},
+			"option inconnue --evn, vouliez-vous dire --env ?",
+		},
+		{
+			// Messages without a translation are shown in English
+			[]string{"deploy", "--env"},
+			"flag --env requires a value",
+		},
+	}
+
+	for _, c := range cases {
+		if err := app.RunArgs(c.Args); err == nil || err.Error() != c.Expected {
+			t.Errorf("Expected %q, found %v", c.Expected, err)
+		}
+	}
+
+	expected := "erreur : trop d'arguments\n"
+	if output := cli.RenderError(app, cli.ErrTooManyArguments); output != expected {
+		t.Errorf("Expected %q, found %q", expected, output)
+	}
+
+	// Sentinel errors are translated, but still match with errors.Is
+	err := app.RunArgs([]string{"status"})
+	if err == nil || err.Error() != "pas implémenté" || !errors.Is(err, cli.ErrNotImplemented) {
+		t.Errorf("Expected a translated ErrNotImplemented, found %v", err)
+	}
+	if output := cli.RenderError(app, err); output != "erreur : pas implémenté\n" {
+		t.Errorf("Expected %q, found %q", "erreur : pas implémenté\n", output)
+	}
+
+	expected = `nom de verrou invalide ".."`
+	if _, err := cli.Lock(app, ".."); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+
+	// Helpers that are used without a CLI translate with the Strings they
+	// are given
+	table := &cli.Table{Columns: []cli.Column{{Name: "name"}}}
+	err = table.Render(&bytes.Buffer{}, &cli.TableOptions{SortBy: "age", Strings: app.Strings})
+	expected = `colonne de tri inconnue "age"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, found %v", expected, err)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestionDistance is the largest edit distance between user input and a
+// known name that we will still consider a likely typo.
+const maxSuggestionDistance = 2
+
+// Suggest returns the candidates that are most likely to be what the user meant
+// when they typed input, ordered from most to least likely. Candidates are
+// chosen by edit distance, and candidates that start with input are included
+// as well. Suggest returns an empty list if nothing is a reasonable match.
+func Suggest(input string, candidates []string) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == input {
+			continue
+		}
+		distance := Distance(strings.ToLower(input), strings.ToLower(candidate))
+		if distance <= maxSuggestionDistance || (input != "" && strings.HasPrefix(candidate, input)) {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := make([]string, len(matches))
+	for idx, m := range matches {
+		suggestions[idx] = m.name
+	}
+	return suggestions
+}
+
+// Distance computes the Levenshtein distance between a and b, which is the
+// number of single-character insertions, deletions, or substitutions needed to
+// turn one into the other.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j] + 1
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+			if previous[j-1]+cost < current[j] {
+				current[j] = previous[j-1] + cost
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
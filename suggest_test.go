@@ -0,0 +1,55 @@
+package cli_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestDistance(t *testing.T) {
+	type TestCase struct {
+		A        string
+		B        string
+		Expected int
+	}
+
+	cases := []TestCase{
+		{A: "", B: "", Expected: 0},
+		{A: "force", B: "force", Expected: 0},
+		{A: "forse", B: "force", Expected: 1},
+		{A: "", B: "abc", Expected: 3},
+		{A: "kitten", B: "sitting", Expected: 3},
+		{A: "über", B: "uber", Expected: 1},
+	}
+
+	for _, testCase := range cases {
+		actual := cli.Distance(testCase.A, testCase.B)
+		if actual != testCase.Expected {
+			t.Errorf("Expected %d, found %d with input (%q, %q)", testCase.Expected, actual, testCase.A, testCase.B)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"force", "format", "verbose", "version", "help"}
+
+	type TestCase struct {
+		Input    string
+		Expected []string
+	}
+
+	cases := []TestCase{
+		{Input: "forse", Expected: []string{"force"}},
+		{Input: "for", Expected: []string{"force", "format"}},
+		{Input: "versoin", Expected: []string{"version"}},
+		{Input: "zzzzzzz", Expected: []string{}},
+	}
+
+	for _, testCase := range cases {
+		actual := cli.Suggest(testCase.Input, candidates)
+		if !reflect.DeepEqual(actual, testCase.Expected) {
+			t.Errorf("Expected %#v, found %#v with input %q", testCase.Expected, actual, testCase.Input)
+		}
+	}
+}
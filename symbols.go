@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"os"
+	"strings"
+)
+
+// Symbols are the glyphs used by the framework to indicate status, such as the
+// check mark printed when a step succeeds.
+type Symbols struct {
+	Success string
+	Failure string
+	Warning string
+	Info    string
+
+	// Spinner holds the animation frames drawn by Printer.Spinner.
+	Spinner []string
+}
+
+var (
+	// UnicodeSymbols are used on terminals that can display them.
+	UnicodeSymbols = Symbols{
+		Success: "✓",
+		Failure: "✗",
+		Warning: "⚠",
+		Info:    "•",
+		Spinner: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	}
+
+	// ASCIISymbols are used when the user passes --no-emoji or when the
+	// terminal or locale is unlikely to render UnicodeSymbols correctly.
+	ASCIISymbols = Symbols{
+		Success: "OK",
+		Failure: "X",
+		Warning: "!",
+		Info:    "*",
+		Spinner: []string{"|", "/", "-", "\\"},
+	}
+)
+
+// Symbols returns the set of symbols the printer should use. ASCIISymbols are
+// returned if ASCII or Accessible is set, or if the terminal locale does not
+// support UTF-8.
+func (p *Printer) Symbols() Symbols {
+	if (p != nil && (p.ASCII || p.Accessible)) || !unicodeLocale() {
+		return ASCIISymbols
+	}
+	return UnicodeSymbols
+}
+
+// unicodeLocale makes a best guess about whether the terminal can display
+// non-ASCII symbols, based on the standard locale environment variables. If no
+// locale is configured we assume UTF-8, except on the Linux virtual console
+// whose fonts typically lack these glyphs.
+func unicodeLocale() bool {
+	if os.Getenv("TERM") == "linux" {
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package cli_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestSymbols(t *testing.T) {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG", "TERM"} {
+		if value, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, value)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Unsetenv(name)
+	}
+
+	printer := &cli.Printer{}
+
+	os.Setenv("LANG", "en_US.UTF-8")
+	if !reflect.DeepEqual(printer.Symbols(), cli.UnicodeSymbols) {
+		t.Error("Expected unicode symbols with a UTF-8 locale")
+	}
+
+	os.Setenv("LC_ALL", "C")
+	if !reflect.DeepEqual(printer.Symbols(), cli.ASCIISymbols) {
+		t.Error("Expected ASCII symbols with the C locale")
+	}
+
+	os.Unsetenv("LC_ALL")
+	printer.ASCII = true
+	if !reflect.DeepEqual(printer.Symbols(), cli.ASCIISymbols) {
+		t.Error("Expected ASCII symbols when ASCII is set")
+	}
+}
+
+func TestNoEmojiFlag(t *testing.T) {
+	app := &cli.CLI{
+		Name: "testapp",
+		Commands: map[string]*cli.Command{
+			"status": {
+				Run: func(args []string) error { return nil },
+			},
+		},
+	}
+
+	if err := app.RunArgs([]string{"--no-emoji", "status"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.Printer.ASCII {
+		t.Error("Expected --no-emoji to enable ASCII symbols")
+	}
+}
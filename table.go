@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Column describes a column in a Table.
+type Column struct {
+	// Name is displayed in upper case in the table header.
+	Name string
+
+	// Wide columns contain supplemental information and are only displayed
+	// when the user requests wide output, similar to kubectl's -o wide.
+	Wide bool
+
+	// MaxWidth truncates values longer than the specified number of
+	// characters, unless the user requests wide output. Zero means values are
+	// never truncated.
+	MaxWidth int
+}
+
+// Table renders rows of data as aligned columns of text. Commands that list
+// things should build a Table and call Render so they get consistent output
+// and the standard output flags (see TableFlags) for free.
+type Table struct {
+	Columns []Column
+
+	// Rows holds the data for the table. Each row should have one value for
+	// each column in Columns. Missing values are displayed as empty cells.
+	Rows [][]string
+}
+
+// TableOptions controls how a Table is rendered. The zero value is valid and
+// produces normal (not wide) output.
+type TableOptions struct {
+	// Wide includes Wide columns and disables truncation.
+	Wide bool
+
+	// Columns selects which columns are displayed, and in what order. Column
+	// names are not case sensitive. Wide columns may be selected even if Wide
+	// is not set. If Columns is empty, all columns are displayed.
+	Columns []string
+
+	// SortBy orders rows by the named column, in the form column[:desc]. Values
+	// are compared as numbers, timestamps, or durations when every value in
+	// the column can be parsed that way, and as strings otherwise. If SortBy
+	// is empty, rows are displayed in their original order.
+	SortBy string
+
+	// SortOrder controls how SortBy compares values that are compared as
+	// strings. Use SortNatural to order "node2" before "node10". Defaults to
+	// SortBytes.
+	SortOrder SortOrder
+
+	// Width truncates lines to fit in the specified number of columns when
+	// Wide is not set. Commands should set it to Printer.OutputWidth so
+	// tables honor --width; if it is zero, TerminalWidth is used instead.
+	// Output is not truncated if the width is unknown.
+	Width int
+
+	// Strings translates the errors returned by Render, such as for an
+	// unknown column. Commands usually set it to ctx.CLI.Strings.
+	Strings Strings
+}
+
+// TableFlags defines the standard table output flags on set and returns the
+// TableOptions they populate. Pass the result to Table.Render after flags
+// have been parsed. The following flags are defined:
+//
+//	--wide                Show additional columns and do not truncate values
+//	--columns name,age    Comma-separated list of columns to display
+//	--sort-by age:desc    Sort rows by a column, optionally in descending order
+func TableFlags(set *flag.FlagSet) *TableOptions {
+	options := &TableOptions{}
+	set.BoolVar(&options.Wide, "wide", false, "Show additional columns and do not truncate values")
+	set.Var((*commaList)(&options.Columns), "columns", "Comma-separated list of columns to display")
+	set.StringVar(&options.SortBy, "sort-by", "", "Sort rows by a column, optionally in descending order (column[:desc])")
+	return options
+}
+
+// commaList is a flag.Value that parses a comma-separated list of strings.
+type commaList []string
+
+func (l *commaList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *commaList) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// Render writes the table to w. If options is nil the defaults are used.
+func (t *Table) Render(w io.Writer, options *TableOptions) error {
+	if options == nil {
+		options = &TableOptions{}
+	}
+
+	indexes, err := t.selectColumns(options)
+	if err != nil {
+		return err
+	}
+
+	header := make([]string, len(indexes))
+	for i, idx := range indexes {
+		header[i] = strings.ToUpper(t.Columns[idx].Name)
+	}
+
+	sorted, err := t.sortedRows(options)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(sorted))
+	for r, row := range sorted {
+		rows[r] = make([]string, len(indexes))
+		for i, idx := range indexes {
+			if idx >= len(row) {
+				continue
+			}
+			value := row[idx]
+			if !options.Wide {
+				value = Truncate(value, t.Columns[idx].MaxWidth)
+			}
+			rows[r][i] = value
+		}
+	}
+
+	widths := make([]int, len(indexes))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, value := range row {
+			if length := utf8.RuneCountInString(value); length > widths[i] {
+				widths[i] = length
+			}
+		}
+	}
+
+	maxWidth := options.Width
+	if maxWidth == 0 {
+		maxWidth = TerminalWidth()
+	}
+
+	var output strings.Builder
+	for _, row := range append([][]string{header}, rows...) {
+		line := ""
+		for i, value := range row {
+			line += value + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value)+3)
+		}
+		// Trim padding after the last value so we don't emit trailing spaces
+		line = strings.TrimRight(line, " ")
+		if !options.Wide {
+			line = Truncate(line, maxWidth)
+		}
+		output.WriteString(line + "\n")
+	}
+
+	_, err = io.WriteString(w, output.String())
+	return err
+}
+
+// selectColumns returns the indexes of the columns that will be displayed.
+func (t *Table) selectColumns(options *TableOptions) ([]int, error) {
+	var indexes []int
+
+	if len(options.Columns) == 0 {
+		for idx, column := range t.Columns {
+			if column.Wide && !options.Wide {
+				continue
+			}
+			indexes = append(indexes, idx)
+		}
+		return indexes, nil
+	}
+
+	for _, name := range options.Columns {
+		idx := t.columnIndex(name)
+		if idx < 0 {
+			available := make([]string, len(t.Columns))
+			for i, column := range t.Columns {
+				available[i] = strings.ToLower(column.Name)
+			}
+			return nil, options.Strings.errorf("unknown column %q, available columns are: %s", name, strings.Join(available, ", "))
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, nil
+}
+
+// sortedRows returns a copy of the table's rows ordered according to
+// options.SortBy.
+func (t *Table) sortedRows(options *TableOptions) ([][]string, error) {
+	sortBy, sortOrder := options.SortBy, options.SortOrder
+	rows := make([][]string, len(t.Rows))
+	copy(rows, t.Rows)
+
+	if sortBy == "" {
+		return rows, nil
+	}
+
+	name, order := sortBy, ""
+	if idx := strings.LastIndex(sortBy, ":"); idx > -1 {
+		name, order = sortBy[:idx], strings.ToLower(sortBy[idx+1:])
+	}
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, options.Strings.errorf("invalid sort order %q, expected asc or desc", order)
+	}
+
+	column := t.columnIndex(name)
+	if column < 0 {
+		return nil, options.Strings.errorf("unknown sort column %q", name)
+	}
+
+	values := make([]string, len(rows))
+	for r, row := range rows {
+		if column < len(row) {
+			values[r] = row[column]
+		}
+	}
+	less := lessFunc(values, sortOrder)
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := "", ""
+		if column < len(rows[i]) {
+			a = rows[i][column]
+		}
+		if column < len(rows[j]) {
+			b = rows[j][column]
+		}
+		if order == "desc" {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+
+	return rows, nil
+}
+
+// lessFunc chooses a comparison for the values in a column. Numbers, RFC 3339
+// timestamps, and durations (such as 90s or 1h30m) are compared by value if
+// every non-empty value in the column can be parsed as that type. Otherwise
+// values are compared as strings in order.
+func lessFunc(values []string, order SortOrder) func(a, b string) bool {
+	parsers := []func(string) (float64, bool){
+		func(s string) (float64, bool) {
+			f, err := strconv.ParseFloat(s, 64)
+			return f, err == nil
+		},
+		func(s string) (float64, bool) {
+			t, err := time.Parse(time.RFC3339, s)
+			return float64(t.UnixNano()), err == nil
+		},
+		func(s string) (float64, bool) {
+			d, err := time.ParseDuration(s)
+			return float64(d), err == nil
+		},
+	}
+
+	for _, parse := range parsers {
+		parsable := true
+		for _, value := range values {
+			if _, ok := parse(value); value != "" && !ok {
+				parsable = false
+				break
+			}
+		}
+		if !parsable {
+			continue
+		}
+
+		parse := parse
+		return func(a, b string) bool {
+			// Empty values sort first
+			if a == "" || b == "" {
+				return a == "" && b != ""
+			}
+			x, _ := parse(a)
+			y, _ := parse(b)
+			return x < y
+		}
+	}
+
+	return order.less
+}
+
+// columnIndex returns the index of the named column, or -1 if there is no
+// column with that name.
+func (t *Table) columnIndex(name string) int {
+	for idx, column := range t.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Truncate shortens str to at most width characters, replacing the end with
+// "..." if any characters were removed. A width of zero or less disables
+// truncation.
+func Truncate(str string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(str) <= width {
+		return str
+	}
+
+	runes := []rune(str)
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
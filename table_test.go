@@ -0,0 +1,203 @@
+package cli_test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func newTestTable() *cli.Table {
+	return &cli.Table{
+		Columns: []cli.Column{
+			{Name: "name"},
+			{Name: "status"},
+			{Name: "image", MaxWidth: 12},
+			{Name: "node", Wide: true},
+		},
+		Rows: [][]string{
+			{"web", "running", "nginx:1.17.0-alpine", "node-1"},
+			{"db", "stopped", "postgres:11", "node-2"},
+			{"cache", "running"},
+		},
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		if err := newTestTable().Render(output, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedOutput := `NAME    STATUS    IMAGE
+web     running   nginx:1.1...
+db      stopped   postgres:11
+cache   running
+`
+		if output.String() != expectedOutput {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output.String())
+		}
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		set := flag.NewFlagSet("list", flag.ContinueOnError)
+		options := cli.TableFlags(set)
+		if err := set.Parse([]string{"--wide"}); err != nil {
+			t.Fatal(err)
+		}
+
+		output := &bytes.Buffer{}
+		if err := newTestTable().Render(output, options); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedOutput := `NAME    STATUS    IMAGE                 NODE
+web     running   nginx:1.17.0-alpine   node-1
+db      stopped   postgres:11           node-2
+cache   running
+`
+		if output.String() != expectedOutput {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output.String())
+		}
+	})
+
+	t.Run("columns", func(t *testing.T) {
+		set := flag.NewFlagSet("list", flag.ContinueOnError)
+		options := cli.TableFlags(set)
+		if err := set.Parse([]string{"--columns", "node, NAME"}); err != nil {
+			t.Fatal(err)
+		}
+
+		output := &bytes.Buffer{}
+		if err := newTestTable().Render(output, options); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedOutput := `NODE     NAME
+node-1   web
+node-2   db
+         cache
+`
+		if output.String() != expectedOutput {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output.String())
+		}
+	})
+
+	t.Run("width", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		if err := newTestTable().Render(output, &cli.TableOptions{Width: 20}); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedOutput := `NAME    STATUS   ...
+web     running  ...
+db      stopped  ...
+cache   running
+`
+		if output.String() != expectedOutput {
+			t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, output.String())
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		options := &cli.TableOptions{Columns: []string{"age"}}
+
+		err := newTestTable().Render(&bytes.Buffer{}, options)
+		expectedError := `unknown column "age", available columns are: name, status, image, node`
+		if err == nil || err.Error() != expectedError {
+			t.Errorf("Expected %q, found %v", expectedError, err)
+		}
+	})
+}
+
+func TestTableSortBy(t *testing.T) {
+	table := &cli.Table{
+		Columns: []cli.Column{
+			{Name: "name"},
+			{Name: "size"},
+			{Name: "age"},
+			{Name: "created"},
+		},
+		Rows: [][]string{
+			{"beta", "100", "2h", "2019-06-01T12:00:00Z"},
+			{"alpha", "9", "90m", "2019-06-23T08:30:00Z"},
+			{"gamma", "25.5", "45s", "2018-12-31T23:59:59Z"},
+		},
+	}
+
+	type TestCase struct {
+		SortBy   string
+		Expected []string
+	}
+
+	cases := []TestCase{
+		{SortBy: "", Expected: []string{"beta", "alpha", "gamma"}},
+		{SortBy: "name", Expected: []string{"alpha", "beta", "gamma"}},
+		{SortBy: "name:desc", Expected: []string{"gamma", "beta", "alpha"}},
+		{SortBy: "size", Expected: []string{"alpha", "gamma", "beta"}},
+		{SortBy: "age:asc", Expected: []string{"gamma", "alpha", "beta"}},
+		{SortBy: "Created:desc", Expected: []string{"alpha", "beta", "gamma"}},
+	}
+
+	for _, testCase := range cases {
+		output := &bytes.Buffer{}
+		options := &cli.TableOptions{Columns: []string{"name"}, SortBy: testCase.SortBy}
+		if err := table.Render(output, options); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "NAME\n" + strings.Join(testCase.Expected, "\n") + "\n"
+		if output.String() != expected {
+			t.Errorf("Expected %q, found %q with --sort-by %q", expected, output.String(), testCase.SortBy)
+		}
+	}
+
+	natural := &cli.Table{
+		Columns: []cli.Column{{Name: "node"}},
+		Rows:    [][]string{{"node10"}, {"node2"}, {"node1"}},
+	}
+	output := &bytes.Buffer{}
+	if err := natural.Render(output, &cli.TableOptions{SortBy: "node", SortOrder: cli.SortNatural}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "NODE\nnode1\nnode2\nnode10\n"; output.String() != expected {
+		t.Errorf("Expected %q, found %q", expected, output.String())
+	}
+
+	for _, sortBy := range []string{"weight", "name:sideways"} {
+		if err := table.Render(&bytes.Buffer{}, &cli.TableOptions{SortBy: sortBy}); err == nil {
+			t.Errorf("Expected error with --sort-by %q", sortBy)
+		}
+	}
+
+	// Sorting should not modify the table
+	if table.Rows[0][0] != "beta" {
+		t.Errorf("Expected rows to retain their original order, found %q first", table.Rows[0][0])
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	type TestCase struct {
+		Str      string
+		Width    int
+		Expected string
+	}
+
+	cases := []TestCase{
+		{Str: "candy", Width: 10, Expected: "candy"},
+		{Str: "chocolate", Width: 8, Expected: "choco..."},
+		{Str: "chocolate", Width: 2, Expected: "ch"},
+		{Str: "chocolate", Width: 0, Expected: "chocolate"},
+		{Str: "crème brûlée", Width: 9, Expected: "crème ..."},
+	}
+
+	for _, testCase := range cases {
+		actual := cli.Truncate(testCase.Str, testCase.Width)
+		if actual != testCase.Expected {
+			t.Errorf("Expected %q, found %q with input (%q, %d)", testCase.Expected, actual, testCase.Str, testCase.Width)
+		}
+	}
+}
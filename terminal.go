@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+)
+
+// StdinIsPiped reports whether data is being piped or redirected into the
+// program's stdin, as in "cat file | program" or "program < file". Commands
+// can use this to decide between reading input from stdin and prompting the
+// user interactively.
+func StdinIsPiped() bool {
+	return fileIsPiped(os.Stdin)
+}
+
+// fileIsPiped reports whether f is a pipe or a regular file.
+func fileIsPiped(f *os.File) bool {
+	if isTerminal(f) {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	mode := stat.Mode()
+	return mode&os.ModeNamedPipe != 0 || mode.IsRegular()
+}
+
+// interactive reports whether we can ask the user for input.
+func interactive() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stderr)
+}
+
+// TerminalWidth returns the width of the output in columns, which is used to
+// wrap help text and fit tables. The width is taken from the COLUMNS
+// environment variable if it is set, and is otherwise detected from the
+// terminal attached to stdout. The --width flag does not change it; see
+// Printer.OutputWidth.
+//
+// TerminalWidth returns zero if the width is unknown, such as when output is
+// redirected to a file, in which case output should not be wrapped.
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return terminalWidth(os.Stdout)
+}
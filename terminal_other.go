@@ -0,0 +1,28 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+// isTerminal reports whether f is likely connected to a terminal. This is a
+// best guess on platforms where we cannot query the terminal directly.
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// disableEcho is not supported on this platform.
+func disableEcho(f *os.File) (restore func() error, err error) {
+	return nil, errors.New("unable to disable terminal echo on this platform")
+}
+
+// terminalWidth is not supported on this platform.
+func terminalWidth(f *os.File) int {
+	return 0
+}
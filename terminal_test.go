@@ -0,0 +1,49 @@
+package cli_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestStdinIsPiped(t *testing.T) {
+	ogStdin := os.Stdin
+	defer func() { os.Stdin = ogStdin }()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+
+	os.Stdin = reader
+	if !cli.StdinIsPiped() {
+		t.Error("Expected pipe to be detected")
+	}
+
+	file, err := ioutil.TempFile("", "cli-test-stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	os.Stdin = file
+	if !cli.StdinIsPiped() {
+		t.Error("Expected redirected file to be detected")
+	}
+
+	devnull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devnull.Close()
+
+	os.Stdin = devnull
+	if cli.StdinIsPiped() {
+		t.Error("Expected /dev/null not to be treated as piped input")
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const enableEchoInput = 0x0004
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+// consoleScreenBufferInfo mirrors CONSOLE_SCREEN_BUFFER_INFO from the Windows
+// API.
+type consoleScreenBufferInfo struct {
+	size              [2]int16
+	cursorPosition    [2]int16
+	attributes        uint16
+	window            [4]int16 // left, top, right, bottom
+	maximumWindowSize [2]int16
+}
+
+func setConsoleMode(handle syscall.Handle, mode uint32) error {
+	r, _, err := procSetConsoleMode.Call(uintptr(handle), uintptr(mode))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// isTerminal reports whether f is connected to a console.
+func isTerminal(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
+
+// disableEcho turns off local echo on the console attached to f and returns a
+// function that restores the previous state.
+func disableEcho(f *os.File) (restore func() error, err error) {
+	handle := syscall.Handle(f.Fd())
+
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		return nil, err
+	}
+
+	if err := setConsoleMode(handle, mode&^enableEchoInput); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return setConsoleMode(handle, mode)
+	}, nil
+}
+
+// terminalWidth returns the number of columns in the console attached to f,
+// or zero if f is not a console.
+func terminalWidth(f *os.File) int {
+	var info consoleScreenBufferInfo
+	r, _, _ := procGetConsoleScreenBufferInfo.Call(f.Fd(), uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return 0
+	}
+	return int(info.window[2]-info.window[0]) + 1
+}
@@ -0,0 +1,25 @@
+package cli
+
+import "github.com/cbednarski/cli/text"
+
+// minWrapWidth is the narrowest column we will wrap text into. Below this the
+// output is harder to read wrapped than it is unwrapped.
+const minWrapWidth = text.MinWidth
+
+// wrap breaks lines in s that are longer than width at word boundaries. See
+// text.Wrap.
+func wrap(s string, width int) string {
+	return text.Wrap(s, width)
+}
+
+// wrapWords splits line into lines no longer than width, breaking between
+// words. See text.WrapWords.
+func wrapWords(line string, width int) []string {
+	return text.WrapWords(line, width)
+}
+
+// textWidth returns the number of columns s occupies in the terminal,
+// ignoring ANSI escape sequences.
+func textWidth(s string) int {
+	return text.Width(s)
+}
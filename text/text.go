@@ -0,0 +1,154 @@
+// Package text formats plain text for the terminal: wrapping paragraphs to a
+// width, indenting blocks, and aligning columns. Widths are measured in
+// terminal columns, and ANSI escape sequences such as colors are ignored, so
+// styled text lines up the same way as plain text.
+//
+// The cli package uses these helpers to render help, and they are available
+// for commands that produce similar output.
+package text
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MinWidth is the narrowest column Wrap and Columnize will wrap text into.
+// Below this, text is harder to read wrapped than it is unwrapped.
+const MinWidth = 20
+
+// Width returns the number of columns s occupies in the terminal, ignoring
+// ANSI escape sequences.
+func Width(s string) int {
+	if strings.IndexByte(s, 0x1b) < 0 {
+		return utf8.RuneCountInString(s)
+	}
+
+	width := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != 0x1b || i+1 == len(s) {
+			if utf8.RuneStart(s[i]) {
+				width++
+			}
+			continue
+		}
+		i++
+		switch s[i] {
+		case '[':
+			// Control sequences end with a byte in the range @ to ~
+			for i++; i < len(s) && (s[i] < 0x40 || s[i] > 0x7e); i++ {
+			}
+		case ']':
+			// Operating system commands end with BEL or ESC \
+			for i++; i < len(s) && s[i] != 0x07 && !(s[i] == '\\' && s[i-1] == 0x1b); i++ {
+			}
+		}
+	}
+	return width
+}
+
+// Wrap breaks lines in s that are longer than width at word boundaries.
+// Lines that begin with whitespace are assumed to be preformatted, such as
+// code examples, and are left alone. Widths less than MinWidth disable
+// wrapping.
+func Wrap(s string, width int) string {
+	if width < MinWidth {
+		return s
+	}
+
+	var output []string
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			output = append(output, line)
+			continue
+		}
+		output = append(output, WrapWords(line, width)...)
+	}
+	return strings.Join(output, "\n")
+}
+
+// WrapWords splits line into lines no longer than width, breaking between
+// words. Words longer than width are placed on a line by themselves. A width
+// of zero or less disables wrapping.
+func WrapWords(line string, width int) []string {
+	if width <= 0 || Width(line) <= width {
+		return []string{line}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(line) {
+		if current == "" {
+			current = word
+			continue
+		}
+		if Width(current)+1+Width(word) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current += " " + word
+	}
+	return append(lines, current)
+}
+
+// Indent adds prefix to the start of every line in s that is not empty.
+func Indent(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// PadRight appends spaces to s until it is width columns wide.
+func PadRight(s string, width int) string {
+	if n := Width(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// Columnize aligns rows of cells into columns separated by gap spaces, and
+// returns one line per row. The last cell in each row is wrapped to fit in
+// width, with continuation lines aligned under the start of the cell, unless
+// that would leave it narrower than MinWidth. A width of zero disables
+// wrapping. Rows may have different numbers of cells.
+func Columnize(rows [][]string, gap, width int) string {
+	var widths []int
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		for i, cell := range row[:len(row)-1] {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := Width(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	spacing := strings.Repeat(" ", gap)
+	output := ""
+	for _, row := range rows {
+		if len(row) == 0 {
+			output += "\n"
+			continue
+		}
+		line := ""
+		for i, cell := range row[:len(row)-1] {
+			line += PadRight(cell, widths[i]) + spacing
+		}
+		prefixWidth := Width(line)
+		wrapWidth := width - prefixWidth
+		if width <= 0 || wrapWidth < MinWidth {
+			wrapWidth = 0
+		}
+		last := strings.Join(WrapWords(row[len(row)-1], wrapWidth), "\n"+strings.Repeat(" ", prefixWidth))
+		output += strings.TrimRight(line+last, " ") + "\n"
+	}
+	return output
+}
@@ -0,0 +1,67 @@
+package text_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli/text"
+)
+
+func TestWidth(t *testing.T) {
+	type TestCase struct {
+		Input    string
+		Expected int
+	}
+
+	cases := []TestCase{
+		{"hello", 5},
+		{"héllo", 5},
+		{"\x1b[1;31mhello\x1b[0m", 5},
+		{"\x1b]8;;https://example.com\x07link\x1b]8;;\x07", 4},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+	}
+
+	for _, c := range cases {
+		if width := text.Width(c.Input); width != c.Expected {
+			t.Errorf("Expected %d for %q, found %d", c.Expected, c.Input, width)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	input := "The quick brown fox jumps over the lazy dog and keeps on running.\n    preformatted lines are never wrapped no matter how long they are"
+	expected := "The quick brown fox jumps\nover the lazy dog and\nkeeps on running.\n    preformatted lines are never wrapped no matter how long they are"
+	if output := text.Wrap(input, 25); output != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, output)
+	}
+
+	if output := text.Wrap(input, 10); output != input {
+		t.Errorf("Expected widths below MinWidth to disable wrapping, found\n%s", output)
+	}
+}
+
+func TestIndent(t *testing.T) {
+	expected := "> one\n\n> two\n"
+	if output := text.Indent("one\n\ntwo\n", "> "); output != expected {
+		t.Errorf("Expected %q, found %q", expected, output)
+	}
+}
+
+func TestColumnize(t *testing.T) {
+	rows := [][]string{
+		{"--env string", "environment to deploy to"},
+		{"--force", "skip confirmation and deploy even if the checks have not passed"},
+		{"--\x1b[1mnow\x1b[0m", "deploy immediately"},
+	}
+	expected := strings.Join([]string{
+		"--env string   environment to deploy to",
+		"--force        skip confirmation and deploy",
+		"               even if the checks have not",
+		"               passed",
+		"--\x1b[1mnow\x1b[0m          deploy immediately",
+		"",
+	}, "\n")
+	if output := text.Columnize(rows, 3, 45); output != expected {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expected, output)
+	}
+}
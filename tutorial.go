@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tutorialFile is the name of the file in the StateDir that records how many
+// tutorial steps the user has completed.
+const tutorialFile = "tutorial"
+
+// TutorialStep is one step in a guided tutorial. See CLI.Tutorial.
+type TutorialStep struct {
+	// Title is a short name for the step.
+	Title string
+
+	// Text explains the concept the step teaches and what the user should do.
+	Text string
+
+	// Command is an optional command line for the user to run, starting with
+	// the program name, such as "ship init". The tutorial offers to run it
+	// for the user.
+	Command string
+
+	// Check optionally verifies that the step was completed, for example by
+	// checking that a file was created. If Check returns an error, its message
+	// is shown and the user may try again or skip the step.
+	Check func() error
+}
+
+// RunTutorial walks the user through c.Tutorial, reading their responses from
+// input. Each step is explained and, if it has a Command, the user is offered
+// the chance to run it. Progress is saved in the StateDir after each step so
+// the user can quit and pick up where they left off.
+func RunTutorial(c *CLI, input io.Reader) error {
+	completed := c.tutorialProgress()
+	total := len(c.Tutorial)
+	if completed >= total {
+		c.Printer.Outf("You have completed the tutorial. Run '%s tutorial --restart' to start over.\n", c.Name)
+		return nil
+	}
+
+	symbols := c.Printer.Symbols()
+	width := c.helpWidth()
+	indent := strings.Repeat(" ", c.layout().Indent)
+
+	var err error
+	for i := completed; i < total; i++ {
+		step := c.Tutorial[i]
+		c.Printer.Outf("Step %d of %d: %s\n\n", i+1, total, step.Title)
+		if step.Text != "" {
+			c.Printer.Out(EnsureNewlines(wrap(strings.TrimSpace(step.Text), width)), "\n")
+		}
+		if step.Command != "" {
+			c.Printer.Outf("%s$ %s\n\n", indent, step.Command)
+		}
+
+		// Commands that the program can't run itself, such as shell
+		// pipelines, are left for the user to run in another terminal
+		var commandArgs []string
+		label := "Press Enter to continue, or type skip or quit: "
+		if step.Command != "" {
+			if commandArgs, err = c.exampleArgs(Example{Command: step.Command}); err == nil {
+				label = "Press Enter to run this command, or type skip or quit: "
+			} else {
+				label = "Run this command in another terminal, then press Enter, or type skip or quit: "
+			}
+		}
+
+		for done := false; !done; {
+			c.Printer.Err(label)
+			answer, err := readLine(input, c.Strings)
+			if err != nil {
+				return err
+			}
+
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "quit", "q":
+				c.Printer.Outf("Your progress has been saved. Run '%s tutorial' to continue.\n", c.Name)
+				return nil
+			case "skip", "s":
+				done = true
+				continue
+			case "":
+			default:
+				continue
+			}
+
+			if commandArgs != nil {
+				commandName, args := ParseArgs(commandArgs)
+				if err := c.dispatch(commandName, args); err != nil {
+					c.Printer.Errf("%s %s\n", symbols.Failure, err)
+					continue
+				}
+			}
+			if step.Check != nil {
+				if err := step.Check(); err != nil {
+					c.Printer.Errf("%s %s\n", symbols.Failure, err)
+					continue
+				}
+			}
+			c.Printer.Errf("%s Step complete\n", symbols.Success)
+			done = true
+		}
+
+		c.saveTutorialProgress(i + 1)
+		c.Printer.Out("\n")
+	}
+
+	c.Printer.Out("You have completed the tutorial!\n")
+	return nil
+}
+
+// tutorialProgress returns the number of tutorial steps the user has
+// completed.
+func (c *CLI) tutorialProgress() int {
+	dir, err := c.StateDir()
+	if err != nil {
+		return 0
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, tutorialFile))
+	if err != nil {
+		return 0
+	}
+	completed, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || completed < 0 {
+		return 0
+	}
+	return completed
+}
+
+// saveTutorialProgress records the number of completed steps. Failing to save
+// progress is not fatal, so errors are reported but otherwise ignored.
+func (c *CLI) saveTutorialProgress(completed int) {
+	dir, err := c.StateDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(dir, tutorialFile), []byte(strconv.Itoa(completed)+"\n"), 0644)
+	}
+	if err != nil {
+		c.Printer.Errf("Unable to save tutorial progress: %s\n", err)
+	}
+}
+
+// tutorialCommand implements the built-in tutorial command.
+func (c *CLI) tutorialCommand(args []string) error {
+	set := flag.NewFlagSet("tutorial", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	restart := set.Bool("restart", false, "")
+	step := set.Int("step", 0, "")
+
+	parser := &flagParser{set: set, messages: c.Strings}
+	positionals, err := parser.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positionals) > 0 {
+		return c.Strings.errorf("usage: %s tutorial [--restart | --step <n>]", c.Name)
+	}
+
+	switch {
+	case *step != 0:
+		if *step < 1 || *step > len(c.Tutorial) {
+			return c.Strings.errorf("step %d does not exist, the tutorial has %d steps", *step, len(c.Tutorial))
+		}
+		c.saveTutorialProgress(*step - 1)
+	case *restart:
+		c.saveTutorialProgress(0)
+	}
+
+	if !interactive() {
+		return c.Strings.errorf("the tutorial must be run in an interactive terminal")
+	}
+	return RunTutorial(c, os.Stdin)
+}
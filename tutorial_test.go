@@ -0,0 +1,158 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestRunTutorial(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tutorial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("GARDEN_STATE_DIR", dir)
+	defer os.Unsetenv("GARDEN_STATE_DIR")
+
+	planted := false
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "garden",
+		Printer: &cli.Printer{Stdout: stdout, Stderr: stderr, ASCII: true},
+		Commands: map[string]*cli.Command{
+			"plant": {
+				Run: func(args []string) error {
+					planted = len(args) == 1 && args[0] == "tomato"
+					return nil
+				},
+			},
+		},
+		Tutorial: []cli.TutorialStep{
+			{
+				Title: "Welcome",
+				Text:  "Garden helps you keep track of your plants.",
+			},
+			{
+				Title:   "Plant something",
+				Text:    "Let's plant a tomato.",
+				Command: "garden plant tomato",
+				Check: func() error {
+					if !planted {
+						return errors.New("nothing has been planted yet")
+					}
+					return nil
+				},
+			},
+			{
+				Title:   "Share your garden",
+				Command: "garden export | mail friend@example.com",
+			},
+		},
+	}
+
+	// Finish the first step, then quit
+	if err := cli.RunTutorial(app, strings.NewReader("\nquit\n")); err != nil {
+		t.Fatal(err)
+	}
+	expectedOutput := `Step 1 of 3: Welcome
+
+Garden helps you keep track of your plants.
+
+
+Step 2 of 3: Plant something
+
+Let's plant a tomato.
+
+  $ garden plant tomato
+
+Your progress has been saved. Run 'garden tutorial' to continue.
+`
+	if stdout.String() != expectedOutput {
+		t.Errorf("--- Expected Output ---\n%s\n--- Actual Output ---\n%s\n", expectedOutput, stdout.String())
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "tutorial"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "1\n" {
+		t.Errorf("Expected progress %q, found %q", "1\n", data)
+	}
+
+	// Resume at step two, run its command, and skip the last step
+	stdout.Reset()
+	stderr.Reset()
+	if err := cli.RunTutorial(app, strings.NewReader("\nskip\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !planted {
+		t.Error("Expected the step's command to run")
+	}
+	if !strings.HasPrefix(stdout.String(), "Step 2 of 3") || !strings.HasSuffix(stdout.String(), "You have completed the tutorial!\n") {
+		t.Errorf("Unexpected output %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "OK Step complete") {
+		t.Errorf("Expected the step to be completed, found %q", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Run this command in another terminal") {
+		t.Errorf("Expected shell commands to be left to the user, found %q", stderr.String())
+	}
+
+	stdout.Reset()
+	if err := cli.RunTutorial(app, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	expected := "You have completed the tutorial. Run 'garden tutorial --restart' to start over.\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected %q, found %q", expected, stdout.String())
+	}
+}
+
+func TestRunTutorialCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tutorial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("GARDEN_STATE_DIR", dir)
+	defer os.Unsetenv("GARDEN_STATE_DIR")
+
+	attempts := 0
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "garden",
+		Printer: &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: stderr, ASCII: true},
+		Tutorial: []cli.TutorialStep{
+			{
+				Title: "Water",
+				Check: func() error {
+					attempts++
+					if attempts < 2 {
+						return errors.New("the soil is still dry")
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := cli.RunTutorial(app, strings.NewReader("\n\n")); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected the check to run twice, found %d", attempts)
+	}
+	if !strings.Contains(stderr.String(), "X the soil is still dry\n") {
+		t.Errorf("Expected the check failure to be shown, found %q", stderr.String())
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// SelfUpdate replaces the running executable with the one at url, which is
+// typically chosen by an update command after NewerRelease reports that a
+// newer version is available. The new executable is fetched with Download and
+// must be signed by one of options.PublicKeys, which are required, so the
+// program is only replaced when the signature matches a key built into it.
+// The new version is used the next time the program is run.
+func SelfUpdate(ctx *Context, url string, options *DownloadOptions) error {
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return err
+	}
+	return installExecutable(ctx, url, path, options)
+}
+
+// installExecutable downloads the executable at url and moves it to dest,
+// replacing any file that is already there, once its signature has been
+// verified with options.PublicKeys.
+func installExecutable(ctx *Context, url, dest string, options *DownloadOptions) error {
+	var messages Strings
+	if ctx.CLI != nil {
+		messages = ctx.CLI.Strings
+	}
+	if options == nil || len(options.PublicKeys) == 0 {
+		return messages.errorf("refusing to install %s without a signature, no public keys are trusted", filepath.Base(dest))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	temp := dest + ".new"
+	if err := Download(ctx, url, temp, options); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp, 0755); err != nil {
+		os.Remove(temp)
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		// A running executable can't be replaced on Windows, but it can be
+		// moved aside. The old copy is removed by the next install.
+		old := dest + ".old"
+		os.Remove(old)
+		if err := os.Rename(dest, old); err != nil && !os.IsNotExist(err) {
+			os.Remove(temp)
+			return err
+		}
+	}
+	if err := os.Rename(temp, dest); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return nil
+}
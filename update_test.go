@@ -0,0 +1,26 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	// Without public keys the executable is left alone, before anything is
+	// downloaded
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stderr: &bytes.Buffer{}},
+		Commands: map[string]*cli.Command{
+			"update": {RunContext: func(ctx *cli.Context) error {
+				return cli.SelfUpdate(ctx, "http://127.0.0.1:0/ship", nil)
+			}},
+		},
+	}
+	if err := app.RunArgs([]string{"update"}); err == nil || !strings.Contains(err.Error(), "without a signature") {
+		t.Errorf("Expected an error without public keys, found %v", err)
+	}
+}
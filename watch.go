@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// watchInterval is how often watched paths are checked for changes.
+	// There is no portable file notification API in the standard library,
+	// so paths are polled.
+	watchInterval = 250 * time.Millisecond
+
+	// watchSettle is how long paths must go without changing before the
+	// command runs again, so saving several files at once causes one run.
+	watchSettle = 200 * time.Millisecond
+)
+
+// fileState is what we compare to detect that a file changed.
+type fileState struct {
+	modified time.Time
+	size     int64
+}
+
+// snapshotPaths returns the state of every file in paths, including files in
+// directories. Hidden directories such as .git are skipped.
+func snapshotPaths(paths []string) map[string]fileState {
+	files := map[string]fileState{}
+	for _, root := range paths {
+		_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if path != root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			files[path] = fileState{modified: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+	return files
+}
+
+// changedPath returns a path that differs between two snapshots, or an empty
+// string if they are the same.
+func changedPath(before, after map[string]fileState) string {
+	for path, state := range after {
+		if previous, ok := before[path]; !ok || previous != state {
+			return path
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			return path
+		}
+	}
+	return ""
+}
+
+// setWatch sets the paths for --watch from a comma-separated list.
+func (c *CLI) setWatch(value string) error {
+	c.watchPaths = nil
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			c.watchPaths = append(c.watchPaths, path)
+		}
+	}
+	if len(c.watchPaths) == 0 {
+		return c.Strings.errorf("flag --%s requires a value", "watch")
+	}
+	for _, path := range c.watchPaths {
+		if _, err := os.Stat(path); err != nil {
+			return c.Strings.errorf("unable to watch %s: %s", path, err)
+		}
+	}
+	return nil
+}
+
+// watch runs the command, then runs it again each time a file in the paths
+// passed to --watch changes, until the user presses Ctrl-C. Errors from the
+// command are shown but don't stop watching. Without --watch the command is
+// run once.
+func (c *CLI) watch(commandName string, args []string) error {
+	if len(c.watchPaths) == 0 {
+		return c.dispatch(commandName, args)
+	}
+
+	base := c.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	ctx, cancel := context.WithCancel(base)
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	symbols := c.Printer.Symbols()
+	snapshot := snapshotPaths(c.watchPaths)
+	for {
+		if err := c.dispatch(commandName, args); err != nil && ctx.Err() == nil {
+			c.Printer.Err(RenderError(c, err))
+		}
+		c.Printer.Errf("%s Watching %s for changes. Press Ctrl-C to stop.\n", symbols.Info, strings.Join(c.watchPaths, ", "))
+
+		// Wait for a change, then for the files to settle
+		changed := ""
+		for changed == "" {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(watchInterval):
+			}
+			current := snapshotPaths(c.watchPaths)
+			changed = changedPath(snapshot, current)
+			snapshot = current
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(watchSettle):
+			}
+			current := snapshotPaths(c.watchPaths)
+			if changedPath(snapshot, current) == "" {
+				break
+			}
+			snapshot = current
+		}
+
+		width := c.helpWidth()
+		if width <= 0 || width > 80 {
+			width = 80
+		}
+		c.Printer.Errf("\n%s\n%s %s changed, running again: %s\n\n", strings.Repeat("-", width), symbols.Info, changed, RenderCommand("", append(strings.Fields(commandName), args...)))
+	}
+}
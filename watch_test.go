@@ -0,0 +1,74 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "site.md")
+	if err := ioutil.WriteFile(path, []byte("# Hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var runs int32
+	stderr := &bytes.Buffer{}
+	app := &cli.CLI{
+		Name:    "ship",
+		Printer: &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: stderr, ASCII: true},
+		Commands: map[string]*cli.Command{
+			"build": {Run: func(args []string) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- app.RunArgsContext(ctx, []string{"--watch", dir, "build"})
+	}()
+
+	waitFor := func(n int32) {
+		deadline := time.Now().Add(5 * time.Second)
+		for atomic.LoadInt32(&runs) < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d runs, found %d", n, atomic.LoadInt32(&runs))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitFor(1)
+	if err := ioutil.WriteFile(path, []byte("# Hello, world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(2)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error when watching is canceled, found %s", err)
+	}
+	if !strings.Contains(stderr.String(), "* "+path+" changed, running again: build\n") {
+		t.Errorf("Expected a separator naming the changed file, found:\n%s", stderr.String())
+	}
+
+	if err := app.RunArgs([]string{"--watch", filepath.Join(dir, "missing"), "build"}); err == nil {
+		t.Error("Expected an error when watching a path that does not exist")
+	}
+}
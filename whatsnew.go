@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lastVersionFile is the name of the file in the StateDir that records the
+// last version of the program that was run.
+const lastVersionFile = "last-version"
+
+// showWhatsNew prints c.WhatsNew to stderr if this is the first time this
+// version of the program has run. Nothing is printed on a fresh install, when
+// the program has no Version, or for built-in commands whose output is read by
+// the shell. Errors reading or writing the state file are ignored so they
+// can't prevent the program from working.
+func (c *CLI) showWhatsNew(commandName string) {
+	if c.WhatsNew == "" || c.Version == "" {
+		return
+	}
+	switch commandName {
+	case "__complete", "completion", "shell-init":
+		return
+	}
+	// Save the notice for an interactive session instead of burying it in a
+	// log file
+	if f, ok := c.Printer.stderr().(*os.File); ok && !isTerminal(f) {
+		return
+	}
+
+	dir, err := c.StateDir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, lastVersionFile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	last := strings.TrimSpace(string(data))
+	if last == c.Version {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	if err := ioutil.WriteFile(path, []byte(c.Version+"\n"), 0644); err != nil {
+		return
+	}
+
+	if last != "" {
+		c.Printer.Err(WhatsNewNotice(c))
+	}
+}
+
+// WhatsNewNotice returns the notice shown after the program is upgraded,
+// titled with the major and minor version, such as "What's new in v1.4".
+func WhatsNewNotice(c *CLI) string {
+	version := strings.TrimPrefix(c.Version, "v")
+	if parts := strings.SplitN(version, ".", 3); len(parts) == 3 {
+		version = parts[0] + "." + parts[1]
+	}
+	notes := wrap(strings.TrimSpace(c.WhatsNew), c.helpWidth())
+	return fmt.Sprintf("What's new in v%s\n\n%s\n\n", version, notes)
+}
@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cbednarski/cli"
+)
+
+func TestWhatsNew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whatsnew")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("FERRY_STATE_DIR", dir)
+	defer os.Unsetenv("FERRY_STATE_DIR")
+
+	stderr := &bytes.Buffer{}
+	run := func(version string) string {
+		stderr.Reset()
+		app := &cli.CLI{
+			Name:     "ferry",
+			Version:  version,
+			WhatsNew: "The sync command now resumes interrupted transfers.",
+			Printer:  &cli.Printer{Stdout: &bytes.Buffer{}, Stderr: stderr},
+			Commands: map[string]*cli.Command{
+				"status": {Run: func(args []string) error { return nil }},
+			},
+		}
+		if err := app.RunArgs([]string{"status"}); err != nil {
+			t.Fatal(err)
+		}
+		return stderr.String()
+	}
+
+	// A fresh install has nothing to compare to
+	if output := run("1.3.2"); output != "" {
+		t.Errorf("Expected no notice on first run, found %q", output)
+	}
+	if output := run("1.3.2"); output != "" {
+		t.Errorf("Expected no notice for the same version, found %q", output)
+	}
+
+	expected := "What's new in v1.4\n\nThe sync command now resumes interrupted transfers.\n\n"
+	if output := run("1.4.0"); output != expected {
+		t.Errorf("Expected %q, found %q", expected, output)
+	}
+	// The notice is only shown once
+	if output := run("1.4.0"); output != "" {
+		t.Errorf("Expected no notice on second run, found %q", output)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "last-version"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "1.4.0\n" {
+		t.Errorf("Expected %q, found %q", "1.4.0\n", data)
+	}
+}